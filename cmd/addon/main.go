@@ -12,7 +12,6 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
-	utilrand "k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/client-go/rest"
 	utilflag "k8s.io/component-base/cli/flag"
 	"k8s.io/klog/v2"
@@ -24,6 +23,7 @@ import (
 
 	"github.com/totvs/addon-framework-basic/pkg/addon"
 	"github.com/totvs/addon-framework-basic/pkg/agent"
+	"github.com/totvs/addon-framework-basic/pkg/hub"
 )
 
 const (
@@ -100,7 +100,8 @@ func newControllerCommand() *cobra.Command {
 // 2. Configura o RegistrationOption (como o agent se registra no hub)
 // 3. Cria o AgentAddon usando factory (define manifests, values, health probe)
 // 4. Adiciona o AgentAddon ao manager
-// 5. Inicia o manager (começa a observar ManagedClusterAddOn)
+// 5. Inicia o cleaner dos relatórios no hub (ver hub.ReportCleaner)
+// 6. Inicia o manager (começa a observar ManagedClusterAddOn)
 //
 // Quando um ManagedClusterAddOn é criado:
 // 1. Controller observa o evento
@@ -119,12 +120,9 @@ func runController(ctx context.Context, kubeConfig *rest.Config) error {
 	}
 
 	// RegistrationOption configura como o agent se registra.
-	// utilrand.String(5) gera um nome único para o agent (usado no CSR).
-	registrationOption := addon.NewRegistrationOption(
-		kubeConfig,
-		addon.AddonName,
-		utilrand.String(5),
-	)
+	// O nome do agent no CSR vem do addon e do UID da ManagedCluster (hub.AgentName),
+	// então restarts e upgrades do controller não forçam um novo registro.
+	registrationOption := addon.NewRegistrationOption(kubeConfig, addon.AddonName)
 
 	// NewAgentAddonFactory cria o addon usando padrão factory.
 	// - FS: Sistema de arquivos embarcado com templates
@@ -148,6 +146,33 @@ func runController(ctx context.Context, kubeConfig *rest.Config) error {
 		return err
 	}
 
+	// Cleaner remove os relatórios do hub quando o addon ou o cluster é removido,
+	// depois do grace period (ADDON_CLEANUP_GRACE_PERIOD) para arquivar os dados.
+	gracePeriod, err := addon.CleanupGracePeriod()
+	if err != nil {
+		return err
+	}
+	cleaner, err := hub.NewReportCleaner(kubeConfig, addon.AddonName, gracePeriod)
+	if err != nil {
+		return err
+	}
+	go func() {
+		if err := cleaner.Run(ctx); err != nil {
+			klog.Errorf("Cleaner dos relatórios parou: %v", err)
+		}
+	}()
+
+	// Rechecker reavalia os CSRs segurados por hub.CSRDenyLabel quando a label é removida
+	rechecker, err := hub.NewCSRRechecker(kubeConfig, addon.AddonName)
+	if err != nil {
+		return err
+	}
+	go func() {
+		if err := rechecker.Run(ctx); err != nil {
+			klog.Errorf("Rechecker dos CSRs parou: %v", err)
+		}
+	}()
+
 	// Inicia o manager (bloqueia até ctx.Done)
 	err = mgr.Start(ctx)
 	if err != nil {
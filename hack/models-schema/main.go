@@ -0,0 +1,54 @@
+// models-schema imprime o schema OpenAPI (swagger) dos tipos de pkg/apis.
+// Usado por make generate como --openapi-schema do applyconfiguration-gen, para que
+// o fake clientset entenda server-side apply do PodReport.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"k8s.io/kube-openapi/pkg/util"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+
+	"github.com/totvs/addon-framework-basic/pkg/client/openapi"
+)
+
+func main() {
+	if err := output(); err != nil {
+		fmt.Fprintf(os.Stderr, "falha ao gerar schema: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// output escreve em stdout as definições com nomes no formato do apiserver
+// (io.k8s.apimachinery...), que é o formato esperado pelo applyconfiguration-gen.
+func output() error {
+	refFunc := func(name string) spec.Ref {
+		return spec.MustCreateRef(fmt.Sprintf("#/definitions/%s", friendlyName(name)))
+	}
+	defs := openapi.GetOpenAPIDefinitions(refFunc)
+	schemaDefs := make(map[string]spec.Schema, len(defs))
+	for k, v := range defs {
+		schemaDefs[friendlyName(k)] = v.Schema
+	}
+	data, err := json.Marshal(&spec.Swagger{
+		SwaggerProps: spec.SwaggerProps{
+			Definitions: schemaDefs,
+			Info: &spec.Info{
+				InfoProps: spec.InfoProps{Title: "basic-addon", Version: "unversioned"},
+			},
+			Swagger: "2.0",
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
+
+// friendlyName converte o caminho do pacote Go em nome de definição OpenAPI.
+func friendlyName(name string) string {
+	return util.ToRESTFriendlyName(name)
+}
@@ -5,14 +5,19 @@ import (
 	"embed"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
+	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
 	"open-cluster-management.io/addon-framework/pkg/addonfactory"
 	"open-cluster-management.io/addon-framework/pkg/agent"
-	"open-cluster-management.io/addon-framework/pkg/utils"
 	addonapiv1alpha1 "open-cluster-management.io/api/addon/v1alpha1"
 	clusterv1 "open-cluster-management.io/api/cluster/v1"
 
+	addonagent "github.com/totvs/addon-framework-basic/pkg/agent"
 	"github.com/totvs/addon-framework-basic/pkg/hub"
 )
 
@@ -20,6 +25,43 @@ const (
 	AddonName             = "basic-addon"
 	DefaultImage          = "basic-addon:latest"
 	InstallationNamespace = "open-cluster-management-agent-addon"
+
+	// DefaultReportEncoding é o encoding do relatório no hub (flag --report-encoding do agent).
+	// "json" grava Data["report"]; "gzip" grava BinaryData["report"] comprimido.
+	DefaultReportEncoding = "json"
+
+	// DefaultReportBackend é onde o agent grava o relatório no hub (flag --report-backend).
+	// "crd" grava o PodReport; "configmap" mantém o ConfigMap pod-report durante a migração.
+	DefaultReportBackend = "crd"
+
+	// DefaultHistorySize e DefaultHistoryMaxAge definem o histórico de relatórios no hub
+	// (flags --history-size e --history-max-age do agent). Expira por quantidade e por idade.
+	// Desligado por padrão: cada slot é uma escrita a mais no hub por relatório publicado.
+	DefaultHistorySize   = "0"
+	DefaultHistoryMaxAge = "1h"
+
+	// DefaultCollectors são os collectors habilitados no agent (flag --collectors), separados
+	// por vírgula. Cada collector além de pods grava a seção <collector>-report no hub.
+	DefaultCollectors = "pods"
+
+	// DefaultMetrics liga a consulta ao metrics.k8s.io no agent (flag --metrics).
+	// Desligado por padrão: nem todo spoke tem metrics-server.
+	DefaultMetrics = "false"
+
+	// DefaultAllowedRegistries é a allow-list de registries do collector images (flag
+	// --allowed-registries), separada por vírgula. Vazia desliga o check.
+	DefaultAllowedRegistries = ""
+
+	// Filtros de coleta do agent (flags --include-namespaces, --exclude-namespaces,
+	// --pod-label-selector e --pod-field-selector). Vazios coletam tudo.
+	DefaultIncludeNamespaces = ""
+	DefaultExcludeNamespaces = ""
+	DefaultPodLabelSelector  = ""
+	DefaultPodFieldSelector  = ""
+
+	// DefaultCleanupGracePeriod é o tempo entre o addon (ou a ManagedCluster) ser removido e os
+	// objetos do relatório serem apagados do hub, para arquivar os dados antes (ver hub.ReportCleaner).
+	DefaultCleanupGracePeriod = "1h"
 )
 
 // FS contém os templates embarcados (manifests/templates).
@@ -29,36 +71,128 @@ const (
 var FS embed.FS
 
 // NewRegistrationOption configura o registro do agent no hub.
-// Fluxo: CSR criado → aprovado via CSRApproveCheck → registration-agent gera kubeconfig
-func NewRegistrationOption(kubeConfig *rest.Config, addonName, agentName string) *agent.RegistrationOption {
+// Fluxo: CSR criado → validado e aprovado via CSRApproveCheck → registration-agent gera kubeconfig
+// O nome do agent no certificado é estável por cluster (ver hub.AgentName).
+func NewRegistrationOption(kubeConfig *rest.Config, addonName string) *agent.RegistrationOption {
 	return &agent.RegistrationOption{
-		CSRConfigurations: agent.KubeClientSignerConfigurations(addonName, agentName),
-		CSRApproveCheck:   utils.DefaultCSRApprover(agentName), // aprova automaticamente
-		PermissionConfig:  hub.AddonRBAC(kubeConfig),           // cria Role/RoleBinding no hub
+		CSRConfigurations: hub.SignerConfigurations(addonName),
+		CSRApproveCheck:   hub.CSRApprover(kubeConfig), // valida identidade, usages e validade
+		// cria Role/RoleBinding no hub, restritas aos objetos do relatório
+		PermissionConfig: hub.AddonRBAC(kubeConfig, reportBackend(), collectors(), historySize()),
 	}
 }
 
 // GetDefaultValues retorna valores para renderizar os templates.
-// Campos: {{ .KubeConfigSecret }}, {{ .ClusterName }}, {{ .Image }}, {{ .AddonInstallNamespace }}
+// Campos: {{ .KubeConfigSecret }}, {{ .ClusterName }}, {{ .Image }}, {{ .ReportBackend }}, {{ .ReportEncoding }},
+// {{ .HistorySize }}, {{ .HistoryMaxAge }}, {{ .Collectors }}, {{ .Metrics }}, {{ .AllowedRegistries }},
+// {{ .IncludeNamespaces }}, {{ .ExcludeNamespaces }}, {{ .PodLabelSelector }}, {{ .PodFieldSelector }},
+// {{ .AddonInstallNamespace }}. Todos exceto KubeConfigSecret e ClusterName podem ser sobrescritos
+// pelas variáveis ADDON_<CAMPO> do controller (ex: ADDON_IMAGE, ADDON_EXCLUDE_NAMESPACES).
+// {{ .SpokeRules }} são as regras da ClusterRole do agent, derivadas de Collectors e Metrics.
 func GetDefaultValues(cluster *clusterv1.ManagedCluster,
 	addon *addonapiv1alpha1.ManagedClusterAddOn) (addonfactory.Values, error) {
 
-	image := os.Getenv("ADDON_IMAGE")
-	if image == "" {
-		image = DefaultImage
+	metrics := getEnv("ADDON_METRICS", DefaultMetrics)
+	rules, err := spokeRules(metrics)
+	if err != nil {
+		return nil, err
 	}
-
 	return addonfactory.StructToValues(struct {
-		KubeConfigSecret string
-		ClusterName      string
-		Image            string
+		KubeConfigSecret  string
+		ClusterName       string
+		Image             string
+		ReportBackend     string
+		ReportEncoding    string
+		HistorySize       string
+		HistoryMaxAge     string
+		Collectors        string
+		Metrics           string
+		AllowedRegistries string
+		IncludeNamespaces string
+		ExcludeNamespaces string
+		PodLabelSelector  string
+		PodFieldSelector  string
+		SpokeRules        []rbacv1.PolicyRule
 	}{
-		KubeConfigSecret: fmt.Sprintf("%s-hub-kubeconfig", addon.Name),
-		ClusterName:      cluster.Name,
-		Image:            image,
+		KubeConfigSecret:  fmt.Sprintf("%s-hub-kubeconfig", addon.Name),
+		ClusterName:       cluster.Name,
+		Image:             getEnv("ADDON_IMAGE", DefaultImage),
+		ReportBackend:     reportBackend(),
+		ReportEncoding:    getEnv("ADDON_REPORT_ENCODING", DefaultReportEncoding),
+		HistorySize:       getEnv("ADDON_HISTORY_SIZE", DefaultHistorySize),
+		HistoryMaxAge:     getEnv("ADDON_HISTORY_MAX_AGE", DefaultHistoryMaxAge),
+		Collectors:        strings.Join(collectors(), ","),
+		Metrics:           metrics,
+		AllowedRegistries: getEnv("ADDON_ALLOWED_REGISTRIES", DefaultAllowedRegistries),
+		IncludeNamespaces: getEnv("ADDON_INCLUDE_NAMESPACES", DefaultIncludeNamespaces),
+		ExcludeNamespaces: getEnv("ADDON_EXCLUDE_NAMESPACES", DefaultExcludeNamespaces),
+		PodLabelSelector:  getEnv("ADDON_POD_LABEL_SELECTOR", DefaultPodLabelSelector),
+		PodFieldSelector:  getEnv("ADDON_POD_FIELD_SELECTOR", DefaultPodFieldSelector),
+		SpokeRules:        rules,
 	}), nil
 }
 
+// reportBackend retorna o backend do relatório (ADDON_REPORT_BACKEND).
+// O mesmo valor define o --report-backend do agent e as permissões da Role no hub.
+func reportBackend() string {
+	return getEnv("ADDON_REPORT_BACKEND", DefaultReportBackend)
+}
+
+// collectors retorna os collectors habilitados (ADDON_COLLECTORS).
+// O mesmo valor define o --collectors do agent e as permissões da Role no hub.
+func collectors() []string {
+	var names []string
+	for _, name := range strings.Split(getEnv("ADDON_COLLECTORS", DefaultCollectors), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// historySize retorna o tamanho do histórico (ADDON_HISTORY_SIZE), que define os nomes
+// pod-report-history-<n> liberados na Role do agent no hub. Valor inválido usa o padrão do agent.
+func historySize() int {
+	raw := getEnv("ADDON_HISTORY_SIZE", DefaultHistorySize)
+	size, err := strconv.Atoi(raw)
+	if err != nil || size < 0 {
+		klog.Warningf("ADDON_HISTORY_SIZE inválido (%q), usando %d", raw, addonagent.HistorySize)
+		return addonagent.HistorySize
+	}
+	return size
+}
+
+// CleanupGracePeriod retorna o grace period da limpeza dos relatórios no hub
+// (ADDON_CLEANUP_GRACE_PERIOD, duração como "30m"; 0 remove na próxima varredura).
+func CleanupGracePeriod() (time.Duration, error) {
+	d, err := time.ParseDuration(getEnv("ADDON_CLEANUP_GRACE_PERIOD", DefaultCleanupGracePeriod))
+	if err != nil {
+		return 0, fmt.Errorf("ADDON_CLEANUP_GRACE_PERIOD: %w", err)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("ADDON_CLEANUP_GRACE_PERIOD: duração negativa %s", d)
+	}
+	return d, nil
+}
+
+// spokeRules retorna as regras da ClusterRole do agent no spoke: só leitura dos recursos dos
+// collectors habilitados e, com metrics, do metrics.k8s.io (ver agent.CollectorRules).
+func spokeRules(metrics string) ([]rbacv1.PolicyRule, error) {
+	enabled, err := strconv.ParseBool(metrics)
+	if err != nil {
+		return nil, fmt.Errorf("ADDON_METRICS: %w", err)
+	}
+	return addonagent.CollectorRules(&addonagent.AgentOptions{Collectors: collectors(), Metrics: enabled})
+}
+
+// getEnv retorna a variável de ambiente key ou def se estiver vazia.
+func getEnv(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
 // AgentHealthProber retorna o health prober usando Lease.
 // O agent atualiza o Lease no spoke, registration-agent observa e reporta status pro hub.
 func AgentHealthProber() *agent.HealthProber {
@@ -2,12 +2,19 @@ package addon
 
 import (
 	"os"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"open-cluster-management.io/addon-framework/pkg/addonfactory"
 	"open-cluster-management.io/addon-framework/pkg/agent"
 	addonapiv1alpha1 "open-cluster-management.io/api/addon/v1alpha1"
 	clusterv1 "open-cluster-management.io/api/cluster/v1"
+
+	addonagent "github.com/totvs/addon-framework-basic/pkg/agent"
 )
 
 func TestGetDefaultValues(t *testing.T) {
@@ -32,6 +39,24 @@ func TestGetDefaultValues(t *testing.T) {
 	if values["Image"] != DefaultImage {
 		t.Errorf("Image = %v, want %v", values["Image"], DefaultImage)
 	}
+	if values["ReportBackend"] != DefaultReportBackend {
+		t.Errorf("ReportBackend = %v, want %v", values["ReportBackend"], DefaultReportBackend)
+	}
+	if values["ReportEncoding"] != DefaultReportEncoding {
+		t.Errorf("ReportEncoding = %v, want %v", values["ReportEncoding"], DefaultReportEncoding)
+	}
+	if values["HistorySize"] != DefaultHistorySize || values["HistoryMaxAge"] != DefaultHistoryMaxAge {
+		t.Errorf("History = %v/%v, want %v/%v", values["HistorySize"], values["HistoryMaxAge"], DefaultHistorySize, DefaultHistoryMaxAge)
+	}
+	if values["Collectors"] != DefaultCollectors {
+		t.Errorf("Collectors = %v, want %v", values["Collectors"], DefaultCollectors)
+	}
+	if values["Metrics"] != DefaultMetrics {
+		t.Errorf("Metrics = %v, want %v", values["Metrics"], DefaultMetrics)
+	}
+	if values["AllowedRegistries"] != DefaultAllowedRegistries {
+		t.Errorf("AllowedRegistries = %v, want empty", values["AllowedRegistries"])
+	}
 }
 
 func TestGetDefaultValuesCustomImage(t *testing.T) {
@@ -58,6 +83,83 @@ func TestGetDefaultValuesCustomImage(t *testing.T) {
 	}
 }
 
+func TestGetDefaultValuesGzipEncoding(t *testing.T) {
+	// Arrange
+	os.Setenv("ADDON_REPORT_ENCODING", "gzip")
+	defer os.Unsetenv("ADDON_REPORT_ENCODING")
+
+	cluster := &clusterv1.ManagedCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster1"},
+	}
+	addon := &addonapiv1alpha1.ManagedClusterAddOn{
+		ObjectMeta: metav1.ObjectMeta{Name: "basic-addon", Namespace: "cluster1"},
+	}
+
+	// Act
+	values, err := GetDefaultValues(cluster, addon)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if values["ReportEncoding"] != "gzip" {
+		t.Errorf("ReportEncoding = %v, want gzip", values["ReportEncoding"])
+	}
+}
+
+func TestGetDefaultValuesCollectors(t *testing.T) {
+	// Arrange
+	os.Setenv("ADDON_COLLECTORS", "pods, nodes,")
+	defer os.Unsetenv("ADDON_COLLECTORS")
+
+	cluster := &clusterv1.ManagedCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster1"},
+	}
+	addon := &addonapiv1alpha1.ManagedClusterAddOn{
+		ObjectMeta: metav1.ObjectMeta{Name: "basic-addon", Namespace: "cluster1"},
+	}
+
+	// Act
+	values, err := GetDefaultValues(cluster, addon)
+
+	// Assert: the value is normalized for --collectors
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if values["Collectors"] != "pods,nodes" {
+		t.Errorf("Collectors = %v, want pods,nodes", values["Collectors"])
+	}
+}
+
+func TestGetDefaultValuesFilters(t *testing.T) {
+	// Arrange
+	os.Setenv("ADDON_EXCLUDE_NAMESPACES", "kube-*,tenant-*")
+	defer os.Unsetenv("ADDON_EXCLUDE_NAMESPACES")
+	os.Setenv("ADDON_POD_LABEL_SELECTOR", "tier!=internal")
+	defer os.Unsetenv("ADDON_POD_LABEL_SELECTOR")
+
+	cluster := &clusterv1.ManagedCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster1"},
+	}
+	addon := &addonapiv1alpha1.ManagedClusterAddOn{
+		ObjectMeta: metav1.ObjectMeta{Name: "basic-addon", Namespace: "cluster1"},
+	}
+
+	// Act
+	values, err := GetDefaultValues(cluster, addon)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if values["ExcludeNamespaces"] != "kube-*,tenant-*" || values["PodLabelSelector"] != "tier!=internal" {
+		t.Errorf("ExcludeNamespaces/PodLabelSelector = %v/%v", values["ExcludeNamespaces"], values["PodLabelSelector"])
+	}
+	if values["IncludeNamespaces"] != "" || values["PodFieldSelector"] != "" {
+		t.Errorf("IncludeNamespaces/PodFieldSelector = %v/%v, want empty", values["IncludeNamespaces"], values["PodFieldSelector"])
+	}
+}
+
 func TestAgentHealthProber(t *testing.T) {
 	// Act
 	prober := AgentHealthProber()
@@ -67,3 +169,117 @@ func TestAgentHealthProber(t *testing.T) {
 		t.Errorf("Type = %v, want Lease", prober.Type)
 	}
 }
+
+func TestManifestsLeastPrivilege(t *testing.T) {
+	// Arrange: every collector and metrics enabled, the widest ClusterRole possible
+	os.Setenv("ADDON_COLLECTORS", strings.Join(addonagent.Collectors(), ","))
+	defer os.Unsetenv("ADDON_COLLECTORS")
+	os.Setenv("ADDON_METRICS", "true")
+	defer os.Unsetenv("ADDON_METRICS")
+	agentAddon, err := addonfactory.NewAgentAddonFactory(AddonName, FS, "manifests/templates").
+		WithGetValuesFuncs(GetDefaultValues).
+		BuildTemplateAgentAddon()
+	if err != nil {
+		t.Fatalf("build addon: %v", err)
+	}
+	cluster := &clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster1"}}
+	addon := &addonapiv1alpha1.ManagedClusterAddOn{ObjectMeta: metav1.ObjectMeta{Name: AddonName, Namespace: "cluster1"}}
+
+	// Act
+	objects, err := agentAddon.Manifests(cluster, addon)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("manifests: %v", err)
+	}
+	var clusterRole *rbacv1.ClusterRole
+	for _, obj := range objects {
+		switch o := obj.(type) {
+		case *rbacv1.ClusterRole:
+			clusterRole = o
+			assertNoWildcards(t, o.Rules)
+		case *rbacv1.Role:
+			assertNoWildcards(t, o.Rules)
+		case *rbacv1.ClusterRoleBinding:
+			if o.RoleRef.Name != "basic-addon-agent" {
+				t.Errorf("ClusterRoleBinding roleRef = %s, want basic-addon-agent", o.RoleRef.Name)
+			}
+		}
+	}
+	if clusterRole == nil {
+		t.Fatal("ClusterRole not rendered")
+	}
+	want, _ := addonagent.CollectorRules(&addonagent.AgentOptions{Collectors: addonagent.Collectors(), Metrics: true})
+	if !reflect.DeepEqual(clusterRole.Rules, want) {
+		t.Errorf("ClusterRole rules = %+v, want %+v", clusterRole.Rules, want)
+	}
+	for _, r := range clusterRole.Rules {
+		for _, verb := range r.Verbs {
+			if verb != "get" && verb != "list" && verb != "watch" {
+				t.Errorf("ClusterRole grants %q on %v, want read-only", verb, r.Resources)
+			}
+		}
+	}
+}
+
+func TestGetDefaultValuesInvalidMetrics(t *testing.T) {
+	// Arrange
+	os.Setenv("ADDON_METRICS", "sim")
+	defer os.Unsetenv("ADDON_METRICS")
+	cluster := &clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster1"}}
+	addon := &addonapiv1alpha1.ManagedClusterAddOn{ObjectMeta: metav1.ObjectMeta{Name: AddonName, Namespace: "cluster1"}}
+
+	// Act
+	_, err := GetDefaultValues(cluster, addon)
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "ADDON_METRICS") {
+		t.Errorf("err = %v, want ADDON_METRICS error", err)
+	}
+}
+
+func TestCleanupGracePeriod(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "", want: time.Hour},
+		{value: "0", want: 0},
+		{value: "30m", want: 30 * time.Minute},
+		{value: "-1m", wantErr: true},
+		{value: "uma hora", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			// Arrange
+			os.Setenv("ADDON_CLEANUP_GRACE_PERIOD", tt.value)
+			defer os.Unsetenv("ADDON_CLEANUP_GRACE_PERIOD")
+
+			// Act
+			got, err := CleanupGracePeriod()
+
+			// Assert
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("CleanupGracePeriod() = %s, %v, want %s (error %v)", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+// assertNoWildcards falha se alguma regra usa "*" em apiGroups, resources ou verbs.
+func assertNoWildcards(t *testing.T, rules []rbacv1.PolicyRule) {
+	t.Helper()
+	if len(rules) == 0 {
+		t.Error("no rules rendered")
+	}
+	for _, r := range rules {
+		for _, values := range [][]string{r.APIGroups, r.Resources, r.Verbs} {
+			for _, v := range values {
+				if strings.Contains(v, "*") {
+					t.Errorf("wildcard %q in rule %+v", v, r)
+				}
+			}
+		}
+	}
+}
@@ -4,68 +4,152 @@ package agent
 
 import (
 	"context"
-	"encoding/json"
+	"fmt"
+	"sort"
 	"time"
 
 	"github.com/spf13/cobra"
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/errors"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/klog/v2"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
 	cmdfactory "open-cluster-management.io/addon-framework/pkg/cmd/factory"
 	"open-cluster-management.io/addon-framework/pkg/lease"
 	"open-cluster-management.io/addon-framework/pkg/version"
+
+	"github.com/totvs/addon-framework-basic/pkg/apply"
+	reportsclient "github.com/totvs/addon-framework-basic/pkg/client/clientset/versioned"
 )
 
 const (
 	// AgentName é o nome do agent.
 	AgentName = "basic-addon-agent"
 
-	// ConfigMapName é o nome do ConfigMap criado no hub (reports).
+	// FieldManager é o field manager do server-side apply das escritas do agent no hub.
+	FieldManager = AgentName
+
+	// ConfigMapName é o nome do ConfigMap criado no hub (reports) com --report-backend=configmap.
 	ConfigMapName = "pod-report"
 
-	// SyncInterval define o intervalo entre sincronizações do relatório.
+	// SyncInterval define o intervalo do resync periódico do relatório.
+	// O relatório é remontado a cada SyncInterval, mas só é gravado no hub se o conteúdo mudou.
 	SyncInterval = 60 * time.Second
 
+	// DebounceInterval é a janela que agrupa eventos de pods em um único sync.
+	// Evita republicar o relatório a cada evento durante rollouts ou rajadas de pods.
+	DebounceInterval = 5 * time.Second
+
 	// CommandAgent é o nome do subcomando.
 	// Convenção do addon-framework: "controller" para hub, "agent" para spoke.
 	CommandAgent = "agent"
 
 	// Nomes das flags - seguem convenção dos exemplos do addon-framework.
 	// Estas flags são passadas via args do Deployment (ver manifests/templates/deployment.yaml).
-	FlagHubKubeconfig  = "hub-kubeconfig"  // Caminho do kubeconfig para conectar ao hub
-	FlagClusterName    = "cluster-name"    // Nome do spoke cluster
-	FlagAddonNamespace = "addon-namespace" // Namespace onde o addon está instalado
-	FlagAddonName      = "addon-name"      // Nome do addon
+	FlagHubKubeconfig       = "hub-kubeconfig"        // Caminho do kubeconfig para conectar ao hub
+	FlagClusterName         = "cluster-name"          // Nome do spoke cluster
+	FlagAddonNamespace      = "addon-namespace"       // Namespace onde o addon está instalado
+	FlagAddonName           = "addon-name"            // Nome do addon
+	FlagSyncInterval        = "sync-interval"         // Intervalo do resync periódico
+	FlagDebounce            = "debounce-interval"     // Janela de debounce dos eventos de pods
+	FlagHeartbeat           = "heartbeat-interval"    // Intervalo do heartbeat quando o relatório não muda
+	FlagSpoolSize           = "spool-size"            // Relatórios guardados no spoke com o hub inacessível
+	FlagHistorySize         = "history-size"          // Relatórios anteriores mantidos no hub
+	FlagHistoryMaxAge       = "history-max-age"       // Idade máxima dos relatórios do histórico
+	FlagReportEncoding      = "report-encoding"       // Encoding do relatório no hub: json ou gzip
+	FlagReportBackend       = "report-backend"        // Onde gravar o relatório no hub: crd ou configmap
+	FlagCollectors          = "collectors"            // Collectors habilitados (ver Collector)
+	FlagEventDigestSize     = "event-digest-size"     // Entradas do digest de eventos Warning
+	FlagEventDigestInterval = "event-digest-interval" // Intervalo mínimo entre digests de eventos
+	FlagMetrics             = "metrics"               // Consulta o uso de CPU e memória no metrics.k8s.io
+	FlagMetricsInterval     = "metrics-interval"      // Intervalo mínimo entre consultas ao metrics.k8s.io
+	FlagAllowedRegistries   = "allowed-registries"    // Allow-list de registries do inventário de imagens
+	FlagIncludeNamespaces   = "include-namespaces"    // Globs dos namespaces coletados
+	FlagExcludeNamespaces   = "exclude-namespaces"    // Globs dos namespaces ignorados
+	FlagPodLabelSelector    = "pod-label-selector"    // Label selector dos pods coletados
+	FlagPodFieldSelector    = "pod-field-selector"    // Field selector dos pods coletados
+	FlagPodPageSize         = "pod-page-size"         // Pods por página na listagem inicial
 )
 
 // PodReport é o dado enviado para o hub.
 // Contém informações sobre os pods do spoke.
+// APIVersion identifica a versão do schema; mudanças incompatíveis criam uma nova versão
+// com conversão das anteriores (ver schema.go).
 type PodReport struct {
-	ClusterName string    `json:"clusterName"`
-	Timestamp   time.Time `json:"timestamp"`
-	TotalPods   int       `json:"totalPods"`
-	Pods        []PodInfo `json:"pods"`
+	APIVersion  string         `json:"apiVersion"`
+	ClusterName string         `json:"clusterName"`
+	Timestamp   time.Time      `json:"timestamp"`
+	TotalPods   int            `json:"totalPods"`
+	Phases      map[string]int `json:"phases"` // Quantidade de pods por fase (Running, Pending, ...)
+	Pods        []PodInfo      `json:"pods"`
+	// Resources soma requests e limits por cluster e por namespace e compara com as ResourceQuotas
+	Resources *ResourceSummary `json:"resources,omitempty"`
+	// Filters são os filtros de coleta em vigor (ausente quando o agent coleta tudo)
+	Filters *CollectionFilters `json:"filters,omitempty"`
 }
 
 // PodInfo contém informações básicas de um pod.
+// Status é a fase do pod (Status.Phase); o detalhe de cada container fica em Containers,
+// já que um pod em CrashLoopBackOff continua na fase Running.
 type PodInfo struct {
-	Name      string `json:"name"`
-	Namespace string `json:"namespace"`
-	Status    string `json:"status"`
+	Name       string           `json:"name"`
+	Namespace  string           `json:"namespace"`
+	Status     string           `json:"status"`
+	Restarts   int32            `json:"restarts"` // Soma dos restarts dos containers (sem init containers)
+	Containers []ContainerInfo  `json:"containers,omitempty"`
+	Workload   *WorkloadRef     `json:"workload,omitempty"` // Dono resolvido pelas ownerReferences (ver ownerResolver)
+	Usage      *ResourceAmounts `json:"usage,omitempty"`    // Uso medido pelo metrics-server (só com --metrics)
+}
+
+// ContainerInfo contém o estado de um container (ou init container) do pod.
+// Montado a partir de Status.ContainerStatuses e Status.InitContainerStatuses.
+type ContainerInfo struct {
+	Name                  string `json:"name"`
+	Init                  bool   `json:"init,omitempty"`
+	Image                 string `json:"image"`
+	ImageID               string `json:"imageID,omitempty"`
+	Ready                 bool   `json:"ready"`
+	RestartCount          int32  `json:"restartCount"`
+	State                 string `json:"state"`                           // waiting, running ou terminated
+	WaitingReason         string `json:"waitingReason,omitempty"`         // ex: CrashLoopBackOff, ImagePullBackOff
+	LastTerminationReason string `json:"lastTerminationReason,omitempty"` // ex: OOMKilled, Error
 }
 
 // AgentOptions define a configuração do agent.
 // Estes campos são preenchidos pelas flags do comando.
 // Não é uma interface do OCM, mas segue a convenção dos exemplos do addon-framework.
 type AgentOptions struct {
-	HubKubeconfigFile string // Kubeconfig para conectar ao hub (criado pelo registration-agent)
-	SpokeClusterName  string // Nome do cluster spoke (usado como namespace no hub)
-	AddonName         string // Nome do addon
-	AddonNamespace    string // Namespace onde o addon está instalado no spoke
+	HubKubeconfigFile string        // Kubeconfig para conectar ao hub (criado pelo registration-agent)
+	SpokeClusterName  string        // Nome do cluster spoke (usado como namespace no hub)
+	AddonName         string        // Nome do addon
+	AddonNamespace    string        // Namespace onde o addon está instalado no spoke
+	SyncInterval      time.Duration // Intervalo do resync periódico (padrão: SyncInterval)
+	DebounceInterval  time.Duration // Janela de debounce dos eventos de pods (padrão: DebounceInterval)
+	HeartbeatInterval time.Duration // Intervalo do heartbeat quando o relatório não muda (padrão: HeartbeatInterval)
+	SpoolSize         int           // Relatórios guardados no spoke com o hub inacessível (0 desliga o spool)
+	HistorySize       int           // Relatórios anteriores mantidos no hub (0 desliga o histórico)
+	HistoryMaxAge     time.Duration // Idade máxima dos relatórios do histórico (0 = sem limite)
+	ReportEncoding    string        // Encoding do relatório no hub: EncodingJSON ou EncodingGzip (só backend configmap)
+	ReportBackend     string        // Onde gravar o relatório no hub: BackendCRD ou BackendConfigMap
+	Collectors        []string      // Collectors habilitados (padrão: CollectorPods)
+
+	EventDigestSize     int           // Entradas do digest de eventos (padrão: EventDigestSize)
+	EventDigestInterval time.Duration // Intervalo mínimo entre digests de eventos (padrão: EventDigestInterval)
+
+	Metrics         bool          // Consulta PodMetrics e NodeMetrics no metrics.k8s.io (ver usageSource)
+	MetricsInterval time.Duration // Intervalo mínimo entre consultas ao metrics.k8s.io (padrão: MetricsInterval)
+
+	AllowedRegistries []string // Registries permitidos no inventário de imagens (vazio desliga o check)
+
+	IncludeNamespaces []string // Globs dos namespaces coletados (vazio = todos)
+	ExcludeNamespaces []string // Globs dos namespaces ignorados (prioridade sobre IncludeNamespaces)
+	PodLabelSelector  string   // Label selector dos pods coletados
+	PodFieldSelector  string   // Field selector dos pods coletados
+	PodPageSize       int64    // Pods por página na listagem (padrão: PodPageSize; 0 lista tudo de uma vez)
+
+	metricsClient metricsclientset.Interface // Cliente do metrics.k8s.io no spoke; nil sem --metrics
 }
 
 // NewAgentCommand cria o subcomando "agent".
@@ -88,6 +172,25 @@ func NewAgentCommand(addonName string) *cobra.Command {
 	flags.StringVar(&o.SpokeClusterName, FlagClusterName, "", "Nome do spoke cluster")
 	flags.StringVar(&o.AddonNamespace, FlagAddonNamespace, "", "Namespace onde o addon está instalado")
 	flags.StringVar(&o.AddonName, FlagAddonName, addonName, "Nome do addon")
+	flags.DurationVar(&o.SyncInterval, FlagSyncInterval, SyncInterval, "Intervalo do resync periódico do relatório")
+	flags.DurationVar(&o.DebounceInterval, FlagDebounce, DebounceInterval, "Janela que agrupa eventos de pods em um único sync")
+	flags.DurationVar(&o.HeartbeatInterval, FlagHeartbeat, HeartbeatInterval, "Intervalo do heartbeat no hub enquanto o relatório não muda")
+	flags.IntVar(&o.SpoolSize, FlagSpoolSize, SpoolSize, "Relatórios não enviados guardados no spoke (ConfigMaps em --addon-namespace); 0 desliga")
+	flags.IntVar(&o.HistorySize, FlagHistorySize, HistorySize, "Relatórios anteriores mantidos no hub (pod-report-history-<n>); 0 desliga")
+	flags.DurationVar(&o.HistoryMaxAge, FlagHistoryMaxAge, HistoryMaxAge, "Idade máxima dos relatórios do histórico; 0 = sem limite")
+	flags.StringVar(&o.ReportEncoding, FlagReportEncoding, EncodingJSON, "Encoding do relatório no hub: json ou gzip (binaryData). Só para --report-backend=configmap")
+	flags.StringVar(&o.ReportBackend, FlagReportBackend, BackendCRD, "Onde gravar o relatório no hub: crd (PodReport) ou configmap (pod-report)")
+	flags.IntVar(&o.EventDigestSize, FlagEventDigestSize, EventDigestSize, "Entradas do digest de eventos Warning (collector events); as mais antigas são descartadas")
+	flags.DurationVar(&o.EventDigestInterval, FlagEventDigestInterval, EventDigestInterval, "Intervalo mínimo entre dois digests de eventos publicados no hub")
+	flags.BoolVar(&o.Metrics, FlagMetrics, false, "Inclui o uso de CPU e memória do metrics.k8s.io (metrics-server) em pods e nodes; sem metrics-server o relatório segue sem uso")
+	flags.DurationVar(&o.MetricsInterval, FlagMetricsInterval, MetricsInterval, "Intervalo mínimo entre consultas ao metrics.k8s.io")
+	flags.StringSliceVar(&o.AllowedRegistries, FlagAllowedRegistries, nil, "Registries permitidos (collector images), separados por vírgula: globs (*.azurecr.io) ou prefixos com repositório (ghcr.io/org); vazio desliga o check")
+	flags.StringSliceVar(&o.IncludeNamespaces, FlagIncludeNamespaces, nil, "Namespaces coletados, separados por vírgula (globs, ex: team-*); vazio coleta todos")
+	flags.StringSliceVar(&o.ExcludeNamespaces, FlagExcludeNamespaces, nil, "Namespaces ignorados, separados por vírgula (globs, ex: kube-*); têm prioridade sobre --include-namespaces")
+	flags.StringVar(&o.PodLabelSelector, FlagPodLabelSelector, "", "Label selector dos pods coletados (ex: tier!=internal)")
+	flags.StringVar(&o.PodFieldSelector, FlagPodFieldSelector, "", "Field selector dos pods coletados (ex: status.phase!=Succeeded)")
+	flags.Int64Var(&o.PodPageSize, FlagPodPageSize, PodPageSize, "Pods por página (limit/continue) na listagem do informer de pods; 0 lista tudo em uma chamada")
+	flags.StringSliceVar(&o.Collectors, FlagCollectors, []string{CollectorPods}, "Collectors habilitados, separados por vírgula; cada um além de pods grava <collector>-report no hub")
 
 	return cmd
 }
@@ -104,7 +207,7 @@ func NewAgentCommand(addonName string) *cobra.Command {
 // 1. Cria cliente para o spoke (cluster local onde o agent roda)
 // 2. Cria cliente para o hub (usando --hub-kubeconfig, criado pelo registration-agent)
 // 3. Inicia o LeaseUpdater (health check - o hub verifica se o lease está sendo atualizado)
-// 4. Inicia loop de sync: coleta pods e envia relatório para o hub
+// 4. Inicia os informers dos collectors e o loop de sync (ver run)
 // O próprio OCM injeta automaticamente o kubeconfig, por se tratar de um addon.
 // cada addon tem seu próprio kubeconfig
 // o registration vê que o addon precisa de credenciais e : cria csr no hub
@@ -119,6 +222,14 @@ func (o *AgentOptions) RunAgent(ctx context.Context, kubeconfig *rest.Config) er
 	if err != nil {
 		return err
 	}
+	// Cliente do metrics.k8s.io; a API só é consultada nos syncs, então o metrics-server
+	// pode faltar ou ser instalado depois (ver usageSource)
+	if o.Metrics {
+		o.metricsClient, err = metricsclientset.NewForConfig(kubeconfig)
+		if err != nil {
+			return err
+		}
+	}
 
 	// Cliente do hub (que que vai criar o configmap de report)
 	// O kubeconfig do hub
@@ -130,78 +241,184 @@ func (o *AgentOptions) RunAgent(ctx context.Context, kubeconfig *rest.Config) er
 	if err != nil {
 		return err
 	}
-	klog.Infof("Conectado ao hub, enviando para namespace: %s", o.SpokeClusterName)
+	// Cliente tipado do PodReport (clientset gerado em pkg/client)
+	reportClient, err := reportsclient.NewForConfig(hubConfig)
+	if err != nil {
+		return err
+	}
+	publisher, err := o.newPublisher(hubClient, reportClient)
+	if err != nil {
+		return err
+	}
+	klog.Infof("Conectado ao hub, enviando para namespace: %s (backend: %s, collectors: %v)", o.SpokeClusterName, o.ReportBackend, o.Collectors)
 
 	// LeaseUpdater mantém o Lease atualizado no spoke.
 	// O registration-agent no spoke verifica se o Lease está sendo atualizado.
 	// Se parar de atualizar, o addon é marcado como Unavailable no hub.
 	// nesse contexto o lease é pq o hub precisa saber se o agent está rodando
 	// lease usa pull modal (agente atualiza um recurso local e o registration agent observa e reporta status pro hub via API spoke->hub)
-	// geralmente +utilizado em aplicações distribuídas (nesse caso os addons) 
+	// geralmente +utilizado em aplicações distribuídas (nesse caso os addons)
 	leaseUpdater := lease.NewLeaseUpdater(spokeClient, o.AddonName, o.AddonNamespace)
 	go leaseUpdater.Start(ctx)
 
-	// Loop de sincronização
-	ticker := time.NewTicker(SyncInterval)
+	return o.run(ctx, spokeClient, publisher, o.newSectionPublisher(hubClient))
+}
+
+// run observa o spoke via informers dos collectors e publica o relatório no hub.
+//
+// Fluxo:
+// 1. Cria a SharedInformerFactory e registra nela os informers de cada collector (--collectors)
+// 2. Cada evento dos informers (add/update/delete) sinaliza o canal changed
+// 3. O primeiro sinal abre uma janela de DebounceInterval; eventos dentro da janela são agrupados
+// 4. Ao fim da janela cada collector monta sua seção a partir do cache e ela é publicada (ver sync)
+// 5. Um ticker de SyncInterval garante o resync periódico mesmo sem eventos
+// 6. Syncs sem mudança de conteúdo não escrevem no hub, só o heartbeat (ver dedupPublisher)
+// 7. Com o hub inacessível os relatórios vão para o spool e são reenviados em ordem (ver spoolPublisher)
+// 8. Cada relatório gravado também entra no histórico do hub (ver historyPublisher)
+//
+// Separado de RunAgent para que os testes possam usar fake clientsets.
+func (o *AgentOptions) run(ctx context.Context, spokeClient kubernetes.Interface, publisher reportPublisher, sections *sectionPublisher) error {
+	collectors, err := newCollectors(o, o.Collectors)
+	if err != nil {
+		return err
+	}
+	if len(collectors) == 0 {
+		return fmt.Errorf("--%s: nenhum collector habilitado", FlagCollectors)
+	}
+	if err := o.filters().validate(); err != nil {
+		return err
+	}
+	syncInterval := o.SyncInterval
+	if syncInterval <= 0 {
+		syncInterval = SyncInterval
+	}
+	debounceInterval := o.DebounceInterval
+	if debounceInterval <= 0 {
+		debounceInterval = DebounceInterval
+	}
+
+	// Relatórios anteriores ficam no histórico do hub (ver historyPublisher)
+	if o.HistorySize > 0 {
+		publisher, err = newHistoryPublisher(publisher, o.HistorySize, o.HistoryMaxAge)
+		if err != nil {
+			return err
+		}
+	}
+	// Relatórios que não chegam ao hub ficam no spool do spoke (ver spoolPublisher)
+	var spool *spoolPublisher
+	if o.SpoolSize > 0 {
+		spool, err = newSpoolPublisher(ctx, publisher, spokeClient, o.AddonNamespace, o.SpoolSize)
+		if err != nil {
+			return err
+		}
+		publisher = spool
+	}
+	// Só escreve no hub quando o conteúdo muda (ver dedupPublisher)
+	publisher = newDedupPublisher(publisher, o.HeartbeatInterval)
+
+	// Resync do informer desligado (0): o resync periódico é feito pelo ticker abaixo.
+	factory := informers.NewSharedInformerFactory(spokeClient, 0)
+
+	// Canal com buffer 1: vários eventos seguidos viram um único sinal pendente.
+	changed := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+	for _, c := range collectors {
+		if err := c.Register(factory, notify); err != nil {
+			return fmt.Errorf("collector %s: %w", c.Name(), err)
+		}
+	}
+
+	factory.Start(ctx.Done())
+	defer factory.Shutdown()
+	for informerType, ok := range factory.WaitForCacheSync(ctx.Done()) {
+		if !ok {
+			return fmt.Errorf("cache do informer %v não sincronizou", informerType)
+		}
+	}
+
+	// Os eventos de add da carga inicial já estão refletidos no primeiro sync.
+	select {
+	case <-changed:
+	default:
+	}
+
+	ticker := time.NewTicker(syncInterval)
 	defer ticker.Stop()
 
 	// Sync imediato na inicialização
-	o.sync(ctx, spokeClient, hubClient)
+	o.sync(ctx, collectors, publisher, sections)
 
+	// debounce e replay ficam nil enquanto não há janela aberta ou spool pendente
+	// (select ignora canal nil)
+	var debounce <-chan time.Time
+	replay := spool.replayTimer()
 	for {
 		select {
 		case <-ctx.Done():
 			return nil
+		case <-changed:
+			if debounce == nil {
+				debounce = time.After(debounceInterval)
+			}
+		case <-debounce:
+			debounce = nil
+			o.sync(ctx, collectors, publisher, sections)
 		case <-ticker.C:
-			o.sync(ctx, spokeClient, hubClient)
+			o.sync(ctx, collectors, publisher, sections)
+		case <-replay:
+			replay = nil
+			if err := spool.Replay(ctx); err != nil {
+				klog.Warningf("%v", err)
+			}
+		}
+		if replay == nil {
+			replay = spool.replayTimer()
 		}
 	}
 }
 
-// sync coleta pods do spoke e envia relatório para o hub.
+// sync roda os collectors e envia o relatório para o hub.
 //
 // Fluxo:
-// 1. Lista todos os pods do spoke
-// 2. Monta o relatório (PodReport)
-// 3. Cria/atualiza o ConfigMap no hub (namespace = nome do spoke cluster)
+// 1. Cada collector monta sua seção a partir do cache dos informers (não consulta o apiserver)
+// 2. O PodReport (collector pods) é gravado via publisher, conforme --report-backend
+// 3. As demais seções são gravadas em <collector>-report (ver sectionPublisher)
 //
-// O ConfigMap é criado no namespace do spoke no hub. Isso permite que
-// o hub tenha visibilidade dos pods de cada spoke.
-func (o *AgentOptions) sync(ctx context.Context, spokeClient, hubClient kubernetes.Interface) {
-	// busca os pods usando o client k8s
-	// interessante que aqui temos acesso tanto ao spoke quanto hub. 
-	// livre para implementarmos qualquer tipo de integração, lógica, etc.
-	pods, err := spokeClient.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
-	if err != nil {
-		klog.Errorf("Falha ao listar pods: %v", err)
-		return
-	}
-
-	// montamos o report através de um método
-	report := o.buildReport(pods.Items)
-	// parseamos o dado
-	data, _ := json.Marshal(report)
-
-	// montamos o configmap
-	cm := &corev1.ConfigMap{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      ConfigMapName,
-			Namespace: o.SpokeClusterName, // Namespace no hub = nome do spoke
-		},
-		Data: map[string]string{"report": string(data)},
-	}
-
-	// Tenta obter o ConfigMap existente para fazer update (precisa do ResourceVersion)
-	// fazemos um "upsert" aqui.
-	existing, err := hubClient.CoreV1().ConfigMaps(o.SpokeClusterName).Get(ctx, ConfigMapName, metav1.GetOptions{})
-	if errors.IsNotFound(err) {
-		_, err = hubClient.CoreV1().ConfigMaps(o.SpokeClusterName).Create(ctx, cm, metav1.CreateOptions{})
-	} else if err == nil {
-		cm.ResourceVersion = existing.ResourceVersion
-		_, err = hubClient.CoreV1().ConfigMaps(o.SpokeClusterName).Update(ctx, cm, metav1.UpdateOptions{})
+// Tudo é gravado no namespace do spoke no hub. A falha de um collector não impede os outros.
+func (o *AgentOptions) sync(ctx context.Context, collectors []Collector, publisher reportPublisher, sections *sectionPublisher) {
+	for _, c := range collectors {
+		// interessante que aqui temos acesso tanto ao spoke quanto hub.
+		// livre para implementarmos qualquer tipo de integração, lógica, etc.
+		out, err := c.Collect(ctx)
+		if err != nil {
+			klog.Errorf("Collector %s falhou: %v", c.Name(), err)
+			continue
+		}
+		if report, ok := out.(*PodReport); ok {
+			o.publishReport(ctx, report, publisher)
+			continue
+		}
+		if err := sections.Publish(ctx, c.Name(), out); err != nil {
+			klog.Errorf("Falha ao sincronizar seção %s: %v", c.Name(), err)
+			continue
+		}
+		klog.V(2).Infof("Seção %s sincronizada", c.Name())
 	}
+}
 
-	if err != nil {
+// publishReport grava o PodReport no hub (PodReport ou ConfigMap, conforme o backend).
+func (o *AgentOptions) publishReport(ctx context.Context, report *PodReport, publisher reportPublisher) {
+	if err := publisher.Publish(ctx, report); err != nil {
+		if apply.IsConflict(err) {
+			// outro field manager é dono de campos do relatório; o apply não é forçado
+			klog.Errorf("Relatório não sincronizado, conflito de ownership no hub: %v", err)
+			return
+		}
 		klog.Errorf("Falha ao sincronizar relatório: %v", err)
 		return
 	}
@@ -209,19 +426,75 @@ func (o *AgentOptions) sync(ctx context.Context, spokeClient, hubClient kubernet
 }
 
 // buildReport cria um PodReport a partir da lista de pods.
-func (o *AgentOptions) buildReport(pods []corev1.Pod) PodReport {
+// Os pods são ordenados por namespace/nome: a ordem do lister não é estável.
+// owners resolve o workload de cada pod; nil usa só o dono direto.
+// quotas são as ResourceQuotas comparadas com o uso de cada namespace (ver buildResourceSummary).
+// usage é o uso medido de cada pod (chave namespace/nome); nil sem métricas.
+func (o *AgentOptions) buildReport(pods []*corev1.Pod, owners *ownerResolver, quotas []*corev1.ResourceQuota, usage map[string]corev1.ResourceList) PodReport {
+	sort.Slice(pods, func(i, j int) bool {
+		if pods[i].Namespace != pods[j].Namespace {
+			return pods[i].Namespace < pods[j].Namespace
+		}
+		return pods[i].Name < pods[j].Name
+	})
+
 	infos := make([]PodInfo, len(pods))
 	for i, p := range pods {
 		infos[i] = PodInfo{
 			Name:      p.Name,
 			Namespace: p.Namespace,
 			Status:    string(p.Status.Phase),
+			Workload:  owners.resolve(p),
+			Usage:     usageAmounts(usage[p.Namespace+"/"+p.Name]),
+		}
+		// init containers primeiro, na ordem em que rodam
+		for _, cs := range p.Status.InitContainerStatuses {
+			infos[i].Containers = append(infos[i].Containers, containerInfo(cs, true))
+		}
+		for _, cs := range p.Status.ContainerStatuses {
+			infos[i].Containers = append(infos[i].Containers, containerInfo(cs, false))
+			infos[i].Restarts += cs.RestartCount
 		}
 	}
 	return PodReport{
+		APIVersion:  SchemaVersion,
 		ClusterName: o.SpokeClusterName,
 		Timestamp:   time.Now().UTC(),
 		TotalPods:   len(pods),
+		Phases:      countPhases(infos),
 		Pods:        infos,
+		Resources:   buildResourceSummary(pods, quotas, usage),
+		Filters:     o.filters(),
+	}
+}
+
+// containerInfo converte o ContainerStatus do kubelet em ContainerInfo.
+func containerInfo(cs corev1.ContainerStatus, init bool) ContainerInfo {
+	info := ContainerInfo{
+		Name:         cs.Name,
+		Init:         init,
+		Image:        cs.Image,
+		ImageID:      cs.ImageID,
+		Ready:        cs.Ready,
+		RestartCount: cs.RestartCount,
+	}
+
+	switch {
+	case cs.State.Waiting != nil:
+		info.State = "waiting"
+		info.WaitingReason = cs.State.Waiting.Reason
+	case cs.State.Running != nil:
+		info.State = "running"
+	case cs.State.Terminated != nil:
+		info.State = "terminated"
+	}
+
+	// Motivo do último término (ex: OOMKilled antes do restart atual).
+	// Sem restart, um container terminado (ex: init container concluído) usa o estado atual.
+	if t := cs.LastTerminationState.Terminated; t != nil {
+		info.LastTerminationReason = t.Reason
+	} else if t := cs.State.Terminated; t != nil {
+		info.LastTerminationReason = t.Reason
 	}
+	return info
 }
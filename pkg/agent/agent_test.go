@@ -1,16 +1,21 @@
 package agent
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
 )
 
 func TestBuildReport(t *testing.T) {
 	// Arrange
 	o := &AgentOptions{SpokeClusterName: "cluster1"}
-	pods := []corev1.Pod{
+	pods := []*corev1.Pod{
 		{
 			ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "default"},
 			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
@@ -22,7 +27,7 @@ func TestBuildReport(t *testing.T) {
 	}
 
 	// Act
-	report := o.buildReport(pods)
+	report := o.buildReport(pods, nil, nil, nil)
 
 	// Assert
 	if report.ClusterName != "cluster1" {
@@ -38,3 +43,196 @@ func TestBuildReport(t *testing.T) {
 		t.Errorf("Pods[0].Name = %s, want pod1", report.Pods[0].Name)
 	}
 }
+
+func TestBuildReportContainers(t *testing.T) {
+	// Arrange
+	o := &AgentOptions{SpokeClusterName: "cluster1"}
+	pods := []*corev1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				InitContainerStatuses: []corev1.ContainerStatus{
+					{
+						Name:  "migrate",
+						Image: "migrate:v1",
+						State: corev1.ContainerState{
+							Terminated: &corev1.ContainerStateTerminated{Reason: "Completed"},
+						},
+					},
+				},
+				ContainerStatuses: []corev1.ContainerStatus{
+					{
+						Name:         "app",
+						Image:        "app:v2",
+						ImageID:      "docker.io/library/app@sha256:abc",
+						RestartCount: 7,
+						State: corev1.ContainerState{
+							Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+						},
+						LastTerminationState: corev1.ContainerState{
+							Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled"},
+						},
+					},
+					{
+						Name:         "sidecar",
+						Image:        "proxy:v1",
+						Ready:        true,
+						RestartCount: 1,
+						State: corev1.ContainerState{
+							Running: &corev1.ContainerStateRunning{},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// Act
+	report := o.buildReport(pods, nil, nil, nil)
+
+	// Assert
+	pod := report.Pods[0]
+	if pod.Restarts != 8 {
+		t.Errorf("Restarts = %d, want 8", pod.Restarts)
+	}
+	if len(pod.Containers) != 3 {
+		t.Fatalf("len(Containers) = %d, want 3", len(pod.Containers))
+	}
+
+	initC := pod.Containers[0]
+	if !initC.Init || initC.State != "terminated" || initC.LastTerminationReason != "Completed" {
+		t.Errorf("init container = %+v, want terminated/Completed", initC)
+	}
+
+	app := pod.Containers[1]
+	want := ContainerInfo{
+		Name:                  "app",
+		Image:                 "app:v2",
+		ImageID:               "docker.io/library/app@sha256:abc",
+		RestartCount:          7,
+		State:                 "waiting",
+		WaitingReason:         "CrashLoopBackOff",
+		LastTerminationReason: "OOMKilled",
+	}
+	if app != want {
+		t.Errorf("app container = %+v, want %+v", app, want)
+	}
+
+	sidecar := pod.Containers[2]
+	if !sidecar.Ready || sidecar.State != "running" || sidecar.WaitingReason != "" {
+		t.Errorf("sidecar container = %+v, want ready/running", sidecar)
+	}
+}
+
+func TestRunPublishesReportOnPodChanges(t *testing.T) {
+	// Arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	spokeClient := fake.NewClientset(newPod("default", "pod1"))
+	hubClient := fake.NewClientset()
+	o := &AgentOptions{
+		SpokeClusterName: "cluster1",
+		SyncInterval:     time.Hour, // only the debounce window triggers a sync
+		DebounceInterval: 10 * time.Millisecond,
+		Collectors:       []string{CollectorPods},
+	}
+
+	// Act
+	go func() {
+		if err := o.run(ctx, spokeClient, &configMapPublisher{client: hubClient, namespace: "cluster1", encoding: EncodingJSON}, o.newSectionPublisher(hubClient)); err != nil {
+			t.Errorf("run returned error: %v", err)
+		}
+	}()
+
+	// Assert: initial sync from the cache
+	waitForTotalPods(t, hubClient, 1)
+
+	// Act: a new pod on the spoke fires an informer event
+	if _, err := spokeClient.CoreV1().Pods("kube-system").Create(ctx, newPod("kube-system", "pod2"), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("create pod: %v", err)
+	}
+
+	// Assert
+	waitForTotalPods(t, hubClient, 2)
+
+	// Act: deletions republish the report too
+	if err := spokeClient.CoreV1().Pods("default").Delete(ctx, "pod1", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("delete pod: %v", err)
+	}
+
+	// Assert
+	report := waitForTotalPods(t, hubClient, 1)
+	if report.Pods[0].Name != "pod2" {
+		t.Errorf("Pods[0].Name = %s, want pod2", report.Pods[0].Name)
+	}
+}
+
+func TestRunPeriodicResync(t *testing.T) {
+	// Arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	spokeClient := fake.NewClientset(newPod("default", "pod1"))
+	hubClient := fake.NewClientset()
+	o := &AgentOptions{
+		SpokeClusterName:  "cluster1",
+		SyncInterval:      10 * time.Millisecond,
+		DebounceInterval:  time.Hour, // only the ticker triggers a sync
+		HeartbeatInterval: 20 * time.Millisecond,
+		Collectors:        []string{CollectorPods},
+	}
+
+	// Act
+	go func() {
+		if err := o.run(ctx, spokeClient, &configMapPublisher{client: hubClient, namespace: "cluster1", encoding: EncodingJSON}, o.newSectionPublisher(hubClient)); err != nil {
+			t.Errorf("run returned error: %v", err)
+		}
+	}()
+	first := waitForTotalPods(t, hubClient, 1)
+
+	// Assert: unchanged resyncs only write the heartbeat
+	err := wait.PollUntilContextTimeout(ctx, 5*time.Millisecond, 5*time.Second, true, func(ctx context.Context) (bool, error) {
+		cm, err := hubClient.CoreV1().ConfigMaps("cluster1").Get(ctx, ConfigMapName, metav1.GetOptions{})
+		if err != nil {
+			return false, nil
+		}
+		return cm.Annotations[HeartbeatAnnotation] != "", nil
+	})
+	if err != nil {
+		t.Fatalf("heartbeat was not written: %v", err)
+	}
+	report, err := ReadReport(ctx, hubClient, "cluster1")
+	if err != nil {
+		t.Fatalf("ReadReport: %v", err)
+	}
+	if !report.Timestamp.Equal(first.Timestamp) {
+		t.Errorf("Timestamp = %v, want %v: an unchanged report should not be rewritten", report.Timestamp, first.Timestamp)
+	}
+}
+
+func newPod(namespace, name string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+// waitForTotalPods waits until the hub report holds total pods.
+func waitForTotalPods(t *testing.T, hubClient kubernetes.Interface, total int) *PodReport {
+	t.Helper()
+	var report *PodReport
+	err := wait.PollUntilContextTimeout(context.Background(), 5*time.Millisecond, 5*time.Second, true, func(ctx context.Context) (bool, error) {
+		r, err := ReadReport(ctx, hubClient, "cluster1")
+		if err != nil {
+			return false, nil
+		}
+		report = r
+		return r.TotalPods == total, nil
+	})
+	if err != nil {
+		t.Fatalf("TotalPods never reached %d: %v", total, err)
+	}
+	return report
+}
@@ -0,0 +1,224 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/informers"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// CollectorPods é o collector do relatório de pods (PodReport). Sempre habilitado por padrão.
+const CollectorPods = "pods"
+
+// Collector coleta uma seção do relatório do spoke.
+//
+// Fluxo:
+// 1. Register cria os informers que o collector usa na factory compartilhada do agent
+// 2. Eventos desses informers chamam changed, que dispara o sync com debounce
+// 3. A cada sync, Collect monta a seção a partir do cache dos informers
+//
+// A saída do collector de pods é o PodReport (ver podsCollector); a dos demais é
+// publicada em um objeto próprio no hub (ver sectionPublisher).
+type Collector interface {
+	// Name identifica o collector na flag --collectors e no nome do objeto no hub.
+	Name() string
+	// Rules são as permissões de leitura no spoke que o collector precisa.
+	Rules() []rbacv1.PolicyRule
+	// Register registra os informers do collector; changed sinaliza mudança no cache.
+	Register(factory informers.SharedInformerFactory, changed func()) error
+	// Collect monta a seção do relatório. O resultado é serializado em JSON.
+	Collect(ctx context.Context) (interface{}, error)
+}
+
+// CollectorFactory cria o collector com as opções do agent.
+type CollectorFactory func(o *AgentOptions) Collector
+
+var (
+	collectorsMu sync.RWMutex
+	collectors   = map[string]CollectorFactory{}
+)
+
+// RegisterCollector registra um collector. Chamado no init() do arquivo do collector;
+// pacotes externos podem registrar os seus antes de NewAgentCommand.
+// O nome precisa ser um DNS label (vira o nome do ConfigMap da seção, ver SectionName).
+func RegisterCollector(name string, factory CollectorFactory) {
+	if errs := validation.IsDNS1123Label(name); len(errs) > 0 || SectionName(name) == ConfigMapName {
+		panic(fmt.Sprintf("nome de collector inválido %q: %v", name, errs))
+	}
+	collectorsMu.Lock()
+	defer collectorsMu.Unlock()
+	if _, exists := collectors[name]; exists {
+		panic(fmt.Sprintf("collector %q registrado duas vezes", name))
+	}
+	collectors[name] = factory
+}
+
+// Collectors lista os nomes dos collectors registrados, em ordem alfabética.
+func Collectors() []string {
+	collectorsMu.RLock()
+	defer collectorsMu.RUnlock()
+	return sortedKeys(collectors)
+}
+
+// CollectorRules devolve as regras de RBAC no spoke dos collectors de o.Collectors, com as do
+// metrics.k8s.io se o.Metrics. É a base da ClusterRole do agent (ver addon.GetDefaultValues):
+// regras com os mesmos apiGroups e verbs são juntadas, em ordem estável.
+func CollectorRules(o *AgentOptions) ([]rbacv1.PolicyRule, error) {
+	enabled, err := newCollectors(o, o.Collectors)
+	if err != nil {
+		return nil, err
+	}
+	var rules []rbacv1.PolicyRule
+	for _, c := range enabled {
+		rules = append(rules, c.Rules()...)
+	}
+	return mergeRules(rules), nil
+}
+
+// mergeRules junta os resources das regras com os mesmos apiGroups e verbs, sem repetições.
+// Regras com resourceNames ou nonResourceURLs ficam como estão.
+func mergeRules(rules []rbacv1.PolicyRule) []rbacv1.PolicyRule {
+	var merged []rbacv1.PolicyRule
+	index := map[string]int{}
+	for _, r := range rules {
+		if len(r.ResourceNames) > 0 || len(r.NonResourceURLs) > 0 {
+			merged = append(merged, r)
+			continue
+		}
+		key := strings.Join(r.APIGroups, ",") + "|" + strings.Join(r.Verbs, ",")
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(merged)
+			merged = append(merged, rbacv1.PolicyRule{APIGroups: r.APIGroups, Verbs: r.Verbs})
+			i = len(merged) - 1
+		}
+		for _, res := range r.Resources {
+			merged[i].Resources = appendUnique(merged[i].Resources, res)
+		}
+	}
+	for i := range merged {
+		sort.Strings(merged[i].Resources)
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return strings.Join(merged[i].APIGroups, ",") < strings.Join(merged[j].APIGroups, ",")
+	})
+	return merged
+}
+
+// newCollectors cria os collectors habilitados, na ordem de names.
+// Nomes vazios ou repetidos são ignorados; nomes desconhecidos são erro.
+func newCollectors(o *AgentOptions, names []string) ([]Collector, error) {
+	collectorsMu.RLock()
+	defer collectorsMu.RUnlock()
+	seen := map[string]bool{}
+	var enabled []Collector
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		factory, ok := collectors[name]
+		if !ok {
+			return nil, fmt.Errorf("--%s: collector desconhecido %q (disponíveis: %s)",
+				FlagCollectors, name, strings.Join(sortedKeys(collectors), ", "))
+		}
+		enabled = append(enabled, factory(o))
+	}
+	return enabled, nil
+}
+
+// sortedKeys devolve as chaves do registro em ordem alfabética.
+func sortedKeys(m map[string]CollectorFactory) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// notifyOnChange registra um handler que chama changed em qualquer evento do informer.
+func notifyOnChange(informer cache.SharedIndexInformer, changed func()) error {
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { changed() },
+		UpdateFunc: func(interface{}, interface{}) { changed() },
+		DeleteFunc: func(interface{}) { changed() },
+	})
+	return err
+}
+
+func init() {
+	RegisterCollector(CollectorPods, func(o *AgentOptions) Collector {
+		c := &podsCollector{options: o, filters: o.filters()}
+		if o.metricsClient != nil {
+			c.usage = newPodUsage(o.metricsClient, o.MetricsInterval)
+		}
+		return c
+	})
+}
+
+// podsCollector monta o PodReport a partir do cache de pods (ver buildReport).
+type podsCollector struct {
+	options *AgentOptions
+	filters *CollectionFilters
+	lister  corelisters.PodLister
+	quotas  corelisters.ResourceQuotaLister
+	owners  *ownerResolver
+	usage   *usageSource // nil sem --metrics
+}
+
+// Name implementa Collector.
+func (c *podsCollector) Name() string { return CollectorPods }
+
+// Rules implementa Collector.
+// ReplicaSets e Jobs resolvem o workload de cada pod (ver ownerResolver).
+func (c *podsCollector) Rules() []rbacv1.PolicyRule {
+	rules := append([]rbacv1.PolicyRule{{
+		APIGroups: []string{""},
+		Resources: []string{"pods", "resourcequotas"},
+		Verbs:     []string{"get", "list", "watch"},
+	}}, ownerRules()...)
+	if c.options.Metrics {
+		rules = append(rules, metricsRules("pods")...)
+	}
+	return rules
+}
+
+// Register implementa Collector.
+func (c *podsCollector) Register(factory informers.SharedInformerFactory, changed func()) error {
+	informer, lister := podInformer(factory, c.options)
+	c.lister = lister
+	c.owners = newOwnerResolver(factory)
+	quotas := factory.Core().V1().ResourceQuotas()
+	c.quotas = quotas.Lister()
+	if err := notifyOnChange(quotas.Informer(), changed); err != nil {
+		return err
+	}
+	return notifyOnChange(informer, changed)
+}
+
+// Collect implementa Collector. Devolve *PodReport.
+// Com --metrics o uso dos pods vem do metrics.k8s.io (única consulta fora do cache).
+func (c *podsCollector) Collect(ctx context.Context) (interface{}, error) {
+	// busca os pods no cache local do informer
+	pods, err := c.lister.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("falha ao listar pods: %w", err)
+	}
+	quotas, err := c.quotas.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("falha ao listar resourcequotas: %w", err)
+	}
+	pods, quotas = filterNamespaces(c.filters, pods), filterNamespaces(c.filters, quotas)
+	report := c.options.buildReport(pods, c.owners, quotas, c.usage.Get(ctx))
+	return &report, nil
+}
@@ -0,0 +1,277 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
+)
+
+// testCollectorName é o collector de teste: conta os namespaces do spoke.
+const testCollectorName = "test-namespaces"
+
+func init() {
+	RegisterCollector(testCollectorName, func(*AgentOptions) Collector { return &namespaceCollector{} })
+}
+
+type namespaceCollector struct {
+	lister corelisters.NamespaceLister
+}
+
+func (c *namespaceCollector) Name() string { return testCollectorName }
+
+func (c *namespaceCollector) Rules() []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"namespaces"}, Verbs: []string{"list", "watch"}}}
+}
+
+func (c *namespaceCollector) Register(factory informers.SharedInformerFactory, changed func()) error {
+	informer := factory.Core().V1().Namespaces()
+	c.lister = informer.Lister()
+	return notifyOnChange(informer.Informer(), changed)
+}
+
+func (c *namespaceCollector) Collect(context.Context) (interface{}, error) {
+	namespaces, err := c.lister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	return map[string]int{"namespaces": len(namespaces)}, nil
+}
+
+func newNamespace(name string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+}
+
+func TestNewCollectors(t *testing.T) {
+	// Act
+	enabled, err := newCollectors(&AgentOptions{}, []string{CollectorPods, " ", testCollectorName, CollectorPods})
+
+	// Assert: blanks and duplicates are ignored, order is kept
+	if err != nil {
+		t.Fatalf("newCollectors: %v", err)
+	}
+	if len(enabled) != 2 || enabled[0].Name() != CollectorPods || enabled[1].Name() != testCollectorName {
+		t.Errorf("collectors = %v, want [pods %s]", enabled, testCollectorName)
+	}
+
+	// Act
+	_, err = newCollectors(&AgentOptions{}, []string{"nodes-typo"})
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "nodes-typo") {
+		t.Errorf("err = %v, want unknown collector error", err)
+	}
+}
+
+func TestCollectorRules(t *testing.T) {
+	// Act
+	rules, err := CollectorRules(&AgentOptions{Collectors: []string{CollectorPods, testCollectorName, CollectorNodes}, Metrics: true})
+
+	// Assert: rules with the same groups and verbs merged, metrics.k8s.io last
+	if err != nil {
+		t.Fatalf("CollectorRules: %v", err)
+	}
+	want := []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"nodes", "pods", "resourcequotas"}, Verbs: []string{"get", "list", "watch"}},
+		{APIGroups: []string{""}, Resources: []string{"namespaces"}, Verbs: []string{"list", "watch"}},
+		{APIGroups: []string{"apps"}, Resources: []string{"replicasets"}, Verbs: []string{"get", "list", "watch"}},
+		{APIGroups: []string{"batch"}, Resources: []string{"jobs"}, Verbs: []string{"get", "list", "watch"}},
+		{APIGroups: []string{"metrics.k8s.io"}, Resources: []string{"nodes", "pods"}, Verbs: []string{"get", "list"}},
+	}
+	if !reflect.DeepEqual(rules, want) {
+		t.Errorf("rules = %+v, want %+v", rules, want)
+	}
+
+	// Act
+	_, err = CollectorRules(&AgentOptions{Collectors: []string{"nodes-typo"}})
+
+	// Assert
+	if err == nil {
+		t.Error("err = nil, want unknown collector error")
+	}
+}
+
+func TestRegisterCollectorRejectsInvalidNames(t *testing.T) {
+	for _, name := range []string{"Nodes", "pod", CollectorPods} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RegisterCollector(%q) did not panic", name)
+				}
+			}()
+			RegisterCollector(name, func(*AgentOptions) Collector { return &namespaceCollector{} })
+		})
+	}
+}
+
+func TestSectionPublisherSkipsUnchangedSections(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	hubClient := fake.NewClientset()
+	p := (&AgentOptions{SpokeClusterName: "cluster1", ReportEncoding: EncodingGzip}).newSectionPublisher(hubClient)
+
+	// Act
+	if err := p.Publish(ctx, testCollectorName, map[string]int{"namespaces": 1}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if err := p.Publish(ctx, testCollectorName, map[string]int{"namespaces": 1}); err != nil {
+		t.Fatalf("publish unchanged: %v", err)
+	}
+
+	// Assert: the unchanged section is not rewritten
+	writes := 0
+	for _, action := range hubClient.Actions() {
+		if action.GetVerb() == "patch" {
+			writes++
+		}
+	}
+	if writes != 1 {
+		t.Errorf("writes = %d, want 1", writes)
+	}
+	section, err := ReadSection(ctx, hubClient, "cluster1", testCollectorName)
+	if err != nil {
+		t.Fatalf("ReadSection: %v", err)
+	}
+	if section.Collector != testCollectorName || section.ClusterName != "cluster1" || string(section.Data) != `{"namespaces":1}` {
+		t.Errorf("section = %+v", section)
+	}
+
+	// Act: a restarted agent seeds the hash from the hub
+	restarted := (&AgentOptions{SpokeClusterName: "cluster1", ReportEncoding: EncodingGzip}).newSectionPublisher(hubClient)
+	hubClient.ClearActions()
+	if err := restarted.Publish(ctx, testCollectorName, map[string]int{"namespaces": 1}); err != nil {
+		t.Fatalf("publish after restart: %v", err)
+	}
+
+	// Assert
+	for _, action := range hubClient.Actions() {
+		if action.GetVerb() == "patch" {
+			t.Errorf("unexpected write after restart: %v", action)
+		}
+	}
+}
+
+func TestSectionPublisherWritesUsageOnHeartbeatCadence(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	hubClient := fake.NewClientset()
+	p := (&AgentOptions{SpokeClusterName: "cluster1", HeartbeatInterval: 10 * time.Minute}).newSectionPublisher(hubClient)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	p.now = func() time.Time { return now }
+	nodes := func(cpu string) *NodeReport {
+		return &NodeReport{TotalNodes: 1, Nodes: []NodeInfo{{Name: "node-a", Usage: &ResourceAmounts{CPU: cpu, Memory: "3Gi"}}}}
+	}
+	writes := func() int {
+		n := 0
+		for _, action := range hubClient.Actions() {
+			if action.GetVerb() == "patch" {
+				n++
+			}
+		}
+		return n
+	}
+	if err := p.Publish(ctx, CollectorNodes, nodes("1250m")); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	// Act: only the usage changes inside the heartbeat interval
+	now = now.Add(time.Minute)
+	if err := p.Publish(ctx, CollectorNodes, nodes("1300m")); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	// Assert
+	if got := writes(); got != 1 {
+		t.Errorf("writes = %d, want 1", got)
+	}
+
+	// Act / Assert: the new usage is written once the interval elapses
+	now = now.Add(10 * time.Minute)
+	if err := p.Publish(ctx, CollectorNodes, nodes("1300m")); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if got := writes(); got != 2 {
+		t.Errorf("writes = %d, want 2", got)
+	}
+	section, err := ReadSection(ctx, hubClient, "cluster1", CollectorNodes)
+	if err != nil || !strings.Contains(string(section.Data), "1300m") {
+		t.Errorf("section = %s, %v; want the latest usage", section.Data, err)
+	}
+}
+
+func TestRunPublishesCollectorSections(t *testing.T) {
+	// Arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	spokeClient := fake.NewClientset(newPod("default", "pod1"), newNamespace("default"))
+	hubClient := fake.NewClientset()
+	o := &AgentOptions{
+		SpokeClusterName: "cluster1",
+		SyncInterval:     time.Hour,
+		DebounceInterval: 10 * time.Millisecond,
+		Collectors:       []string{CollectorPods, testCollectorName},
+	}
+
+	// Act
+	go func() {
+		if err := o.run(ctx, spokeClient, &configMapPublisher{client: hubClient, namespace: "cluster1", encoding: EncodingJSON}, o.newSectionPublisher(hubClient)); err != nil {
+			t.Errorf("run returned error: %v", err)
+		}
+	}()
+
+	// Assert: each collector is published under its own object
+	waitForTotalPods(t, hubClient, 1)
+	waitForNamespaces(t, hubClient, 1)
+
+	// Act: events from the collector's informer trigger a sync
+	if _, err := spokeClient.CoreV1().Namespaces().Create(ctx, newNamespace("team-a"), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("create namespace: %v", err)
+	}
+
+	// Assert
+	waitForNamespaces(t, hubClient, 2)
+}
+
+func TestRunRejectsUnknownCollector(t *testing.T) {
+	// Arrange
+	hubClient := fake.NewClientset()
+	o := &AgentOptions{SpokeClusterName: "cluster1", Collectors: []string{"unknown"}}
+
+	// Act
+	err := o.run(context.Background(), fake.NewClientset(), &configMapPublisher{client: hubClient, namespace: "cluster1"}, o.newSectionPublisher(hubClient))
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), FlagCollectors) {
+		t.Errorf("err = %v, want --%s error", err, FlagCollectors)
+	}
+}
+
+func waitForNamespaces(t *testing.T, hubClient *fake.Clientset, want int) {
+	t.Helper()
+	var got map[string]int
+	err := wait.PollUntilContextTimeout(context.Background(), 5*time.Millisecond, 5*time.Second, true, func(ctx context.Context) (bool, error) {
+		section, err := ReadSection(ctx, hubClient, "cluster1", testCollectorName)
+		if err != nil {
+			return false, nil
+		}
+		if err := json.Unmarshal(section.Data, &got); err != nil {
+			return false, err
+		}
+		return got["namespaces"] == want, nil
+	})
+	if err != nil {
+		t.Fatalf("section namespaces = %v, want %d: %v", got, want, err)
+	}
+}
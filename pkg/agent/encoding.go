@@ -0,0 +1,83 @@
+package agent
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+const (
+	// EncodingJSON grava o relatório como JSON em ConfigMap.Data["report"] (padrão).
+	EncodingJSON = "json"
+
+	// EncodingGzip grava o JSON comprimido com gzip em ConfigMap.BinaryData["report"].
+	// Relatórios de pods comprimem ~10x, reduzindo o uso do etcd do hub.
+	EncodingGzip = "gzip"
+
+	// EncodingAnnotation declara o encoding do payload no ConfigMap do relatório.
+	EncodingAnnotation = "basic-addon.open-cluster-management.io/report-encoding"
+
+	// SchemaVersionAnnotation declara a versão do schema do PodReport no ConfigMap.
+	// A fonte da verdade é o campo apiVersion do payload; a anotação evita decodificar para filtrar.
+	SchemaVersionAnnotation = "basic-addon.open-cluster-management.io/schema-version"
+
+	// SchemaVersion é a versão atual do schema do PodReport (ver schema.go).
+	SchemaVersion = SchemaV2
+)
+
+// validEncoding indica se o encoding é suportado.
+func validEncoding(encoding string) bool {
+	return encoding == EncodingJSON || encoding == EncodingGzip
+}
+
+// encodeReport serializa o relatório em JSON e aplica o encoding (json ou gzip).
+func encodeReport(report interface{}, encoding string) ([]byte, error) {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return nil, err
+	}
+	if encoding != EncodingGzip {
+		return data, nil
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DecodePayload devolve o JSON do relatório a partir do payload lido do hub.
+// Encoding vazio é tratado como json (ConfigMaps gravados antes da anotação existir).
+func DecodePayload(data []byte, encoding string) ([]byte, error) {
+	switch encoding {
+	case "", EncodingJSON:
+		return data, nil
+	case EncodingGzip:
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		return io.ReadAll(zr)
+	default:
+		return nil, fmt.Errorf("encoding de relatório desconhecido: %q", encoding)
+	}
+}
+
+// DecodeReport decodifica um payload (json ou gzip) em PodReport, já convertido para a
+// versão atual do schema (ver decodeVersioned).
+// Útil para ferramentas do hub que leem o ConfigMap diretamente; ReadReport já faz isso.
+func DecodeReport(data []byte, encoding string) (*PodReport, error) {
+	raw, err := DecodePayload(data, encoding)
+	if err != nil {
+		return nil, err
+	}
+	return decodeVersioned(raw)
+}
@@ -0,0 +1,104 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDecodeReportBothEncodings(t *testing.T) {
+	for _, encoding := range []string{EncodingJSON, EncodingGzip} {
+		t.Run(encoding, func(t *testing.T) {
+			// Arrange
+			data, err := encodeReport(testReport(3), encoding)
+			if err != nil {
+				t.Fatalf("encode: %v", err)
+			}
+
+			// Act
+			report, err := DecodeReport(data, encoding)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if report.TotalPods != 3 || report.Pods[2].Name != "pod-2" {
+				t.Errorf("report = %+v, want 3 pods", report)
+			}
+		})
+	}
+}
+
+func TestDecodeReportLegacyWithoutAnnotation(t *testing.T) {
+	// Arrange: ConfigMaps written before the annotation existed are plain JSON
+	data, _ := json.Marshal(testReport(1))
+
+	// Act
+	report, err := DecodeReport(data, "")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if report.TotalPods != 1 {
+		t.Errorf("TotalPods = %d, want 1", report.TotalPods)
+	}
+}
+
+func TestDecodeReportUnknownEncoding(t *testing.T) {
+	// Act
+	_, err := DecodeReport([]byte("{}"), "zstd")
+
+	// Assert
+	if err == nil {
+		t.Error("expected error for unknown encoding")
+	}
+}
+
+func TestPublishConfigMapGzip(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	hubClient := fake.NewClientset()
+	plain, _ := json.Marshal(testReport(500))
+	payload, err := encodeReport(testReport(500), EncodingGzip)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	if len(payload) >= len(plain)/5 {
+		t.Errorf("gzip payload = %d bytes, want well below %d", len(payload), len(plain))
+	}
+
+	// Act: sharded and compressed at the same time
+	if err := publishConfigMap(ctx, hubClient, "cluster1", ConfigMapName, payload, EncodingGzip, nil, len(payload)/2); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	// Assert
+	head, err := hubClient.CoreV1().ConfigMaps("cluster1").Get(ctx, ConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get head: %v", err)
+	}
+	if head.Annotations[EncodingAnnotation] != EncodingGzip {
+		t.Errorf("encoding annotation = %q, want gzip", head.Annotations[EncodingAnnotation])
+	}
+	if head.Annotations[SchemaVersionAnnotation] != SchemaVersion {
+		t.Errorf("schema annotation = %q, want %s", head.Annotations[SchemaVersionAnnotation], SchemaVersion)
+	}
+	shard, err := hubClient.CoreV1().ConfigMaps("cluster1").Get(ctx, shardName(ConfigMapName, 0), metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get shard: %v", err)
+	}
+	if len(shard.BinaryData[ReportKey]) == 0 || len(shard.Data) != 0 {
+		t.Errorf("gzip shard should only use binaryData, got data=%v", shard.Data)
+	}
+	report, err := ReadReport(ctx, hubClient, "cluster1")
+	if err != nil {
+		t.Fatalf("ReadReport: %v", err)
+	}
+	if report.TotalPods != 500 {
+		t.Errorf("TotalPods = %d, want 500", report.TotalPods)
+	}
+}
@@ -0,0 +1,206 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// CollectorEvents é o collector do digest de eventos Warning (seção events-report no hub).
+	CollectorEvents = "events"
+
+	// EventDigestSize é o número padrão de entradas do digest (as mais recentes ficam).
+	EventDigestSize = 100
+
+	// EventDigestInterval é o intervalo mínimo padrão entre dois digests diferentes.
+	// Rajadas de eventos (ex: CrashLoopBackOff em muitos pods) não viram uma escrita por sync.
+	EventDigestInterval = time.Minute
+
+	// EventDigestWindow é a janela do digest: eventos vistos pela última vez antes dela saem.
+	EventDigestWindow = time.Hour
+)
+
+// EventDigest é a seção do collector events: eventos Warning do spoke agrupados por
+// objeto envolvido e reason.
+type EventDigest struct {
+	Window  string         `json:"window"`  // Janela do digest (ex: 1h0m0s)
+	Total   int            `json:"total"`   // Entradas na janela, antes do limite de tamanho
+	Dropped int            `json:"dropped"` // Entradas fora do limite (as mais antigas)
+	Events  []EventSummary `json:"events"`  // Da mais recente para a mais antiga
+}
+
+// EventSummary agrupa os eventos Warning de um objeto com o mesmo reason.
+type EventSummary struct {
+	Kind      string    `json:"kind"`
+	Namespace string    `json:"namespace,omitempty"`
+	Name      string    `json:"name"`
+	Reason    string    `json:"reason"`
+	Message   string    `json:"message"` // Mensagem do evento mais recente
+	Count     int32     `json:"count"`   // Ocorrências somadas (Event.Count / Series.Count)
+	FirstSeen time.Time `json:"firstSeen"`
+	LastSeen  time.Time `json:"lastSeen"`
+}
+
+func init() {
+	RegisterCollector(CollectorEvents, func(o *AgentOptions) Collector {
+		c := &eventsCollector{size: o.EventDigestSize, interval: o.EventDigestInterval, window: EventDigestWindow, now: time.Now, filters: o.filters()}
+		if c.size <= 0 {
+			c.size = EventDigestSize
+		}
+		if c.interval <= 0 {
+			c.interval = EventDigestInterval
+		}
+		return c
+	})
+}
+
+// eventsCollector monta o EventDigest a partir do cache de eventos Warning.
+//
+// O informer só lista e observa eventos com type=Warning (field selector no apiserver).
+// O digest é limitado a size entradas e a no máximo um digest novo a cada interval:
+// dentro do intervalo Collect devolve o anterior, que o sectionPublisher não regrava.
+// O resync periódico publica o que ficou pendente.
+type eventsCollector struct {
+	size     int
+	interval time.Duration
+	window   time.Duration
+	now      func() time.Time
+	filters  *CollectionFilters
+
+	lister corelisters.EventLister
+	last   *EventDigest
+	built  time.Time
+}
+
+// Name implementa Collector.
+func (c *eventsCollector) Name() string { return CollectorEvents }
+
+// Rules implementa Collector.
+func (c *eventsCollector) Rules() []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{{
+		APIGroups: []string{""},
+		Resources: []string{"events"},
+		Verbs:     []string{"get", "list", "watch"},
+	}}
+}
+
+// Register implementa Collector. O informer de eventos é filtrado (type=Warning), por isso
+// é registrado com InformerFor em vez de factory.Core().V1().Events().
+func (c *eventsCollector) Register(factory informers.SharedInformerFactory, changed func()) error {
+	informer := factory.InformerFor(&corev1.Event{}, newWarningEventInformer)
+	c.lister = corelisters.NewEventLister(informer.GetIndexer())
+	return notifyOnChange(informer, changed)
+}
+
+// newWarningEventInformer cria o informer de eventos com type=Warning.
+func newWarningEventInformer(client kubernetes.Interface, resync time.Duration) cache.SharedIndexInformer {
+	return coreinformers.NewFilteredEventInformer(client, metav1.NamespaceAll, resync,
+		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
+		func(opts *metav1.ListOptions) { opts.FieldSelector = "type=" + corev1.EventTypeWarning })
+}
+
+// Collect implementa Collector. Devolve *EventDigest.
+func (c *eventsCollector) Collect(context.Context) (interface{}, error) {
+	now := c.now()
+	if c.last != nil && now.Sub(c.built) < c.interval {
+		return c.last, nil
+	}
+	events, err := c.lister.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("falha ao listar eventos: %w", err)
+	}
+	c.last, c.built = buildEventDigest(filterNamespaces(c.filters, events), now.Add(-c.window), c.size), now
+	c.last.Window = c.window.String()
+	return c.last, nil
+}
+
+// buildEventDigest agrupa os eventos Warning por objeto envolvido e reason, descarta os vistos
+// antes de since e mantém as size entradas mais recentes.
+func buildEventDigest(events []*corev1.Event, since time.Time, size int) *EventDigest {
+	type key struct{ kind, namespace, name, reason string }
+	groups := map[key]*EventSummary{}
+	for _, e := range events {
+		if e.Type != corev1.EventTypeWarning {
+			continue
+		}
+		first, last, count := eventTimes(e)
+		if last.Before(since) {
+			continue
+		}
+		k := key{e.InvolvedObject.Kind, e.InvolvedObject.Namespace, e.InvolvedObject.Name, e.Reason}
+		g, ok := groups[k]
+		if !ok {
+			g = &EventSummary{Kind: k.kind, Namespace: k.namespace, Name: k.name, Reason: k.reason, FirstSeen: first, LastSeen: last}
+			groups[k] = g
+		}
+		g.Count += count
+		if first.Before(g.FirstSeen) {
+			g.FirstSeen = first
+		}
+		if !last.Before(g.LastSeen) {
+			g.LastSeen, g.Message = last, e.Message
+		}
+	}
+
+	digest := &EventDigest{Events: make([]EventSummary, 0, len(groups))}
+	for _, g := range groups {
+		digest.Events = append(digest.Events, *g)
+	}
+	sort.Slice(digest.Events, func(i, j int) bool {
+		a, b := digest.Events[i], digest.Events[j]
+		if !a.LastSeen.Equal(b.LastSeen) {
+			return a.LastSeen.After(b.LastSeen)
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Reason < b.Reason
+	})
+	digest.Total = len(digest.Events)
+	if len(digest.Events) > size {
+		digest.Dropped = len(digest.Events) - size
+		digest.Events = digest.Events[:size]
+	}
+	return digest
+}
+
+// eventTimes devolve primeira e última ocorrência e a contagem do evento. Cobre eventos da
+// API core (FirstTimestamp/LastTimestamp/Count) e da events.k8s.io (EventTime/Series).
+func eventTimes(e *corev1.Event) (first, last time.Time, count int32) {
+	first, last, count = e.FirstTimestamp.Time, e.LastTimestamp.Time, e.Count
+	if first.IsZero() {
+		first = e.EventTime.Time
+	}
+	if e.Series != nil {
+		count = e.Series.Count
+		if t := e.Series.LastObservedTime.Time; t.After(last) {
+			last = t
+		}
+	}
+	if last.IsZero() {
+		last = first
+	}
+	if first.IsZero() {
+		// Sem nenhum horário (evento malformado): usa a criação do objeto
+		first, last = e.CreationTimestamp.Time, e.CreationTimestamp.Time
+	}
+	if count < 1 {
+		count = 1
+	}
+	return first, last, count
+}
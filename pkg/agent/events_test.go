@@ -0,0 +1,141 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var eventsNow = time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+func warningEvent(name, pod, reason string, count int32, first, last time.Duration) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "default"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: pod},
+		Type:           corev1.EventTypeWarning,
+		Reason:         reason,
+		Message:        name,
+		Count:          count,
+		FirstTimestamp: metav1.NewTime(eventsNow.Add(-first)),
+		LastTimestamp:  metav1.NewTime(eventsNow.Add(-last)),
+	}
+}
+
+func TestBuildEventDigestDeduplicates(t *testing.T) {
+	// Arrange: two events for the same pod and reason, one for another reason
+	events := []*corev1.Event{
+		warningEvent("a", "web-1", "BackOff", 3, 30*time.Minute, 10*time.Minute),
+		warningEvent("b", "web-1", "BackOff", 2, 20*time.Minute, time.Minute),
+		warningEvent("c", "web-1", "FailedScheduling", 1, 5*time.Minute, 5*time.Minute),
+		{Type: corev1.EventTypeNormal, Reason: "Pulled", InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web-1"}},
+	}
+
+	// Act
+	digest := buildEventDigest(events, eventsNow.Add(-time.Hour), 10)
+
+	// Assert: most recent first; Normal events are ignored
+	if digest.Total != 2 || len(digest.Events) != 2 {
+		t.Fatalf("digest = %+v, want 2 entries", digest)
+	}
+	backoff := digest.Events[0]
+	if backoff.Reason != "BackOff" || backoff.Count != 5 {
+		t.Errorf("Events[0] = %s x%d, want BackOff x5", backoff.Reason, backoff.Count)
+	}
+	if !backoff.FirstSeen.Equal(eventsNow.Add(-30*time.Minute)) || !backoff.LastSeen.Equal(eventsNow.Add(-time.Minute)) {
+		t.Errorf("FirstSeen/LastSeen = %s/%s", backoff.FirstSeen, backoff.LastSeen)
+	}
+	if backoff.Message != "b" {
+		t.Errorf("Message = %q, want the latest one", backoff.Message)
+	}
+}
+
+func TestBuildEventDigestIsBounded(t *testing.T) {
+	// Arrange: 5 pods in the window, 1 outside
+	var events []*corev1.Event
+	for i := 0; i < 5; i++ {
+		events = append(events, warningEvent(fmt.Sprintf("e%d", i), fmt.Sprintf("pod-%d", i), "BackOff", 1, time.Duration(i)*time.Minute, time.Duration(i)*time.Minute))
+	}
+	events = append(events, warningEvent("old", "pod-old", "BackOff", 1, 3*time.Hour, 2*time.Hour))
+
+	// Act
+	digest := buildEventDigest(events, eventsNow.Add(-time.Hour), 3)
+
+	// Assert: the newest entries are kept
+	if digest.Total != 5 || digest.Dropped != 2 || len(digest.Events) != 3 {
+		t.Fatalf("Total/Dropped/len = %d/%d/%d, want 5/2/3", digest.Total, digest.Dropped, len(digest.Events))
+	}
+	if digest.Events[0].Name != "pod-0" || digest.Events[2].Name != "pod-2" {
+		t.Errorf("Events = %+v", digest.Events)
+	}
+}
+
+func TestBuildEventDigestSeries(t *testing.T) {
+	// Arrange: events.k8s.io style event (EventTime + Series)
+	e := &corev1.Event{
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web-1"},
+		Type:           corev1.EventTypeWarning,
+		Reason:         "Unhealthy",
+		EventTime:      metav1.NewMicroTime(eventsNow.Add(-10 * time.Minute)),
+		Series:         &corev1.EventSeries{Count: 7, LastObservedTime: metav1.NewMicroTime(eventsNow.Add(-time.Minute))},
+	}
+
+	// Act
+	digest := buildEventDigest([]*corev1.Event{e}, eventsNow.Add(-time.Hour), 10)
+
+	// Assert
+	got := digest.Events[0]
+	if got.Count != 7 || !got.FirstSeen.Equal(eventsNow.Add(-10*time.Minute)) || !got.LastSeen.Equal(eventsNow.Add(-time.Minute)) {
+		t.Errorf("summary = %+v", got)
+	}
+}
+
+func TestEventsCollectorRateLimitsDigests(t *testing.T) {
+	// Arrange
+	client := fake.NewClientset(warningEvent("a", "web-1", "BackOff", 1, time.Minute, time.Minute))
+	factory := informers.NewSharedInformerFactory(client, 0)
+	defer factory.Shutdown()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := eventsNow
+	c := &eventsCollector{size: 10, interval: time.Minute, window: time.Hour, now: func() time.Time { return now }}
+	changed := make(chan struct{}, 10)
+	if err := c.Register(factory, func() { changed <- struct{}{} }); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	factory.Start(ctx.Done())
+	factory.WaitForCacheSync(ctx.Done())
+	first, err := c.Collect(ctx)
+	if err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+
+	// Act: a new warning inside the interval
+	if _, err := client.CoreV1().Events("default").Create(ctx, warningEvent("b", "web-2", "FailedScheduling", 1, 0, 0), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("create event: %v", err)
+	}
+	for i := 0; i < 2; i++ { // adds of "a" (initial list) and "b"
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			t.Fatal("informer did not see the new event")
+		}
+	}
+	limited, _ := c.Collect(ctx)
+	now = now.Add(time.Minute)
+	next, _ := c.Collect(ctx)
+
+	// Assert: the digest only changes once the interval has passed
+	if limited != first || len(limited.(*EventDigest).Events) != 1 {
+		t.Errorf("digest inside the interval = %+v, want the previous one", limited)
+	}
+	if len(next.(*EventDigest).Events) != 2 {
+		t.Errorf("digest after the interval = %+v, want 2 entries", next)
+	}
+}
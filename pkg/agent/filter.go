@@ -0,0 +1,104 @@
+package agent
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// CollectionFilters são os filtros de coleta em vigor no agent. Ficam gravados no relatório
+// e em cada seção, para o hub saber que a visão do cluster é parcial.
+//
+// Os filtros de namespace valem para todos os objetos com namespace (pods, quotas, workloads,
+// eventos); os seletores valem só para os pods e são aplicados pelo apiserver.
+type CollectionFilters struct {
+	IncludeNamespaces []string `json:"includeNamespaces,omitempty"` // Globs; vazio = todos
+	ExcludeNamespaces []string `json:"excludeNamespaces,omitempty"` // Globs; têm prioridade sobre IncludeNamespaces
+	PodLabelSelector  string   `json:"podLabelSelector,omitempty"`
+	PodFieldSelector  string   `json:"podFieldSelector,omitempty"`
+}
+
+// filters devolve os filtros das flags (nil sem nenhum filtro).
+func (o *AgentOptions) filters() *CollectionFilters {
+	f := &CollectionFilters{
+		IncludeNamespaces: trimAll(o.IncludeNamespaces),
+		ExcludeNamespaces: trimAll(o.ExcludeNamespaces),
+		PodLabelSelector:  strings.TrimSpace(o.PodLabelSelector),
+		PodFieldSelector:  strings.TrimSpace(o.PodFieldSelector),
+	}
+	if len(f.IncludeNamespaces) == 0 && len(f.ExcludeNamespaces) == 0 && f.PodLabelSelector == "" && f.PodFieldSelector == "" {
+		return nil
+	}
+	return f
+}
+
+// validate confere os globs e os seletores, para o agent falhar na inicialização e não a cada sync.
+func (f *CollectionFilters) validate() error {
+	if f == nil {
+		return nil
+	}
+	for flag, globs := range map[string][]string{FlagIncludeNamespaces: f.IncludeNamespaces, FlagExcludeNamespaces: f.ExcludeNamespaces} {
+		for _, g := range globs {
+			if _, err := path.Match(g, ""); err != nil {
+				return fmt.Errorf("--%s: glob inválido %q: %w", flag, g, err)
+			}
+		}
+	}
+	if _, err := labels.Parse(f.PodLabelSelector); err != nil {
+		return fmt.Errorf("--%s: %w", FlagPodLabelSelector, err)
+	}
+	if _, err := fields.ParseSelector(f.PodFieldSelector); err != nil {
+		return fmt.Errorf("--%s: %w", FlagPodFieldSelector, err)
+	}
+	return nil
+}
+
+// allowsNamespace diz se o namespace entra na coleta. Um filtro nil aceita todos.
+func (f *CollectionFilters) allowsNamespace(namespace string) bool {
+	if f == nil {
+		return true
+	}
+	if matchAny(f.ExcludeNamespaces, namespace) {
+		return false
+	}
+	return len(f.IncludeNamespaces) == 0 || matchAny(f.IncludeNamespaces, namespace)
+}
+
+// filterNamespaces remove os objetos de namespaces fora do filtro.
+func filterNamespaces[T metav1.Object](f *CollectionFilters, items []T) []T {
+	if f == nil {
+		return items
+	}
+	kept := items[:0:0]
+	for _, item := range items {
+		if f.allowsNamespace(item.GetNamespace()) {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+// matchAny diz se name casa com algum dos globs.
+func matchAny(globs []string, name string) bool {
+	for _, g := range globs {
+		if ok, _ := path.Match(g, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// trimAll remove espaços e valores vazios da lista.
+func trimAll(values []string) []string {
+	var out []string
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,129 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestAllowsNamespace(t *testing.T) {
+	f := &CollectionFilters{IncludeNamespaces: []string{"team-*", "default"}, ExcludeNamespaces: []string{"team-secret"}}
+	cases := map[string]bool{
+		"default":     true,
+		"team-a":      true,
+		"team-secret": false, // exclude wins
+		"kube-system": false, // not included
+	}
+	for ns, want := range cases {
+		if got := f.allowsNamespace(ns); got != want {
+			t.Errorf("allowsNamespace(%q) = %v, want %v", ns, got, want)
+		}
+	}
+	if !(*CollectionFilters)(nil).allowsNamespace("kube-system") {
+		t.Errorf("nil filter must allow every namespace")
+	}
+}
+
+func TestFiltersValidate(t *testing.T) {
+	cases := map[string]*AgentOptions{
+		FlagExcludeNamespaces: {ExcludeNamespaces: []string{"kube-["}},
+		FlagPodLabelSelector:  {PodLabelSelector: "tier in (a"},
+		FlagPodFieldSelector:  {PodFieldSelector: "spec.nodeName"},
+	}
+	for flag, o := range cases {
+		// Act
+		err := o.filters().validate()
+
+		// Assert
+		if err == nil || !strings.Contains(err.Error(), flag) {
+			t.Errorf("validate = %v, want --%s error", err, flag)
+		}
+	}
+	if (&AgentOptions{}).filters() != nil {
+		t.Errorf("filters() without flags must be nil")
+	}
+}
+
+func TestPodsCollectorAppliesFilters(t *testing.T) {
+	// Arrange
+	o := &AgentOptions{
+		SpokeClusterName:  "cluster1",
+		ExcludeNamespaces: []string{"kube-*"},
+		PodLabelSelector:  "tier!=internal",
+	}
+	internal := newPod("team-a", "internal")
+	internal.Labels = map[string]string{"tier": "internal"}
+	pods := []runtime.Object{newPod("team-a", "web"), internal, newPod("kube-system", "coredns")}
+	enabled, err := newCollectors(o, []string{CollectorPods, CollectorImages})
+	if err != nil {
+		t.Fatalf("newCollectors: %v", err)
+	}
+
+	// Act
+	report := collect(t, enabled[0], pods...).(*PodReport)
+
+	// Assert: the label selector is applied by the apiserver, the namespace globs by the agent
+	if report.TotalPods != 1 || report.Pods[0].Name != "web" {
+		t.Errorf("pods = %+v, want only team-a/web", report.Pods)
+	}
+	if report.Filters == nil || report.Filters.PodLabelSelector != "tier!=internal" || report.Filters.ExcludeNamespaces[0] != "kube-*" {
+		t.Errorf("Filters = %+v, want the filters in effect", report.Filters)
+	}
+	if roundTrip := fromPodReportStatus(toPodReportStatus(report)); reportHash(roundTrip) != reportHash(report) {
+		t.Errorf("filters lost in the status round trip: %+v", roundTrip.Filters)
+	}
+
+	// Act: other collectors share the filtered pod informer
+	inv := collect(t, enabled[1], pods...).(*ImageInventory)
+
+	// Assert
+	for _, img := range inv.Images {
+		for _, p := range img.Pods {
+			if p.Namespace == "kube-system" || p.Name == "internal" {
+				t.Errorf("unexpected pod %+v in the image inventory", p)
+			}
+		}
+	}
+}
+
+func TestSectionRecordsFilters(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	hubClient := fake.NewClientset()
+	data := map[string]int{"namespaces": 1}
+	if err := (&AgentOptions{SpokeClusterName: "cluster1"}).newSectionPublisher(hubClient).Publish(ctx, testCollectorName, data); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	filtered := &AgentOptions{SpokeClusterName: "cluster1", ExcludeNamespaces: []string{"kube-*"}}
+
+	// Act: same data, new filters
+	if err := filtered.newSectionPublisher(hubClient).Publish(ctx, testCollectorName, data); err != nil {
+		t.Fatalf("publish filtered: %v", err)
+	}
+
+	// Assert: the section is rewritten with the filters
+	section, err := ReadSection(ctx, hubClient, "cluster1", testCollectorName)
+	if err != nil {
+		t.Fatalf("ReadSection: %v", err)
+	}
+	if section.Filters == nil || section.Filters.ExcludeNamespaces[0] != "kube-*" {
+		t.Errorf("section filters = %+v, want kube-*", section.Filters)
+	}
+}
+
+func TestRunRejectsInvalidFilters(t *testing.T) {
+	// Arrange
+	hubClient := fake.NewClientset()
+	o := &AgentOptions{SpokeClusterName: "cluster1", Collectors: []string{CollectorPods}, PodLabelSelector: "=="}
+
+	// Act
+	err := o.run(context.Background(), fake.NewClientset(), &configMapPublisher{client: hubClient, namespace: "cluster1"}, o.newSectionPublisher(hubClient))
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), FlagPodLabelSelector) {
+		t.Errorf("err = %v, want --%s error", err, FlagPodLabelSelector)
+	}
+}
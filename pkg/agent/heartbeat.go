@@ -0,0 +1,179 @@
+package agent
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// ContentHashAnnotation guarda o hash do conteúdo do relatório publicado (ver reportHash).
+	ContentHashAnnotation = "basic-addon.open-cluster-management.io/content-hash"
+
+	// HeartbeatAnnotation guarda o último heartbeat (RFC3339) do agent.
+	// Atualizada só quando o relatório não muda; o horário do último conteúdo novo
+	// fica no próprio relatório (timestamp / lastSyncTime).
+	HeartbeatAnnotation = "basic-addon.open-cluster-management.io/last-heartbeat"
+
+	// HeartbeatFieldManager é o field manager do heartbeat.
+	// O heartbeat é um merge patch (não cria o objeto se ele foi removido) e só ele escreve
+	// HeartbeatAnnotation, então não há conflito com o apply de FieldManager.
+	HeartbeatFieldManager = AgentName + "-heartbeat"
+
+	// HeartbeatInterval é o intervalo mínimo entre heartbeats enquanto o relatório não muda.
+	HeartbeatInterval = 10 * time.Minute
+)
+
+// reportHash calcula o hash dos campos estáveis do relatório (sem Timestamp e sem o uso medido
+// pelo metrics-server, que muda a cada consulta; ver usageHash).
+// Dois syncs com os mesmos pods geram o mesmo hash.
+func reportHash(report *PodReport) string {
+	stable := struct {
+		ClusterName string             `json:"clusterName"`
+		TotalPods   int                `json:"totalPods"`
+		Pods        []PodInfo          `json:"pods"`
+		Resources   *ResourceSummary   `json:"resources,omitempty"`
+		Filters     *CollectionFilters `json:"filters,omitempty"`
+	}{ClusterName: report.ClusterName, TotalPods: report.TotalPods, Filters: report.Filters}
+	for _, p := range report.Pods {
+		p.Usage = nil
+		stable.Pods = append(stable.Pods, p)
+	}
+	if r := report.Resources; r != nil {
+		resources := *r
+		resources.Usage, resources.Namespaces = nil, nil
+		for _, ns := range r.Namespaces {
+			ns.Usage = nil
+			resources.Namespaces = append(resources.Namespaces, ns)
+		}
+		stable.Resources = &resources
+	}
+	return hashJSON(stable)
+}
+
+// usageHash calcula o hash do uso medido no relatório (pods, cluster e namespaces).
+// Vazio sem --metrics.
+func usageHash(report *PodReport) string {
+	var usage []*ResourceAmounts
+	for _, p := range report.Pods {
+		usage = append(usage, p.Usage)
+	}
+	if r := report.Resources; r != nil {
+		usage = append(usage, r.Usage)
+		for _, ns := range r.Namespaces {
+			usage = append(usage, ns.Usage)
+		}
+	}
+	for _, u := range usage {
+		if u != nil {
+			return hashJSON(usage)
+		}
+	}
+	return ""
+}
+
+// hashJSON devolve o sha256 (hex) do JSON de v.
+func hashJSON(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		// Não acontece com esses tipos; hash vazio força a escrita
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// heartbeatPatch monta o merge patch que grava HeartbeatAnnotation.
+func heartbeatPatch(now time.Time) []byte {
+	patch := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{HeartbeatAnnotation: now.UTC().Format(time.RFC3339)},
+		},
+	}
+	data, _ := json.Marshal(patch)
+	return data
+}
+
+// dedupPublisher evita escritas no hub quando o conteúdo do relatório não mudou.
+//
+// Fluxo:
+// 1. Calcula o hash do relatório (reportHash)
+// 2. Se difere do último publicado, grava o relatório (que leva o hash em ContentHashAnnotation)
+// 3. Se é igual, só grava o heartbeat, e no máximo uma vez a cada heartbeatInterval
+// 4. Se só o uso medido mudou (--metrics, fora do hash), regrava o relatório no lugar do heartbeat
+//
+// Assim cada consulta ao metrics-server não vira uma escrita no hub.
+// O último hash fica em memória; no primeiro sync ele vem do hub (PublishedHash),
+// assim um restart do agent não regrava um relatório que já está atualizado.
+type dedupPublisher struct {
+	reportPublisher
+	heartbeatInterval time.Duration
+	now               func() time.Time
+
+	seeded    bool      // hash já lido do hub
+	hash      string    // hash do último relatório publicado
+	usage     string    // usageHash do último relatório publicado
+	lastWrite time.Time // última escrita (relatório ou heartbeat)
+}
+
+// newDedupPublisher envolve publisher com a detecção de mudanças.
+func newDedupPublisher(publisher reportPublisher, heartbeatInterval time.Duration) *dedupPublisher {
+	if heartbeatInterval <= 0 {
+		heartbeatInterval = HeartbeatInterval
+	}
+	return &dedupPublisher{reportPublisher: publisher, heartbeatInterval: heartbeatInterval, now: time.Now}
+}
+
+// Publish grava o relatório só se o conteúdo mudou; senão grava o heartbeat quando vencido.
+func (d *dedupPublisher) Publish(ctx context.Context, report *PodReport) error {
+	if !d.seeded {
+		hash, err := d.reportPublisher.PublishedHash(ctx)
+		if err != nil {
+			// Sem o hash do hub o relatório é regravado; tenta de novo no próximo sync
+			klog.Warningf("Falha ao ler hash do relatório no hub: %v", err)
+		} else {
+			d.hash, d.seeded = hash, true
+		}
+	}
+
+	hash := reportHash(report)
+	now := d.now()
+	if hash != "" && hash == d.hash {
+		if now.Sub(d.lastWrite) < d.heartbeatInterval {
+			klog.V(2).Infof("Relatório sem mudanças (hash %s), escrita ignorada", hash)
+			return nil
+		}
+		usage := usageHash(report)
+		if usage != d.usage {
+			// Só o uso mudou: o relatório com o uso atual faz as vezes do heartbeat
+			if err := d.reportPublisher.Publish(ctx, report); err != nil {
+				return err
+			}
+			d.usage, d.lastWrite = usage, now
+			klog.V(2).Infof("Uso dos pods atualizado no hub")
+			return nil
+		}
+		err := d.reportPublisher.Heartbeat(ctx, now)
+		if err == nil {
+			d.lastWrite = now
+			klog.V(2).Infof("Relatório sem mudanças, heartbeat gravado")
+			return nil
+		}
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		// Relatório removido no hub: grava de novo
+		klog.Warningf("Relatório não encontrado no hub, regravando")
+	}
+
+	if err := d.reportPublisher.Publish(ctx, report); err != nil {
+		return err
+	}
+	d.hash, d.usage, d.lastWrite = hash, usageHash(report), now
+	return nil
+}
@@ -0,0 +1,239 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+
+	reportsfake "github.com/totvs/addon-framework-basic/pkg/client/clientset/versioned/fake"
+)
+
+// recordingPublisher counts the writes dedupPublisher lets through.
+type recordingPublisher struct {
+	hash         string
+	heartbeatErr error
+	publishes    int
+	heartbeats   int
+}
+
+func (r *recordingPublisher) Publish(_ context.Context, report *PodReport) error {
+	r.publishes++
+	r.hash = reportHash(report)
+	return nil
+}
+
+func (r *recordingPublisher) PublishedHash(context.Context) (string, error) {
+	return r.hash, nil
+}
+
+func (r *recordingPublisher) Heartbeat(context.Context, time.Time) error {
+	r.heartbeats++
+	return r.heartbeatErr
+}
+
+func TestReportHashIgnoresTimestamp(t *testing.T) {
+	// Arrange
+	a, b := testReport(3), testReport(3)
+	b.Timestamp = a.Timestamp.Add(time.Minute)
+	c := testReport(3)
+	c.Pods[1].Status = "Failed"
+
+	// Act / Assert
+	if reportHash(&a) != reportHash(&b) {
+		t.Error("hash changed with Timestamp only")
+	}
+	if reportHash(&a) == reportHash(&c) {
+		t.Error("hash did not change with pod status")
+	}
+}
+
+func TestDedupPublisherSkipsUnchangedReports(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	inner := &recordingPublisher{}
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	d := newDedupPublisher(inner, 10*time.Minute)
+	d.now = func() time.Time { return now }
+	report := testReport(2)
+
+	// Act / Assert: first sync writes
+	if err := d.Publish(ctx, &report); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if inner.publishes != 1 {
+		t.Fatalf("publishes = %d, want 1", inner.publishes)
+	}
+
+	// Act / Assert: same content inside the heartbeat interval writes nothing
+	now = now.Add(time.Minute)
+	report.Timestamp = now
+	_ = d.Publish(ctx, &report)
+	if inner.publishes != 1 || inner.heartbeats != 0 {
+		t.Errorf("publishes=%d heartbeats=%d, want 1/0", inner.publishes, inner.heartbeats)
+	}
+
+	// Act / Assert: once the interval elapses only the heartbeat is written
+	now = now.Add(10 * time.Minute)
+	_ = d.Publish(ctx, &report)
+	if inner.publishes != 1 || inner.heartbeats != 1 {
+		t.Errorf("publishes=%d heartbeats=%d, want 1/1", inner.publishes, inner.heartbeats)
+	}
+
+	// Act / Assert: a content change writes the report again
+	report.Pods[0].Restarts++
+	_ = d.Publish(ctx, &report)
+	if inner.publishes != 2 {
+		t.Errorf("publishes = %d, want 2", inner.publishes)
+	}
+}
+
+func TestReportHashIgnoresUsage(t *testing.T) {
+	// Arrange: same pods, usage measured on two metrics polls
+	a, b := testReport(2), testReport(2)
+	a.Pods[0].Usage = &ResourceAmounts{CPU: "15m", Memory: "101Mi"}
+	b.Pods[0].Usage = &ResourceAmounts{CPU: "18m", Memory: "104Mi"}
+	b.Resources = &ResourceSummary{Usage: &ResourceAmounts{CPU: "18m", Memory: "104Mi"}}
+	a.Resources = &ResourceSummary{Usage: &ResourceAmounts{CPU: "15m", Memory: "101Mi"}}
+
+	// Act / Assert
+	if reportHash(&a) != reportHash(&b) {
+		t.Error("hash changed with usage only")
+	}
+	if usageHash(&a) == usageHash(&b) || usageHash(&a) == "" {
+		t.Error("usageHash did not change with usage")
+	}
+	if a.Pods[0].Usage == nil || a.Resources.Usage == nil {
+		t.Error("reportHash modified the report")
+	}
+}
+
+func TestDedupPublisherWritesUsageOnHeartbeatCadence(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	inner := &recordingPublisher{}
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	d := newDedupPublisher(inner, 10*time.Minute)
+	d.now = func() time.Time { return now }
+	report := testReport(2)
+	report.Pods[0].Usage = &ResourceAmounts{CPU: "15m", Memory: "101Mi"}
+	if err := d.Publish(ctx, &report); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	// Act: usage changes on every metrics poll inside the heartbeat interval
+	for i := 0; i < 9; i++ {
+		now = now.Add(time.Minute)
+		report.Pods[0].Usage = &ResourceAmounts{CPU: fmt.Sprintf("%dm", 16+i), Memory: "101Mi"}
+		_ = d.Publish(ctx, &report)
+	}
+
+	// Assert: no write for a usage-only change
+	if inner.publishes != 1 || inner.heartbeats != 0 {
+		t.Errorf("publishes=%d heartbeats=%d, want 1/0", inner.publishes, inner.heartbeats)
+	}
+
+	// Act / Assert: when the heartbeat is due the report with the new usage replaces it
+	now = now.Add(time.Minute)
+	_ = d.Publish(ctx, &report)
+	if inner.publishes != 2 || inner.heartbeats != 0 {
+		t.Errorf("publishes=%d heartbeats=%d, want 2/0", inner.publishes, inner.heartbeats)
+	}
+
+	// Act / Assert: usage unchanged, the next due write is a plain heartbeat
+	now = now.Add(10 * time.Minute)
+	_ = d.Publish(ctx, &report)
+	if inner.publishes != 2 || inner.heartbeats != 1 {
+		t.Errorf("publishes=%d heartbeats=%d, want 2/1", inner.publishes, inner.heartbeats)
+	}
+}
+
+func TestDedupPublisherSeedsHashFromHub(t *testing.T) {
+	// Arrange: report already on the hub from before an agent restart
+	report := testReport(2)
+	inner := &recordingPublisher{hash: reportHash(&report)}
+	d := newDedupPublisher(inner, time.Hour)
+
+	// Act
+	if err := d.Publish(context.Background(), &report); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	// Assert: heartbeat only, no rewrite
+	if inner.publishes != 0 || inner.heartbeats != 1 {
+		t.Errorf("publishes=%d heartbeats=%d, want 0/1", inner.publishes, inner.heartbeats)
+	}
+}
+
+func TestDedupPublisherRewritesDeletedReport(t *testing.T) {
+	// Arrange: the report was deleted on the hub, so the heartbeat patch fails
+	report := testReport(1)
+	inner := &recordingPublisher{
+		hash:         reportHash(&report),
+		heartbeatErr: apierrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, ConfigMapName),
+	}
+	d := newDedupPublisher(inner, time.Hour)
+
+	// Act
+	err := d.Publish(context.Background(), &report)
+
+	// Assert
+	if err != nil || inner.publishes != 1 {
+		t.Errorf("err=%v publishes=%d, want the report to be written again", err, inner.publishes)
+	}
+}
+
+func TestConfigMapPublisherHashAndHeartbeat(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	hubClient := fake.NewClientset()
+	p := &configMapPublisher{client: hubClient, namespace: "cluster1", encoding: EncodingJSON}
+	report := testReport(2)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Act
+	if err := p.Publish(ctx, &report); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if err := p.Heartbeat(ctx, now); err != nil {
+		t.Fatalf("heartbeat: %v", err)
+	}
+	report.TotalPods = 5
+	if err := p.Publish(ctx, &report); err != nil {
+		t.Fatalf("second publish: %v", err)
+	}
+
+	// Assert: the heartbeat survives the next apply of the report
+	hash, err := p.PublishedHash(ctx)
+	if err != nil || hash != reportHash(&report) {
+		t.Errorf("PublishedHash = %q, %v; want %q", hash, err, reportHash(&report))
+	}
+	cm, _ := hubClient.CoreV1().ConfigMaps("cluster1").Get(ctx, ConfigMapName, metav1.GetOptions{})
+	if cm.Annotations[HeartbeatAnnotation] != "2025-01-01T00:00:00Z" {
+		t.Errorf("heartbeat annotation = %q", cm.Annotations[HeartbeatAnnotation])
+	}
+}
+
+func TestPodReportPublisherHashMatchesStatus(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	p := &podReportPublisher{client: reportsfake.NewClientset(), namespace: "cluster1"}
+	report := testReport(2)
+	report.Pods[0].Containers = []ContainerInfo{{Name: "app", Image: "nginx", State: "running"}}
+
+	// Act
+	if err := p.Publish(ctx, &report); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	hash, err := p.PublishedHash(ctx)
+
+	// Assert: the hash recomputed from .status matches the published report
+	if err != nil || hash != reportHash(&report) {
+		t.Errorf("PublishedHash = %q, %v; want %q", hash, err, reportHash(&report))
+	}
+}
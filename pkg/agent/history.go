@@ -0,0 +1,326 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	reportsclient "github.com/totvs/addon-framework-basic/pkg/client/clientset/versioned"
+)
+
+const (
+	// HistorySize é o número padrão de relatórios anteriores mantidos no hub (0: histórico
+	// desligado, já que cada slot custa uma escrita a mais no hub por relatório).
+	HistorySize = 0
+
+	// HistoryMaxAge é a idade máxima padrão de um relatório do histórico.
+	HistoryMaxAge = time.Hour
+
+	// HistoryLabel marca os PodReports de histórico (backend crd).
+	HistoryLabel = "basic-addon.open-cluster-management.io/history"
+
+	// HistorySequenceAnnotation guarda a sequência do relatório no histórico (maior = mais novo).
+	HistorySequenceAnnotation = "basic-addon.open-cluster-management.io/history-sequence"
+
+	// ReportTimestampAnnotation guarda o timestamp (RFC3339) do relatório do histórico.
+	// Usado na expiração por idade sem decodificar o relatório.
+	ReportTimestampAnnotation = "basic-addon.open-cluster-management.io/report-timestamp"
+)
+
+// historyName é o nome do objeto do slot do histórico: pod-report-history-<slot>.
+func historyName(slot int) string {
+	return fmt.Sprintf("%s-history-%d", ConfigMapName, slot)
+}
+
+// historyEntry identifica um relatório do histórico no hub.
+type historyEntry struct {
+	name      string
+	seq       int
+	timestamp time.Time
+}
+
+// historyStore grava e lista os objetos do histórico no backend do relatório.
+type historyStore interface {
+	saveHistory(ctx context.Context, name string, seq int, report *PodReport) error
+	listHistory(ctx context.Context, size int) ([]historyEntry, error)
+	deleteHistory(ctx context.Context, name string) error
+}
+
+// historyPublisher mantém no hub um ring buffer dos últimos relatórios publicados.
+//
+// Fluxo:
+// 1. Cada relatório gravado com sucesso também é gravado no slot seq % size do histórico
+// 2. Entradas além das size mais novas ou mais velhas que maxAge são removidas
+// 3. A expiração por idade também roda no heartbeat, já que o relatório pode ficar sem mudar
+//
+// Fica abaixo do spool: relatórios reenviados após uma queda do hub entram no histórico
+// em ordem, mantendo o histórico contínuo. Falhas no histórico não falham o Publish.
+type historyPublisher struct {
+	reportPublisher
+	store  historyStore
+	size   int
+	maxAge time.Duration // 0 desliga a expiração por idade
+	now    func() time.Time
+
+	loaded  bool
+	entries []historyEntry // ordenadas por seq
+}
+
+// newHistoryPublisher envolve publisher com o histórico. O backend precisa implementar historyStore.
+func newHistoryPublisher(publisher reportPublisher, size int, maxAge time.Duration) (*historyPublisher, error) {
+	store, ok := publisher.(historyStore)
+	if !ok {
+		return nil, fmt.Errorf("backend %T não suporta histórico", publisher)
+	}
+	return &historyPublisher{reportPublisher: publisher, store: store, size: size, maxAge: maxAge, now: time.Now}, nil
+}
+
+// Publish grava o relatório e depois o guarda no histórico.
+func (h *historyPublisher) Publish(ctx context.Context, report *PodReport) error {
+	if err := h.reportPublisher.Publish(ctx, report); err != nil {
+		return err
+	}
+	if err := h.record(ctx, report); err != nil {
+		klog.Warningf("Falha ao gravar histórico do relatório: %v", err)
+	}
+	return nil
+}
+
+// Heartbeat grava o heartbeat e expira o histórico por idade.
+func (h *historyPublisher) Heartbeat(ctx context.Context, now time.Time) error {
+	if err := h.reportPublisher.Heartbeat(ctx, now); err != nil {
+		return err
+	}
+	if err := h.load(ctx); err != nil {
+		klog.Warningf("Falha ao ler histórico do relatório: %v", err)
+		return nil
+	}
+	h.expire(ctx)
+	return nil
+}
+
+// record grava o relatório no próximo slot e expira as entradas antigas.
+func (h *historyPublisher) record(ctx context.Context, report *PodReport) error {
+	if err := h.load(ctx); err != nil {
+		return err
+	}
+	seq := 0
+	if n := len(h.entries); n > 0 {
+		seq = h.entries[n-1].seq + 1
+	}
+	name := historyName(seq % h.size)
+	if err := h.store.saveHistory(ctx, name, seq, report); err != nil {
+		return err
+	}
+
+	// O slot reutilizado substitui a entrada anterior com o mesmo nome
+	for i := range h.entries {
+		if h.entries[i].name == name {
+			h.entries = append(h.entries[:i], h.entries[i+1:]...)
+			break
+		}
+	}
+	h.entries = append(h.entries, historyEntry{name: name, seq: seq, timestamp: report.Timestamp})
+	h.expire(ctx)
+	return nil
+}
+
+// expire remove as entradas fora das size mais novas ou mais velhas que maxAge.
+func (h *historyPublisher) expire(ctx context.Context) {
+	if len(h.entries) == 0 {
+		return
+	}
+	latest := h.entries[len(h.entries)-1].seq
+	cutoff := h.now().Add(-h.maxAge)
+	kept := h.entries[:0]
+	for _, entry := range h.entries {
+		expired := entry.seq <= latest-h.size || (h.maxAge > 0 && entry.timestamp.Before(cutoff))
+		if !expired {
+			kept = append(kept, entry)
+			continue
+		}
+		if err := h.store.deleteHistory(ctx, entry.name); err != nil {
+			klog.Warningf("Falha ao remover %s do histórico: %v", entry.name, err)
+			kept = append(kept, entry)
+			continue
+		}
+		klog.V(2).Infof("Relatório %s (sequência %d) removido do histórico", entry.name, entry.seq)
+	}
+	h.entries = kept
+}
+
+// load lê as entradas do histórico do hub na primeira vez (depois fica em memória).
+func (h *historyPublisher) load(ctx context.Context) error {
+	if h.loaded {
+		return nil
+	}
+	entries, err := h.store.listHistory(ctx, h.size)
+	if err != nil {
+		return err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].seq < entries[j].seq })
+	h.entries, h.loaded = entries, true
+	return nil
+}
+
+// historyAnnotations são as anotações que identificam a entrada no histórico.
+func historyAnnotations(seq int, report *PodReport) map[string]string {
+	return map[string]string{
+		HistorySequenceAnnotation: strconv.Itoa(seq),
+		ReportTimestampAnnotation: report.Timestamp.UTC().Format(time.RFC3339),
+	}
+}
+
+// parseHistoryEntry lê a entrada a partir das anotações (ok false se não é do histórico).
+func parseHistoryEntry(name string, annotations map[string]string) (historyEntry, bool) {
+	seq, err := strconv.Atoi(annotations[HistorySequenceAnnotation])
+	if err != nil {
+		return historyEntry{}, false
+	}
+	timestamp, _ := time.Parse(time.RFC3339, annotations[ReportTimestampAnnotation])
+	return historyEntry{name: name, seq: seq, timestamp: timestamp}, true
+}
+
+// saveHistory grava o relatório no ConfigMap name (com shards, se necessário).
+func (p *configMapPublisher) saveHistory(ctx context.Context, name string, seq int, report *PodReport) error {
+	data, err := encodeReport(report, p.encoding)
+	if err != nil {
+		return permanent(fmt.Errorf("falha ao codificar relatório: %w", err))
+	}
+	return publishConfigMap(ctx, p.client, p.namespace, name, data, p.encoding, historyAnnotations(seq, report), MaxObjectBytes)
+}
+
+// listHistory lê os ConfigMaps principais dos size slots do histórico. Lê cada slot pelo nome
+// em vez de listar: a Role do agent no hub só libera os objetos do relatório (ver ReportObjects).
+func (p *configMapPublisher) listHistory(ctx context.Context, size int) ([]historyEntry, error) {
+	var entries []historyEntry
+	for slot := 0; slot < size; slot++ {
+		cm, err := p.client.CoreV1().ConfigMaps(p.namespace).Get(ctx, historyName(slot), metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if entry, ok := parseHistoryEntry(cm.Name, cm.Annotations); ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// deleteHistory remove o ConfigMap do histórico e seus shards.
+func (p *configMapPublisher) deleteHistory(ctx context.Context, name string) error {
+	return deleteConfigMap(ctx, p.client, p.namespace, name)
+}
+
+// listHistoryConfigMaps lista as entradas do histórico do backend configmap.
+// O ConfigMap principal é o que tem ReportLabel igual ao próprio nome; shards apontam para ele.
+func listHistoryConfigMaps(ctx context.Context, hubClient kubernetes.Interface, namespace string) ([]historyEntry, error) {
+	list, err := hubClient.CoreV1().ConfigMaps(namespace).List(ctx, metav1.ListOptions{LabelSelector: ReportLabel})
+	if err != nil {
+		return nil, err
+	}
+	var entries []historyEntry
+	for _, cm := range list.Items {
+		if cm.Labels[ReportLabel] != cm.Name {
+			continue
+		}
+		if entry, ok := parseHistoryEntry(cm.Name, cm.Annotations); ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// saveHistory grava o relatório no PodReport name, marcado com HistoryLabel.
+func (p *podReportPublisher) saveHistory(ctx context.Context, name string, seq int, report *PodReport) error {
+	return p.applyPodReport(ctx, name, report, map[string]string{HistoryLabel: PodReportName}, historyAnnotations(seq, report))
+}
+
+// listHistory lê os PodReports dos size slots do histórico, pelo nome (ver configMapPublisher.listHistory).
+func (p *podReportPublisher) listHistory(ctx context.Context, size int) ([]historyEntry, error) {
+	var entries []historyEntry
+	for slot := 0; slot < size; slot++ {
+		obj, err := p.client.ReportsV1alpha1().PodReports(p.namespace).Get(ctx, historyName(slot), metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if entry, ok := parseHistoryEntry(obj.Name, obj.Annotations); ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// deleteHistory remove o PodReport do histórico.
+func (p *podReportPublisher) deleteHistory(ctx context.Context, name string) error {
+	err := p.client.ReportsV1alpha1().PodReports(p.namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// ListHistory lista o histórico de relatórios do cluster no backend configmap, do mais novo
+// para o mais antigo. Entradas sendo regravadas (shards que não conferem) são ignoradas.
+func ListHistory(ctx context.Context, hubClient kubernetes.Interface, clusterName string) ([]*PodReport, error) {
+	entries, err := listHistoryConfigMaps(ctx, hubClient, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].seq > entries[j].seq })
+
+	reports := make([]*PodReport, 0, len(entries))
+	for _, entry := range entries {
+		data, encoding, err := readPayload(ctx, hubClient, clusterName, entry.name)
+		if err != nil {
+			klog.Warningf("Relatório %s do histórico ignorado: %v", entry.name, err)
+			continue
+		}
+		report, err := DecodeReport(data, encoding)
+		if err != nil {
+			return nil, fmt.Errorf("relatório %s do histórico inválido: %w", entry.name, err)
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// ListPodReportHistory lista o histórico de relatórios do cluster no backend crd, do mais novo
+// para o mais antigo. Relatórios com Truncated vêm sem a lista de pods.
+func ListPodReportHistory(ctx context.Context, reportClient reportsclient.Interface, clusterName string) ([]*PodReport, error) {
+	list, err := reportClient.ReportsV1alpha1().PodReports(clusterName).List(ctx, metav1.ListOptions{LabelSelector: HistoryLabel})
+	if err != nil {
+		return nil, err
+	}
+	type item struct {
+		seq    int
+		report *PodReport
+	}
+	var items []item
+	for _, obj := range list.Items {
+		entry, ok := parseHistoryEntry(obj.Name, obj.Annotations)
+		if !ok {
+			continue
+		}
+		items = append(items, item{seq: entry.seq, report: fromPodReportStatus(obj.Status)})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].seq > items[j].seq })
+
+	reports := make([]*PodReport, len(items))
+	for i := range items {
+		reports[i] = items[i].report
+	}
+	return reports, nil
+}
@@ -0,0 +1,146 @@
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	reportsv1alpha1 "github.com/totvs/addon-framework-basic/pkg/apis/reports/v1alpha1"
+	reportsfake "github.com/totvs/addon-framework-basic/pkg/client/clientset/versioned/fake"
+)
+
+// newTestHistory builds a history over the configmap backend with a controllable clock.
+func newTestHistory(t *testing.T, size int, maxAge time.Duration, now *time.Time) (*historyPublisher, *fake.Clientset) {
+	t.Helper()
+	hubClient := fake.NewClientset()
+	h, err := newHistoryPublisher(&configMapPublisher{client: hubClient, namespace: "cluster1", encoding: EncodingJSON}, size, maxAge)
+	if err != nil {
+		t.Fatalf("newHistoryPublisher: %v", err)
+	}
+	h.now = func() time.Time { return *now }
+	return h, hubClient
+}
+
+func totals(reports []*PodReport) []int {
+	var out []int
+	for _, r := range reports {
+		out = append(out, r.TotalPods)
+	}
+	return out
+}
+
+func TestHistoryKeepsLastN(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	h, hubClient := newTestHistory(t, 3, time.Hour, &now)
+
+	// Act
+	for i := 1; i <= 5; i++ {
+		report := testReport(i)
+		report.Timestamp = now
+		if err := h.Publish(ctx, &report); err != nil {
+			t.Fatalf("publish %d: %v", i, err)
+		}
+		now = now.Add(time.Minute)
+	}
+
+	// Assert: newest first, only the last three
+	history, err := ListHistory(ctx, hubClient, "cluster1")
+	if err != nil {
+		t.Fatalf("ListHistory: %v", err)
+	}
+	if got := totals(history); len(got) != 3 || got[0] != 5 || got[2] != 3 {
+		t.Errorf("history = %v, want [5 4 3]", got)
+	}
+	latest, _ := ReadReport(ctx, hubClient, "cluster1")
+	if latest.TotalPods != 5 {
+		t.Errorf("latest TotalPods = %d, want 5", latest.TotalPods)
+	}
+}
+
+func TestHistoryExpiresByAge(t *testing.T) {
+	// Arrange: two reports ten minutes apart, max age 15 minutes
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	h, hubClient := newTestHistory(t, 10, 15*time.Minute, &now)
+	for i := 1; i <= 2; i++ {
+		report := testReport(i)
+		report.Timestamp = now
+		_ = h.Publish(ctx, &report)
+		now = now.Add(10 * time.Minute)
+	}
+
+	// Act: the report does not change, only the heartbeat runs
+	if err := h.Heartbeat(ctx, now); err != nil {
+		t.Fatalf("heartbeat: %v", err)
+	}
+
+	// Assert: the first report (20 minutes old) expired
+	history, err := ListHistory(ctx, hubClient, "cluster1")
+	if err != nil {
+		t.Fatalf("ListHistory: %v", err)
+	}
+	if got := totals(history); len(got) != 1 || got[0] != 2 {
+		t.Errorf("history = %v, want [2]", got)
+	}
+}
+
+func TestHistoryResumesSequenceAfterRestart(t *testing.T) {
+	// Arrange: history written by a previous agent
+	ctx := context.Background()
+	now := time.Now()
+	h, hubClient := newTestHistory(t, 2, 0, &now)
+	for i := 1; i <= 2; i++ {
+		report := testReport(i)
+		_ = h.Publish(ctx, &report)
+	}
+
+	// Act: a new agent over the same hub
+	restarted, err := newHistoryPublisher(&configMapPublisher{client: hubClient, namespace: "cluster1", encoding: EncodingJSON}, 2, 0)
+	if err != nil {
+		t.Fatalf("newHistoryPublisher: %v", err)
+	}
+	report := testReport(3)
+	_ = restarted.Publish(ctx, &report)
+
+	// Assert: the oldest slot was reused
+	history, _ := ListHistory(ctx, hubClient, "cluster1")
+	if got := totals(history); len(got) != 2 || got[0] != 3 || got[1] != 2 {
+		t.Errorf("history = %v, want [3 2]", got)
+	}
+}
+
+func TestListPodReportHistory(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	reportClient := reportsfake.NewClientset()
+	for seq := 0; seq < 3; seq++ {
+		obj := &reportsv1alpha1.PodReport{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        historyName(seq),
+				Namespace:   "cluster1",
+				Labels:      map[string]string{HistoryLabel: PodReportName},
+				Annotations: historyAnnotations(seq, &PodReport{}),
+			},
+			Status: reportsv1alpha1.PodReportStatus{TotalPods: int32(seq + 1)},
+		}
+		if _, err := reportClient.ReportsV1alpha1().PodReports("cluster1").Create(ctx, obj, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	// Act
+	history, err := ListPodReportHistory(ctx, reportClient, "cluster1")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("ListPodReportHistory: %v", err)
+	}
+	if got := totals(history); len(got) != 3 || got[0] != 3 || got[2] != 1 {
+		t.Errorf("history = %v, want [3 2 1]", got)
+	}
+}
@@ -0,0 +1,321 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	corelisters "k8s.io/client-go/listers/core/v1"
+)
+
+// CollectorImages é o collector do inventário de imagens (seção images-report no hub).
+const CollectorImages = "images"
+
+// Problemas das imagens (ImageInfo.Issues).
+const (
+	ImageLatestTag          = "latestTag"          // Tag latest (explícita ou implícita) sem digest
+	ImageNoDigest           = "noDigest"           // Referência no spec sem digest (@sha256:...)
+	ImageRegistryNotAllowed = "registryNotAllowed" // Registry fora de --allowed-registries
+)
+
+// dockerHub é o registry das imagens sem registry na referência (ex: nginx:1.27).
+const dockerHub = "docker.io"
+
+// ImageInventory é a seção do collector images: as imagens usadas pelos pods do spoke.
+type ImageInventory struct {
+	TotalImages       int            `json:"totalImages"`
+	AllowedRegistries []string       `json:"allowedRegistries,omitempty"` // Allow-list usada no check (vazia: check desligado)
+	Issues            map[string]int `json:"issues"`                      // problema -> imagens
+	Images            []ImageInfo    `json:"images"`                      // Ordenadas pela referência
+}
+
+// ImageInfo é uma imagem única (referência como no spec do pod) e quem a usa.
+type ImageInfo struct {
+	Image      string   `json:"image"`
+	Registry   string   `json:"registry"`
+	Repository string   `json:"repository"`
+	Tag        string   `json:"tag,omitempty"`
+	Digests    []string `json:"digests,omitempty"` // Do spec ou resolvidos pelo kubelet (Status.ImageID)
+	Issues     []string `json:"issues,omitempty"`
+	Clusters   []string `json:"clusters"`
+	Pods       []PodRef `json:"pods"`
+}
+
+// PodRef identifica um pod que usa a imagem.
+type PodRef struct {
+	Cluster   string `json:"cluster"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+func init() {
+	RegisterCollector(CollectorImages, func(o *AgentOptions) Collector {
+		return &imagesCollector{clusterName: o.SpokeClusterName, allowed: o.AllowedRegistries, options: o, filters: o.filters()}
+	})
+}
+
+// imagesCollector monta o ImageInventory a partir do cache de pods.
+type imagesCollector struct {
+	clusterName string
+	allowed     []string
+	options     *AgentOptions
+	filters     *CollectionFilters
+	lister      corelisters.PodLister
+}
+
+// Name implementa Collector.
+func (c *imagesCollector) Name() string { return CollectorImages }
+
+// Rules implementa Collector.
+func (c *imagesCollector) Rules() []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{{
+		APIGroups: []string{""},
+		Resources: []string{"pods"},
+		Verbs:     []string{"get", "list", "watch"},
+	}}
+}
+
+// Register implementa Collector.
+func (c *imagesCollector) Register(factory informers.SharedInformerFactory, changed func()) error {
+	informer, lister := podInformer(factory, c.options)
+	c.lister = lister
+	return notifyOnChange(informer, changed)
+}
+
+// Collect implementa Collector. Devolve *ImageInventory.
+func (c *imagesCollector) Collect(context.Context) (interface{}, error) {
+	pods, err := c.lister.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("falha ao listar pods: %w", err)
+	}
+	return buildImageInventory(c.clusterName, filterNamespaces(c.filters, pods), c.allowed), nil
+}
+
+// buildImageInventory lista as imagens dos containers (init, regulares e efêmeros) de todos os
+// pods, inclusive os terminados: um Job concluído também rodou a imagem.
+func buildImageInventory(clusterName string, pods []*corev1.Pod, allowed []string) *ImageInventory {
+	images := map[string]*ImageInfo{}
+	for _, p := range pods {
+		pod := PodRef{Cluster: clusterName, Namespace: p.Namespace, Name: p.Name}
+		for _, c := range podImages(p) {
+			info, ok := images[c.image]
+			if !ok {
+				info = newImageInfo(c.image, allowed)
+				info.Clusters = []string{clusterName}
+				images[c.image] = info
+			}
+			if n := len(info.Pods); n == 0 || info.Pods[n-1] != pod {
+				info.Pods = append(info.Pods, pod)
+			}
+			if c.digest != "" {
+				info.Digests = appendUnique(info.Digests, c.digest)
+			}
+		}
+	}
+	inventory := &ImageInventory{AllowedRegistries: allowed, Images: make([]ImageInfo, 0, len(images))}
+	for _, info := range images {
+		inventory.Images = append(inventory.Images, *info)
+	}
+	return finishInventory(inventory)
+}
+
+// MergeImageInventories junta os inventários de vários clusters (lidos com ReadSection)
+// em uma visão da frota: cada imagem com a união dos clusters, pods e digests.
+func MergeImageInventories(inventories ...*ImageInventory) *ImageInventory {
+	images := map[string]*ImageInfo{}
+	merged := &ImageInventory{}
+	for _, inv := range inventories {
+		if inv == nil {
+			continue
+		}
+		for _, r := range inv.AllowedRegistries {
+			merged.AllowedRegistries = appendUnique(merged.AllowedRegistries, r)
+		}
+		for _, img := range inv.Images {
+			info, ok := images[img.Image]
+			if !ok {
+				copied := img
+				copied.Digests, copied.Issues, copied.Clusters, copied.Pods = nil, nil, nil, nil
+				info = &copied
+				images[img.Image] = info
+			}
+			for _, d := range img.Digests {
+				info.Digests = appendUnique(info.Digests, d)
+			}
+			for _, issue := range img.Issues {
+				info.Issues = appendUnique(info.Issues, issue)
+			}
+			for _, c := range img.Clusters {
+				info.Clusters = appendUnique(info.Clusters, c)
+			}
+			info.Pods = append(info.Pods, img.Pods...)
+		}
+	}
+	merged.Images = make([]ImageInfo, 0, len(images))
+	for _, info := range images {
+		merged.Images = append(merged.Images, *info)
+	}
+	return finishInventory(merged)
+}
+
+// finishInventory ordena imagens, pods, digests e clusters e conta os problemas.
+func finishInventory(inv *ImageInventory) *ImageInventory {
+	sort.Strings(inv.AllowedRegistries)
+	sort.Slice(inv.Images, func(i, j int) bool { return inv.Images[i].Image < inv.Images[j].Image })
+	inv.TotalImages = len(inv.Images)
+	inv.Issues = map[string]int{}
+	for i := range inv.Images {
+		img := &inv.Images[i]
+		sort.Strings(img.Digests)
+		sort.Strings(img.Issues)
+		sort.Strings(img.Clusters)
+		sort.Slice(img.Pods, func(a, b int) bool {
+			x, y := img.Pods[a], img.Pods[b]
+			if x.Cluster != y.Cluster {
+				return x.Cluster < y.Cluster
+			}
+			if x.Namespace != y.Namespace {
+				return x.Namespace < y.Namespace
+			}
+			return x.Name < y.Name
+		})
+		for _, issue := range img.Issues {
+			inv.Issues[issue]++
+		}
+	}
+	return inv
+}
+
+// containerImage é a imagem de um container e o digest resolvido pelo kubelet.
+type containerImage struct {
+	image, digest string
+}
+
+// podImages devolve as imagens dos containers do pod com o digest do spec ou do Status.ImageID.
+func podImages(p *corev1.Pod) []containerImage {
+	imageIDs := map[string]string{}
+	statuses := [][]corev1.ContainerStatus{p.Status.InitContainerStatuses, p.Status.ContainerStatuses, p.Status.EphemeralContainerStatuses}
+	for _, list := range statuses {
+		for _, cs := range list {
+			imageIDs[cs.Name] = cs.ImageID
+		}
+	}
+	var images []containerImage
+	add := func(name, image string) {
+		if image == "" {
+			return
+		}
+		_, _, _, digest := parseImage(image)
+		if digest == "" {
+			digest = imageIDDigest(imageIDs[name])
+		}
+		images = append(images, containerImage{image: image, digest: digest})
+	}
+	for _, c := range p.Spec.InitContainers {
+		add(c.Name, c.Image)
+	}
+	for _, c := range p.Spec.Containers {
+		add(c.Name, c.Image)
+	}
+	for _, c := range p.Spec.EphemeralContainers {
+		add(c.Name, c.Image)
+	}
+	return images
+}
+
+// newImageInfo cria a ImageInfo da referência e aplica os checks de tag, digest e registry.
+func newImageInfo(image string, allowed []string) *ImageInfo {
+	registry, repository, tag, digest := parseImage(image)
+	info := &ImageInfo{Image: image, Registry: registry, Repository: repository, Tag: tag}
+	if digest == "" {
+		info.Issues = append(info.Issues, ImageNoDigest)
+		if tag == "latest" {
+			info.Issues = append(info.Issues, ImageLatestTag)
+		}
+	}
+	if len(allowed) > 0 && !registryAllowed(registry, repository, allowed) {
+		info.Issues = append(info.Issues, ImageRegistryNotAllowed)
+	}
+	return info
+}
+
+// parseImage separa a referência da imagem em registry, repositório, tag e digest, com as regras
+// do Docker: sem registry (primeiro componente sem "." ou ":" e diferente de localhost) é o
+// Docker Hub, e sem tag nem digest a tag é latest.
+func parseImage(image string) (registry, repository, tag, digest string) {
+	name := image
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, digest = name[:i], name[i+1:]
+	}
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, tag = name[:i], name[i+1:]
+	}
+	if tag == "" && digest == "" {
+		tag = "latest"
+	}
+	registry, repository = dockerHub, name
+	if i := strings.Index(name, "/"); i >= 0 {
+		first := name[:i]
+		if strings.ContainsAny(first, ".:") || first == "localhost" {
+			registry, repository = normalizeRegistry(first), name[i+1:]
+		}
+	}
+	if registry == dockerHub && !strings.Contains(repository, "/") {
+		repository = "library/" + repository
+	}
+	return registry, repository, tag, digest
+}
+
+// normalizeRegistry troca os aliases do Docker Hub por docker.io.
+func normalizeRegistry(registry string) string {
+	switch registry {
+	case "index.docker.io", "registry-1.docker.io":
+		return dockerHub
+	}
+	return registry
+}
+
+// imageIDDigest extrai o digest do Status.ImageID (ex: docker-pullable://nginx@sha256:...).
+// ImageIDs sem "@" são o ID local da imagem, não o digest do registry, e são ignorados.
+func imageIDDigest(imageID string) string {
+	if i := strings.LastIndex(imageID, "@"); i >= 0 {
+		return imageID[i+1:]
+	}
+	return ""
+}
+
+// registryAllowed diz se a imagem está na allow-list. Cada entrada é um glob (path.Match) do
+// registry (ex: *.azurecr.io) ou, com "/", um prefixo de registry/repositório (ex: ghcr.io/totvs).
+func registryAllowed(registry, repository string, allowed []string) bool {
+	full := registry + "/" + repository
+	for _, entry := range allowed {
+		entry = strings.TrimSuffix(strings.TrimSpace(entry), "/")
+		if i := strings.Index(entry, "/"); i >= 0 {
+			entry = normalizeRegistry(entry[:i]) + entry[i:]
+			if strings.HasPrefix(full, entry+"/") {
+				return true
+			}
+			continue
+		}
+		if ok, _ := path.Match(normalizeRegistry(entry), registry); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// appendUnique adiciona s a list se ainda não estiver lá.
+func appendUnique(list []string, s string) []string {
+	for _, v := range list {
+		if v == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
@@ -0,0 +1,117 @@
+package agent
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const testDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func podWithImages(namespace, name string, images ...string) *corev1.Pod {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	for i, image := range images {
+		pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: string(rune('a' + i)), Image: image})
+	}
+	return pod
+}
+
+func TestParseImage(t *testing.T) {
+	cases := []struct {
+		image                             string
+		registry, repository, tag, digest string
+	}{
+		{"nginx", "docker.io", "library/nginx", "latest", ""},
+		{"nginx:1.27", "docker.io", "library/nginx", "1.27", ""},
+		{"bitnami/redis:7", "docker.io", "bitnami/redis", "7", ""},
+		{"index.docker.io/library/nginx:1.27", "docker.io", "library/nginx", "1.27", ""},
+		{"quay.io/prometheus/node-exporter:v1.8.2", "quay.io", "prometheus/node-exporter", "v1.8.2", ""},
+		{"localhost:5000/app", "localhost:5000", "app", "latest", ""},
+		{"ghcr.io/totvs/app@" + testDigest, "ghcr.io", "totvs/app", "", testDigest},
+		{"registry.k8s.io/pause:3.10@" + testDigest, "registry.k8s.io", "pause", "3.10", testDigest},
+	}
+	for _, tc := range cases {
+		t.Run(tc.image, func(t *testing.T) {
+			// Act
+			registry, repository, tag, digest := parseImage(tc.image)
+
+			// Assert
+			if registry != tc.registry || repository != tc.repository || tag != tc.tag || digest != tc.digest {
+				t.Errorf("parseImage = %q %q %q %q, want %q %q %q %q",
+					registry, repository, tag, digest, tc.registry, tc.repository, tc.tag, tc.digest)
+			}
+		})
+	}
+}
+
+func TestBuildImageInventory(t *testing.T) {
+	// Arrange
+	web := podWithImages("default", "web", "nginx", "ghcr.io/totvs/app@"+testDigest)
+	web.Spec.InitContainers = []corev1.Container{{Name: "init", Image: "nginx"}}
+	web.Status.ContainerStatuses = []corev1.ContainerStatus{{Name: "a", ImageID: "docker.io/library/nginx@sha256:aaa"}}
+	worker := podWithImages("jobs", "worker", "quay.io/acme/worker:1.0", "nginx")
+	worker.Status.ContainerStatuses = []corev1.ContainerStatus{{Name: "a", ImageID: "sha256:local-image-id"}}
+	allowed := []string{"*.io", "ghcr.io/totvs"}
+
+	// Act
+	inv := buildImageInventory("cluster1", []*corev1.Pod{worker, web}, []string{"docker.io", "ghcr.io/totvs"})
+
+	// Assert
+	if inv.TotalImages != 3 {
+		t.Fatalf("TotalImages = %d, want 3: %+v", inv.TotalImages, inv.Images)
+	}
+	ghcr, nginx, quay := inv.Images[0], inv.Images[1], inv.Images[2]
+	if len(ghcr.Issues) != 0 || !reflect.DeepEqual(ghcr.Digests, []string{testDigest}) {
+		t.Errorf("ghcr = %+v, want pinned and allowed", ghcr)
+	}
+	if !reflect.DeepEqual(nginx.Issues, []string{ImageLatestTag, ImageNoDigest}) {
+		t.Errorf("nginx issues = %v, want latestTag and noDigest", nginx.Issues)
+	}
+	if !reflect.DeepEqual(nginx.Digests, []string{"sha256:aaa"}) {
+		t.Errorf("nginx digests = %v, want the digest resolved by the kubelet", nginx.Digests)
+	}
+	wantPods := []PodRef{{"cluster1", "default", "web"}, {"cluster1", "jobs", "worker"}}
+	if !reflect.DeepEqual(nginx.Pods, wantPods) || !reflect.DeepEqual(nginx.Clusters, []string{"cluster1"}) {
+		t.Errorf("nginx pods = %v clusters = %v, want %v", nginx.Pods, nginx.Clusters, wantPods)
+	}
+	if !reflect.DeepEqual(quay.Issues, []string{ImageNoDigest, ImageRegistryNotAllowed}) || quay.Digests != nil {
+		t.Errorf("quay = %+v, want noDigest and registryNotAllowed, no local image ID as digest", quay)
+	}
+	if inv.Issues[ImageNoDigest] != 2 || inv.Issues[ImageRegistryNotAllowed] != 1 {
+		t.Errorf("Issues = %v", inv.Issues)
+	}
+
+	// Act: globs in the allow-list
+	inv = buildImageInventory("cluster1", []*corev1.Pod{worker}, allowed)
+
+	// Assert: docker.io and quay.io match *.io
+	if inv.Issues[ImageRegistryNotAllowed] != 0 {
+		t.Errorf("Issues = %v, want every registry allowed", inv.Issues)
+	}
+}
+
+func TestMergeImageInventories(t *testing.T) {
+	// Arrange
+	a := buildImageInventory("cluster-a", []*corev1.Pod{podWithImages("default", "web", "nginx:1.27")}, nil)
+	b := buildImageInventory("cluster-b", []*corev1.Pod{podWithImages("default", "web", "nginx:1.27", "redis:7")}, nil)
+
+	// Act
+	fleet := MergeImageInventories(a, nil, b)
+
+	// Assert
+	if fleet.TotalImages != 2 || fleet.Images[0].Image != "nginx:1.27" {
+		t.Fatalf("images = %+v", fleet.Images)
+	}
+	nginx := fleet.Images[0]
+	if !reflect.DeepEqual(nginx.Clusters, []string{"cluster-a", "cluster-b"}) || len(nginx.Pods) != 2 {
+		t.Errorf("nginx = %+v, want used in both clusters", nginx)
+	}
+	if fleet.Issues[ImageNoDigest] != 2 {
+		t.Errorf("Issues = %v, want 2 noDigest", fleet.Issues)
+	}
+	if len(a.Images[0].Clusters) != 1 {
+		t.Errorf("merge changed the input: %+v", a.Images[0])
+	}
+}
@@ -0,0 +1,141 @@
+package agent
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+const (
+	// MetricsInterval é o intervalo mínimo padrão entre duas consultas ao metrics.k8s.io.
+	// O uso muda a cada scrape do metrics-server: sem o intervalo todo sync regravaria o relatório.
+	MetricsInterval = time.Minute
+
+	// MetricsRetryInterval é a espera antes de consultar de novo uma API de métricas indisponível.
+	MetricsRetryInterval = 5 * time.Minute
+)
+
+// metricsRules são as regras de RBAC no spoke para ler PodMetrics e NodeMetrics.
+func metricsRules(resources ...string) []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{{
+		APIGroups: []string{"metrics.k8s.io"},
+		Resources: resources,
+		Verbs:     []string{"get", "list"},
+	}}
+}
+
+// usageSource consulta o uso de CPU e memória no metrics.k8s.io (metrics-server) do spoke.
+//
+// O resultado fica em cache por interval. Se a API falhar (metrics-server ausente ou fora),
+// o relatório segue sem uso: a falha é logada uma vez e a consulta só é repetida depois de
+// MetricsRetryInterval. Um usageSource nil (--metrics desligado) não devolve uso.
+type usageSource struct {
+	kind     string // pods ou nodes, para os logs
+	list     func(ctx context.Context) (map[string]corev1.ResourceList, error)
+	interval time.Duration
+	now      func() time.Time
+
+	usage       map[string]corev1.ResourceList
+	fetched     time.Time
+	unavailable bool
+}
+
+// newPodUsage cria o usageSource dos PodMetrics, com chave namespace/nome.
+func newPodUsage(client metricsclientset.Interface, interval time.Duration) *usageSource {
+	return newUsageSource("pods", interval, func(ctx context.Context) (map[string]corev1.ResourceList, error) {
+		list, err := client.MetricsV1beta1().PodMetricses(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		usage := make(map[string]corev1.ResourceList, len(list.Items))
+		for _, m := range list.Items {
+			total := corev1.ResourceList{}
+			for _, c := range m.Containers {
+				addResources(total, c.Usage)
+			}
+			usage[m.Namespace+"/"+m.Name] = roundUsage(total)
+		}
+		return usage, nil
+	})
+}
+
+// newNodeUsage cria o usageSource dos NodeMetrics, com chave nome do node.
+func newNodeUsage(client metricsclientset.Interface, interval time.Duration) *usageSource {
+	return newUsageSource("nodes", interval, func(ctx context.Context) (map[string]corev1.ResourceList, error) {
+		list, err := client.MetricsV1beta1().NodeMetricses().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		usage := make(map[string]corev1.ResourceList, len(list.Items))
+		for _, m := range list.Items {
+			usage[m.Name] = roundUsage(m.Usage)
+		}
+		return usage, nil
+	})
+}
+
+func newUsageSource(kind string, interval time.Duration, list func(context.Context) (map[string]corev1.ResourceList, error)) *usageSource {
+	if interval <= 0 {
+		interval = MetricsInterval
+	}
+	return &usageSource{kind: kind, list: list, interval: interval, now: time.Now}
+}
+
+// Get devolve o uso em cache, consultando a API se o cache expirou.
+// Devolve nil com a API indisponível.
+func (s *usageSource) Get(ctx context.Context) map[string]corev1.ResourceList {
+	if s == nil {
+		return nil
+	}
+	now := s.now()
+	wait := s.interval
+	if s.unavailable {
+		wait = MetricsRetryInterval
+	}
+	if !s.fetched.IsZero() && now.Sub(s.fetched) < wait {
+		return s.usage
+	}
+	usage, err := s.list(ctx)
+	s.fetched = now
+	if err != nil {
+		if !s.unavailable {
+			klog.Warningf("Métricas de %s indisponíveis (metrics-server instalado?), relatório segue sem uso: %v", s.kind, err)
+		}
+		s.usage, s.unavailable = nil, true
+		return nil
+	}
+	if s.unavailable {
+		klog.Infof("Métricas de %s disponíveis novamente", s.kind)
+	}
+	s.usage, s.unavailable = usage, false
+	return usage
+}
+
+// roundUsage arredonda CPU para millicores e memória para MiB (para cima).
+// O metrics-server devolve nanocores e bytes: sem arredondar o relatório mudaria a cada scrape.
+func roundUsage(list corev1.ResourceList) corev1.ResourceList {
+	rounded := corev1.ResourceList{}
+	if cpu, ok := list[corev1.ResourceCPU]; ok {
+		rounded[corev1.ResourceCPU] = *resource.NewMilliQuantity(cpu.MilliValue(), resource.DecimalSI)
+	}
+	if mem, ok := list[corev1.ResourceMemory]; ok {
+		const mi = 1 << 20
+		rounded[corev1.ResourceMemory] = *resource.NewQuantity((mem.Value()+mi-1)/mi*mi, resource.BinarySI)
+	}
+	return rounded
+}
+
+// usageAmounts converte o uso em ResourceAmounts (nil sem uso).
+func usageAmounts(list corev1.ResourceList) *ResourceAmounts {
+	if list == nil {
+		return nil
+	}
+	cpu, mem := list[corev1.ResourceCPU], list[corev1.ResourceMemory]
+	return &ResourceAmounts{CPU: cpu.String(), Memory: mem.String()}
+}
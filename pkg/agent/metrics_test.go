@@ -0,0 +1,194 @@
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+)
+
+func usageList(cpu, memory string) corev1.ResourceList {
+	return corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse(cpu),
+		corev1.ResourceMemory: resource.MustParse(memory),
+	}
+}
+
+func podMetrics(namespace, name string, containers ...corev1.ResourceList) *metricsv1beta1.PodMetrics {
+	m := &metricsv1beta1.PodMetrics{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	for _, usage := range containers {
+		m.Containers = append(m.Containers, metricsv1beta1.ContainerMetrics{Name: "c", Usage: usage})
+	}
+	return m
+}
+
+// newMetricsClient cria o fake do metrics.k8s.io com objs. O fake deduz o resource pelo kind
+// (podmetrics), mas o cliente lista pods e nodes: os objetos são criados direto no tracker.
+func newMetricsClient(t *testing.T, objs ...runtime.Object) *metricsfake.Clientset {
+	t.Helper()
+	client := metricsfake.NewSimpleClientset()
+	for _, obj := range objs {
+		gvr := metricsv1beta1.SchemeGroupVersion.WithResource("pods")
+		if _, ok := obj.(*metricsv1beta1.NodeMetrics); ok {
+			gvr = metricsv1beta1.SchemeGroupVersion.WithResource("nodes")
+		}
+		if err := client.Tracker().Create(gvr, obj, obj.(metav1.Object).GetNamespace()); err != nil {
+			t.Fatalf("create %v: %v", gvr, err)
+		}
+	}
+	return client
+}
+
+// collect registra o collector em uma factory com objs, sincroniza o cache e roda Collect.
+func collect(t *testing.T, c Collector, objs ...runtime.Object) interface{} {
+	t.Helper()
+	factory := informers.NewSharedInformerFactory(fake.NewClientset(objs...), 0)
+	defer factory.Shutdown()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel() // runs before Shutdown, which waits for the informers to stop
+	if err := c.Register(factory, func() {}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	factory.Start(ctx.Done())
+	factory.WaitForCacheSync(ctx.Done())
+	out, err := c.Collect(ctx)
+	if err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	return out
+}
+
+func TestPodsCollectorMergesUsage(t *testing.T) {
+	// Arrange: usage in nanocores and bytes, as returned by metrics-server
+	metrics := newMetricsClient(t,
+		podMetrics("team-a", "web", usageList("12500000n", "100Mi"), usageList("2500000n", "1")),
+		podMetrics("team-a", "gone", usageList("1", "1Gi")),
+	)
+	o := &AgentOptions{SpokeClusterName: "cluster1", Metrics: true, metricsClient: metrics}
+	enabled, err := newCollectors(o, []string{CollectorPods})
+	if err != nil {
+		t.Fatalf("newCollectors: %v", err)
+	}
+	pods := []runtime.Object{
+		podWithResources("team-a", "web", corev1.PodRunning, resources("100m", "128Mi", "", "")),
+		podWithResources("team-a", "new", corev1.PodPending, resources("100m", "128Mi", "", "")),
+	}
+
+	// Act
+	report := collect(t, enabled[0], pods...).(*PodReport)
+
+	// Assert: cpu rounded to millicores, memory rounded up to MiB
+	if report.Pods[1].Name != "web" || report.Pods[1].Usage == nil || *report.Pods[1].Usage != (ResourceAmounts{CPU: "15m", Memory: "101Mi"}) {
+		t.Errorf("web usage = %+v, want 15m/101Mi", report.Pods[1].Usage)
+	}
+	if report.Pods[0].Usage != nil {
+		t.Errorf("new usage = %+v, want nil (not scraped yet)", report.Pods[0].Usage)
+	}
+	if report.Resources.Usage == nil || *report.Resources.Usage != (ResourceAmounts{CPU: "15m", Memory: "101Mi"}) {
+		t.Errorf("cluster usage = %+v, want 15m/101Mi (metrics of deleted pods are ignored)", report.Resources.Usage)
+	}
+	if ns := report.Resources.Namespaces[0]; ns.Usage == nil || ns.Usage.CPU != "15m" {
+		t.Errorf("namespace usage = %+v, want 15m", ns.Usage)
+	}
+	if rules := enabled[0].Rules(); rules[len(rules)-1].APIGroups[0] != "metrics.k8s.io" {
+		t.Errorf("rules = %+v, want metrics.k8s.io rule", rules)
+	}
+}
+
+func TestNodesCollectorMergesUsage(t *testing.T) {
+	// Arrange
+	metrics := newMetricsClient(t, &metricsv1beta1.NodeMetrics{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-a"},
+		Usage:      usageList("1250000000n", "3Gi"),
+	})
+	o := &AgentOptions{Metrics: true, metricsClient: metrics}
+	enabled, err := newCollectors(o, []string{CollectorNodes})
+	if err != nil {
+		t.Fatalf("newCollectors: %v", err)
+	}
+
+	// Act
+	report := collect(t, enabled[0], &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-a"}}).(*NodeReport)
+
+	// Assert
+	if usage := report.Nodes[0].Usage; usage == nil || *usage != (ResourceAmounts{CPU: "1250m", Memory: "3Gi"}) {
+		t.Errorf("node usage = %+v, want 1250m/3Gi", usage)
+	}
+}
+
+func TestUsageSourceDegradesWithoutMetricsServer(t *testing.T) {
+	// Arrange: metrics.k8s.io is not served (no metrics-server)
+	metrics := newMetricsClient(t, podMetrics("default", "web", usageList("10m", "64Mi")))
+	available := false
+	calls := 0
+	metrics.PrependReactor("list", "pods", func(clienttesting.Action) (bool, runtime.Object, error) {
+		calls++
+		if !available {
+			return true, nil, apierrors.NewNotFound(schema.GroupResource{Group: "metrics.k8s.io", Resource: "pods"}, "")
+		}
+		return false, nil, nil
+	})
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := newPodUsage(metrics, time.Minute)
+	s.now = func() time.Time { return now }
+
+	// Act & Assert: the report goes on without usage
+	if usage := s.Get(context.Background()); usage != nil {
+		t.Errorf("usage = %v, want nil", usage)
+	}
+
+	// Act & Assert: the API is not queried again before MetricsRetryInterval
+	now = now.Add(MetricsInterval)
+	s.Get(context.Background())
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+
+	// Act: metrics-server is installed
+	available = true
+	now = now.Add(MetricsRetryInterval)
+	usage := s.Get(context.Background())
+
+	// Assert
+	if calls != 2 || usage["default/web"] == nil {
+		t.Errorf("calls = %d, usage = %v, want default/web after the retry", calls, usage)
+	}
+
+	// Act & Assert: usage is cached within the interval
+	s.Get(context.Background())
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2 (cached)", calls)
+	}
+}
+
+func TestPodsCollectorWithoutMetrics(t *testing.T) {
+	// Arrange
+	enabled, err := newCollectors(&AgentOptions{}, []string{CollectorPods})
+	if err != nil {
+		t.Fatalf("newCollectors: %v", err)
+	}
+
+	// Act
+	report := collect(t, enabled[0], podWithResources("default", "web", corev1.PodRunning)).(*PodReport)
+
+	// Assert: no usage fields and no metrics.k8s.io permission
+	if report.Pods[0].Usage != nil || report.Resources.Usage != nil {
+		t.Errorf("usage = %+v / %+v, want nil", report.Pods[0].Usage, report.Resources.Usage)
+	}
+	for _, r := range enabled[0].Rules() {
+		if r.APIGroups[0] == "metrics.k8s.io" {
+			t.Errorf("unexpected metrics rule %+v", r)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	corelisters "k8s.io/client-go/listers/core/v1"
+)
+
+// CollectorNodes é o collector do inventário de nodes (seção nodes-report no hub).
+const CollectorNodes = "nodes"
+
+// nodeConditions são as condições do node incluídas no relatório.
+var nodeConditions = []corev1.NodeConditionType{
+	corev1.NodeReady,
+	corev1.NodeMemoryPressure,
+	corev1.NodeDiskPressure,
+	corev1.NodePIDPressure,
+	corev1.NodeNetworkUnavailable,
+}
+
+// NodeReport é a seção do collector nodes: o inventário de nodes do spoke.
+type NodeReport struct {
+	TotalNodes int        `json:"totalNodes"`
+	ReadyNodes int        `json:"readyNodes"`
+	Nodes      []NodeInfo `json:"nodes"`
+}
+
+// withoutUsage implementa usageSection.
+func (r *NodeReport) withoutUsage() interface{} {
+	stable := *r
+	stable.Nodes = append(r.Nodes[:0:0], r.Nodes...) // cópia; nil continua nil
+	for i := range stable.Nodes {
+		stable.Nodes[i].Usage = nil
+	}
+	return &stable
+}
+
+// NodeInfo contém capacidade, condições e identificação de um node.
+// Quantidades seguem o formato do Kubernetes (ex: cpu "4", memory "16Gi").
+type NodeInfo struct {
+	Name           string            `json:"name"`
+	Ready          bool              `json:"ready"`
+	Unschedulable  bool              `json:"unschedulable,omitempty"`
+	Capacity       NodeResources     `json:"capacity"`
+	Allocatable    NodeResources     `json:"allocatable"`
+	Conditions     map[string]string `json:"conditions"` // tipo -> status (True, False, Unknown)
+	KubeletVersion string            `json:"kubeletVersion"`
+	OSImage        string            `json:"osImage"`
+	Architecture   string            `json:"architecture"`
+	Zone           string            `json:"zone,omitempty"`   // label topology.kubernetes.io/zone
+	Region         string            `json:"region,omitempty"` // label topology.kubernetes.io/region
+	Taints         []NodeTaint       `json:"taints,omitempty"`
+	Usage          *ResourceAmounts  `json:"usage,omitempty"` // Uso medido pelo metrics-server (só com --metrics)
+}
+
+// NodeResources são os recursos do node relevantes para o inventário.
+type NodeResources struct {
+	CPU    string `json:"cpu"`
+	Memory string `json:"memory"`
+	Pods   string `json:"pods"`
+}
+
+// NodeTaint é um taint do node.
+type NodeTaint struct {
+	Key    string `json:"key"`
+	Value  string `json:"value,omitempty"`
+	Effect string `json:"effect"`
+}
+
+func init() {
+	RegisterCollector(CollectorNodes, func(o *AgentOptions) Collector {
+		c := &nodesCollector{metrics: o.Metrics}
+		if o.metricsClient != nil {
+			c.usage = newNodeUsage(o.metricsClient, o.MetricsInterval)
+		}
+		return c
+	})
+}
+
+// nodesCollector monta o NodeReport a partir do cache de nodes.
+type nodesCollector struct {
+	metrics bool
+	lister  corelisters.NodeLister
+	usage   *usageSource // nil sem --metrics
+}
+
+// Name implementa Collector.
+func (c *nodesCollector) Name() string { return CollectorNodes }
+
+// Rules implementa Collector.
+func (c *nodesCollector) Rules() []rbacv1.PolicyRule {
+	rules := []rbacv1.PolicyRule{{
+		APIGroups: []string{""},
+		Resources: []string{"nodes"},
+		Verbs:     []string{"get", "list", "watch"},
+	}}
+	if c.metrics {
+		rules = append(rules, metricsRules("nodes")...)
+	}
+	return rules
+}
+
+// Register implementa Collector.
+func (c *nodesCollector) Register(factory informers.SharedInformerFactory, changed func()) error {
+	informer := factory.Core().V1().Nodes()
+	c.lister = informer.Lister()
+	return notifyOnChange(informer.Informer(), changed)
+}
+
+// Collect implementa Collector. Devolve *NodeReport.
+// Com --metrics o uso dos nodes vem do metrics.k8s.io (única consulta fora do cache).
+func (c *nodesCollector) Collect(ctx context.Context) (interface{}, error) {
+	nodes, err := c.lister.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("falha ao listar nodes: %w", err)
+	}
+	return buildNodeReport(nodes, c.usage.Get(ctx)), nil
+}
+
+// buildNodeReport cria o NodeReport a partir da lista de nodes, ordenada por nome.
+//
+// O Status do node muda a cada heartbeat do kubelet (lastHeartbeatTime), por isso o
+// relatório só guarda o status das condições: assim a seção não é regravada sem mudanças reais.
+// usage é o uso medido de cada node (chave nome); nil sem métricas.
+func buildNodeReport(nodes []*corev1.Node, usage map[string]corev1.ResourceList) *NodeReport {
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+
+	report := &NodeReport{TotalNodes: len(nodes), Nodes: make([]NodeInfo, len(nodes))}
+	for i, n := range nodes {
+		info := NodeInfo{
+			Name:           n.Name,
+			Unschedulable:  n.Spec.Unschedulable,
+			Capacity:       nodeResources(n.Status.Capacity),
+			Allocatable:    nodeResources(n.Status.Allocatable),
+			Conditions:     map[string]string{},
+			KubeletVersion: n.Status.NodeInfo.KubeletVersion,
+			OSImage:        n.Status.NodeInfo.OSImage,
+			Architecture:   n.Status.NodeInfo.Architecture,
+			Zone:           n.Labels[corev1.LabelTopologyZone],
+			Region:         n.Labels[corev1.LabelTopologyRegion],
+			Usage:          usageAmounts(usage[n.Name]),
+		}
+		for _, cond := range n.Status.Conditions {
+			for _, t := range nodeConditions {
+				if cond.Type == t {
+					info.Conditions[string(t)] = string(cond.Status)
+				}
+			}
+		}
+		info.Ready = info.Conditions[string(corev1.NodeReady)] == string(corev1.ConditionTrue)
+		for _, t := range n.Spec.Taints {
+			info.Taints = append(info.Taints, NodeTaint{Key: t.Key, Value: t.Value, Effect: string(t.Effect)})
+		}
+		if info.Ready {
+			report.ReadyNodes++
+		}
+		report.Nodes[i] = info
+	}
+	return report
+}
+
+// nodeResources extrai cpu, memory e pods da lista de recursos do node.
+func nodeResources(list corev1.ResourceList) NodeResources {
+	return NodeResources{
+		CPU:    quantity(list, corev1.ResourceCPU),
+		Memory: quantity(list, corev1.ResourceMemory),
+		Pods:   quantity(list, corev1.ResourcePods),
+	}
+}
+
+// quantity devolve a quantidade do recurso como string (vazia se ausente).
+func quantity(list corev1.ResourceList, name corev1.ResourceName) string {
+	q, ok := list[name]
+	if !ok {
+		return ""
+	}
+	return q.String()
+}
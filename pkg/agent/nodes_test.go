@@ -0,0 +1,97 @@
+package agent
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newNode(name string, ready corev1.ConditionStatus) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				corev1.LabelTopologyZone:   "us-east-1a",
+				corev1.LabelTopologyRegion: "us-east-1",
+			},
+		},
+		Spec: corev1.NodeSpec{
+			Taints: []corev1.Taint{{Key: "dedicated", Value: "infra", Effect: corev1.TaintEffectNoSchedule}},
+		},
+		Status: corev1.NodeStatus{
+			Capacity: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("16Gi"),
+				corev1.ResourcePods:   resource.MustParse("110"),
+			},
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("3800m"),
+				corev1.ResourceMemory: resource.MustParse("15Gi"),
+				corev1.ResourcePods:   resource.MustParse("110"),
+			},
+			Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: ready, LastHeartbeatTime: metav1.Now()},
+				{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionFalse},
+				{Type: corev1.NodeDiskPressure, Status: corev1.ConditionTrue},
+			},
+			NodeInfo: corev1.NodeSystemInfo{KubeletVersion: "v1.34.2", OSImage: "Ubuntu 24.04", Architecture: "amd64"},
+		},
+	}
+}
+
+func TestBuildNodeReport(t *testing.T) {
+	// Arrange
+	nodes := []*corev1.Node{newNode("worker-2", corev1.ConditionUnknown), newNode("worker-1", corev1.ConditionTrue)}
+
+	// Act
+	report := buildNodeReport(nodes, nil)
+
+	// Assert
+	if report.TotalNodes != 2 || report.ReadyNodes != 1 {
+		t.Errorf("TotalNodes/ReadyNodes = %d/%d, want 2/1", report.TotalNodes, report.ReadyNodes)
+	}
+	node := report.Nodes[0]
+	if node.Name != "worker-1" || !node.Ready {
+		t.Errorf("Nodes[0] = %s ready=%v, want worker-1 ready", node.Name, node.Ready)
+	}
+	if node.Capacity != (NodeResources{CPU: "4", Memory: "16Gi", Pods: "110"}) {
+		t.Errorf("Capacity = %+v", node.Capacity)
+	}
+	if node.Allocatable.CPU != "3800m" || node.Allocatable.Memory != "15Gi" {
+		t.Errorf("Allocatable = %+v", node.Allocatable)
+	}
+	if node.Conditions["DiskPressure"] != "True" || node.Conditions["MemoryPressure"] != "False" {
+		t.Errorf("Conditions = %v", node.Conditions)
+	}
+	if node.KubeletVersion != "v1.34.2" || node.OSImage != "Ubuntu 24.04" || node.Architecture != "amd64" {
+		t.Errorf("node info = %s/%s/%s", node.KubeletVersion, node.OSImage, node.Architecture)
+	}
+	if node.Zone != "us-east-1a" || node.Region != "us-east-1" {
+		t.Errorf("Zone/Region = %s/%s", node.Zone, node.Region)
+	}
+	if len(node.Taints) != 1 || node.Taints[0] != (NodeTaint{Key: "dedicated", Value: "infra", Effect: "NoSchedule"}) {
+		t.Errorf("Taints = %+v", node.Taints)
+	}
+	if report.Nodes[1].Ready {
+		t.Errorf("worker-2 with Ready=Unknown reported as ready")
+	}
+}
+
+func TestBuildNodeReportIgnoresHeartbeats(t *testing.T) {
+	// Arrange: same node, only the kubelet heartbeat changed
+	before := newNode("worker-1", corev1.ConditionTrue)
+	after := before.DeepCopy()
+	after.Status.Conditions[0].LastHeartbeatTime = metav1.NewTime(before.Status.Conditions[0].LastHeartbeatTime.Add(40 * time.Second))
+
+	// Act
+	a, _ := encodeReport(buildNodeReport([]*corev1.Node{before}, nil), EncodingJSON)
+	b, _ := encodeReport(buildNodeReport([]*corev1.Node{after}, nil), EncodingJSON)
+
+	// Assert: the section is not rewritten on every kubelet heartbeat
+	if string(a) != string(b) {
+		t.Errorf("report changed on heartbeat:\n%s\n%s", a, b)
+	}
+}
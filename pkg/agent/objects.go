@@ -0,0 +1,41 @@
+package agent
+
+// ReportObjects devolve os nomes dos objetos que o agent grava no namespace do cluster no hub,
+// para o backend, os collectors e o tamanho do histórico do agent: o relatório e o histórico
+// (PodReports no backend crd, ConfigMaps com shards no configmap) e as seções dos collectors
+// além de pods (sempre ConfigMaps com shards).
+//
+// São os resourceNames da Role do agent no hub (ver hub.AddonRBAC). Por isso o agent só acessa
+// esses objetos pelo nome: lê o histórico slot a slot, cria com server-side apply (autorizado
+// como create com o nome) e limita os shards a MaxShards.
+func ReportObjects(backend string, collectors []string, historySize int) (podReports, configMaps []string) {
+	reports := []string{ConfigMapName}
+	for slot := 0; slot < historySize; slot++ {
+		reports = append(reports, historyName(slot))
+	}
+	if backend == BackendConfigMap {
+		for _, name := range reports {
+			configMaps = append(configMaps, withShards(name)...)
+		}
+	} else {
+		podReports = reports
+	}
+	seen := map[string]bool{CollectorPods: true}
+	for _, c := range collectors {
+		if c == "" || seen[c] {
+			continue
+		}
+		seen[c] = true
+		configMaps = append(configMaps, withShards(SectionName(c))...)
+	}
+	return podReports, configMaps
+}
+
+// withShards devolve name e os nomes dos seus MaxShards shards.
+func withShards(name string) []string {
+	names := []string{name}
+	for i := 0; i < MaxShards; i++ {
+		names = append(names, shardName(name, i))
+	}
+	return names
+}
@@ -0,0 +1,56 @@
+package agent
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestReportObjects(t *testing.T) {
+	tests := []struct {
+		name           string
+		backend        string
+		collectors     []string
+		historySize    int
+		wantPodReports int
+		wantConfigMaps int
+	}{
+		{name: "crd without sections", backend: BackendCRD, collectors: []string{CollectorPods}, historySize: 3, wantPodReports: 4},
+		{name: "crd with sections", backend: BackendCRD, collectors: []string{CollectorPods, "nodes", "nodes"}, historySize: 3,
+			wantPodReports: 4, wantConfigMaps: MaxShards + 1},
+		{name: "configmap", backend: BackendConfigMap, collectors: []string{CollectorPods, "events"}, historySize: 1,
+			wantConfigMaps: 3 * (MaxShards + 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			podReports, configMaps := ReportObjects(tt.backend, tt.collectors, tt.historySize)
+
+			// Assert
+			if len(podReports) != tt.wantPodReports || len(configMaps) != tt.wantConfigMaps {
+				t.Errorf("len = %d/%d, want %d/%d", len(podReports), len(configMaps), tt.wantPodReports, tt.wantConfigMaps)
+			}
+		})
+	}
+}
+
+func TestReportObjectsCoverPublishedNames(t *testing.T) {
+	// Arrange: names the configmap publisher writes for the last history slot and shard
+	want := []string{ConfigMapName, historyName(1), shardName(historyName(1), MaxShards-1), SectionName("events")}
+
+	// Act
+	_, configMaps := ReportObjects(BackendConfigMap, []string{"events"}, 2)
+
+	// Assert
+	granted := map[string]bool{}
+	for _, name := range configMaps {
+		granted[name] = true
+	}
+	for _, name := range want {
+		if !granted[name] {
+			t.Errorf("%s not in %v", name, configMaps)
+		}
+	}
+	if granted[historyName(2)] || granted[fmt.Sprintf("%s-%d", ConfigMapName, MaxShards)] {
+		t.Errorf("names beyond the history size or MaxShards granted: %v", configMaps)
+	}
+}
@@ -0,0 +1,190 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// PodPageSize é o tamanho padrão da página na listagem de pods (Limit).
+	PodPageSize = 500
+
+	// maxListRestarts limita quantas vezes a listagem recomeça do início por token expirado.
+	maxListRestarts = 3
+)
+
+// podInformer registra na factory o informer de pods compartilhado pelos collectors.
+//
+// Todos os collectors usam este informer: a factory guarda um informer por tipo, e o primeiro
+// registrado valeria para todos. Ele aplica os seletores de --pod-label-selector e
+// --pod-field-selector, lista em páginas de --pod-page-size (ver listPods) e guarda no cache só
+// os campos usados pelos collectors (ver trimPod). o nil usa os padrões, sem filtros.
+func podInformer(factory informers.SharedInformerFactory, o *AgentOptions) (cache.SharedIndexInformer, corelisters.PodLister) {
+	var filters *CollectionFilters
+	pageSize := int64(PodPageSize)
+	if o != nil {
+		filters, pageSize = o.filters(), o.PodPageSize
+	}
+	informer := factory.InformerFor(&corev1.Pod{}, func(client kubernetes.Interface, resync time.Duration) cache.SharedIndexInformer {
+		return newPodInformer(client, resync, filters, pageSize)
+	})
+	return informer, corelisters.NewPodLister(informer.GetIndexer())
+}
+
+// newPodInformer cria o informer de pods com listagem paginada e cache reduzido.
+func newPodInformer(client kubernetes.Interface, resync time.Duration, filters *CollectionFilters, pageSize int64) cache.SharedIndexInformer {
+	selectors := func(opts *metav1.ListOptions) {
+		if filters != nil {
+			opts.LabelSelector, opts.FieldSelector = filters.PodLabelSelector, filters.PodFieldSelector
+		}
+	}
+	lw := &cache.ListWatch{
+		// listPods sobrescreve Limit e Continue e pagina sozinho; a lista volta sem Continue,
+		// então o pager do reflector faz uma única chamada e só recebe os pods reduzidos.
+		ListWithContextFunc: func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+			selectors(&opts)
+			return listPods(ctx, client, opts, pageSize)
+		},
+		WatchFuncWithContext: func(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+			selectors(&opts)
+			return client.CoreV1().Pods(metav1.NamespaceAll).Watch(ctx, opts)
+		},
+	}
+	informer := cache.NewSharedIndexInformer(lw, &corev1.Pod{}, resync,
+		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	// Os eventos do watch também entram reduzidos no cache
+	if err := informer.SetTransform(func(obj interface{}) (interface{}, error) {
+		if p, ok := obj.(*corev1.Pod); ok {
+			return trimPod(p), nil
+		}
+		return obj, nil
+	}); err != nil {
+		klog.Warningf("Falha ao registrar transform do informer de pods: %v", err)
+	}
+	return informer
+}
+
+// listPods lista os pods em páginas de pageSize (Limit/Continue) e devolve a lista já reduzida
+// por trimPod: só uma página com os pods completos fica em memória por vez. pageSize <= 0 lista
+// tudo em uma chamada.
+//
+// As páginas são lidas do etcd (ResourceVersion vazio): com resourceVersion=0 o apiserver
+// responde do watch cache e ignora Limit. Se o token de continue expirar (410, compactação do
+// etcd), a listagem recomeça do início (até maxListRestarts vezes). O token que o apiserver
+// devolve no 410 não é usado: as páginas restantes viriam de um snapshot mais novo e nenhum RV
+// serviria de início para o watch (o da primeira página foi compactado; o da última perderia os
+// eventos dos pods já lidos).
+func listPods(ctx context.Context, client kubernetes.Interface, opts metav1.ListOptions, pageSize int64) (*corev1.PodList, error) {
+	opts.ResourceVersion, opts.ResourceVersionMatch = "", ""
+	opts.Limit, opts.Continue = pageSize, ""
+	if pageSize < 0 {
+		opts.Limit = 0
+	}
+	list := &corev1.PodList{}
+	restarts := 0
+	for {
+		page, err := client.CoreV1().Pods(metav1.NamespaceAll).List(ctx, opts)
+		if err != nil && opts.Continue != "" && (apierrors.IsResourceExpired(err) || apierrors.IsGone(err)) {
+			if restarts++; restarts > maxListRestarts {
+				return nil, fmt.Errorf("listagem de pods recomeçou %d vezes por token expirado: %w", maxListRestarts, err)
+			}
+			klog.Warningf("Token de continue expirado na listagem de pods, recomeçando (%d/%d)", restarts, maxListRestarts)
+			list.Items, list.ResourceVersion, opts.Continue = list.Items[:0], "", ""
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		// O RV da primeira página é o ponto de partida do watch: eventos posteriores são reaplicados
+		if list.ResourceVersion == "" {
+			list.ResourceVersion = page.ResourceVersion
+		}
+		for i := range page.Items {
+			list.Items = append(list.Items, *trimPod(&page.Items[i]))
+		}
+		if page.Continue == "" {
+			klog.V(2).Infof("Pods listados: %d", len(list.Items))
+			return list, nil
+		}
+		opts.Continue = page.Continue
+	}
+}
+
+// trimPod devolve uma cópia do pod só com os campos lidos pelos collectors. managedFields,
+// anotações, env, comandos, probes, volumeMounts e condições ficam de fora: em clusters grandes
+// eles são a maior parte da memória do cache.
+func trimPod(p *corev1.Pod) *corev1.Pod {
+	out := &corev1.Pod{
+		TypeMeta: p.TypeMeta,
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              p.Name,
+			Namespace:         p.Namespace,
+			UID:               p.UID,
+			ResourceVersion:   p.ResourceVersion,
+			CreationTimestamp: p.CreationTimestamp,
+			DeletionTimestamp: p.DeletionTimestamp,
+			Labels:            p.Labels,
+			OwnerReferences:   p.OwnerReferences,
+		},
+		Spec: corev1.PodSpec{
+			NodeName:                     p.Spec.NodeName,
+			ServiceAccountName:           p.Spec.ServiceAccountName,
+			AutomountServiceAccountToken: p.Spec.AutomountServiceAccountToken,
+			HostNetwork:                  p.Spec.HostNetwork,
+			HostPID:                      p.Spec.HostPID,
+			HostIPC:                      p.Spec.HostIPC,
+			SecurityContext:              p.Spec.SecurityContext,
+			Overhead:                     p.Spec.Overhead,
+			InitContainers:               trimContainers(p.Spec.InitContainers),
+			Containers:                   trimContainers(p.Spec.Containers),
+		},
+		Status: corev1.PodStatus{
+			Phase:                      p.Status.Phase,
+			QOSClass:                   p.Status.QOSClass,
+			InitContainerStatuses:      p.Status.InitContainerStatuses,
+			ContainerStatuses:          p.Status.ContainerStatuses,
+			EphemeralContainerStatuses: p.Status.EphemeralContainerStatuses,
+		},
+	}
+	for _, v := range p.Spec.Volumes {
+		if v.HostPath != nil {
+			out.Spec.Volumes = append(out.Spec.Volumes, corev1.Volume{Name: v.Name, VolumeSource: corev1.VolumeSource{HostPath: v.HostPath}})
+		}
+	}
+	for _, c := range p.Spec.EphemeralContainers {
+		out.Spec.EphemeralContainers = append(out.Spec.EphemeralContainers, corev1.EphemeralContainer{
+			EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: c.Name, Image: c.Image, SecurityContext: c.SecurityContext},
+		})
+	}
+	return out
+}
+
+// trimContainers mantém nome, imagem, recursos, securityContext e restartPolicy (sidecars).
+func trimContainers(containers []corev1.Container) []corev1.Container {
+	if containers == nil {
+		return nil
+	}
+	out := make([]corev1.Container, len(containers))
+	for i, c := range containers {
+		out[i] = corev1.Container{
+			Name:            c.Name,
+			Image:           c.Image,
+			Resources:       c.Resources,
+			SecurityContext: c.SecurityContext,
+			RestartPolicy:   c.RestartPolicy,
+		}
+	}
+	return out
+}
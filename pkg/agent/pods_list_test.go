@@ -0,0 +1,191 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// pagedPods serve a listagem de pods do fake clientset com Limit/Continue, como o apiserver.
+// O token de continue é o índice do próximo pod. expire devolve, para a chamada n (a partir de 1),
+// se o token expira e qual token o 410 traz ("" para nenhum).
+type pagedPods struct {
+	pods   []corev1.Pod
+	calls  []metav1.ListOptions
+	expire func(call int) (expired bool, token string)
+}
+
+func newPagedPods(total int) *pagedPods {
+	s := &pagedPods{pods: make([]corev1.Pod, total)}
+	for i := range s.pods {
+		p := podWithResources(fmt.Sprintf("ns-%d", i%50), fmt.Sprintf("pod-%05d", i), corev1.PodRunning, resources("10m", "16Mi", "", ""))
+		p.Labels = map[string]string{"app": "web"}
+		p.Annotations = map[string]string{"kubectl.kubernetes.io/last-applied-configuration": "{}"}
+		p.ManagedFields = []metav1.ManagedFieldsEntry{{Manager: "kubectl"}}
+		p.Spec.Containers[0].Env = []corev1.EnvVar{{Name: "A", Value: "b"}}
+		s.pods[i] = *p
+	}
+	return s
+}
+
+func (s *pagedPods) client() *fake.Clientset {
+	client := fake.NewClientset()
+	client.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		opts := action.(k8stesting.ListActionImpl).GetListOptions()
+		s.calls = append(s.calls, opts)
+		if s.expire != nil && opts.Continue != "" {
+			if expired, token := s.expire(len(s.calls)); expired {
+				err := apierrors.NewResourceExpired("continue token expirado")
+				err.ErrStatus.ListMeta.Continue = token
+				return true, nil, err
+			}
+		}
+		start, _ := strconv.Atoi(opts.Continue)
+		end := len(s.pods)
+		if opts.Limit > 0 && start+int(opts.Limit) < end {
+			end = start + int(opts.Limit)
+		}
+		list := &corev1.PodList{ListMeta: metav1.ListMeta{ResourceVersion: strconv.Itoa(100 + len(s.calls))}}
+		list.Items = append(list.Items, s.pods[start:end]...)
+		if end < len(s.pods) {
+			list.Continue = strconv.Itoa(end)
+		}
+		return true, list, nil
+	})
+	return client
+}
+
+func TestListPodsPaginates(t *testing.T) {
+	// Arrange
+	server := newPagedPods(20000)
+
+	// Act
+	list, err := listPods(context.Background(), server.client(), metav1.ListOptions{ResourceVersion: "0"}, 500)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("listPods: %v", err)
+	}
+	if len(server.calls) != 40 {
+		t.Errorf("calls = %d, want 40 pages of 500", len(server.calls))
+	}
+	for i, opts := range server.calls {
+		if opts.Limit != 500 || opts.ResourceVersion != "" {
+			t.Fatalf("call %d: limit = %d resourceVersion = %q, want 500 and empty", i, opts.Limit, opts.ResourceVersion)
+		}
+	}
+	if len(list.Items) != 20000 || list.ResourceVersion != "101" {
+		t.Fatalf("items = %d resourceVersion = %q, want 20000 and the first page's", len(list.Items), list.ResourceVersion)
+	}
+	seen := map[string]bool{}
+	for _, p := range list.Items {
+		seen[p.Namespace+"/"+p.Name] = true
+	}
+	if len(seen) != 20000 {
+		t.Errorf("unique pods = %d, want 20000", len(seen))
+	}
+	p := list.Items[0]
+	if p.Annotations != nil || p.ManagedFields != nil || p.Spec.Containers[0].Env != nil {
+		t.Errorf("pod not trimmed: %+v", p.ObjectMeta)
+	}
+	if p.Spec.Containers[0].Resources.Requests.Cpu().String() != "10m" || p.Status.Phase != corev1.PodRunning {
+		t.Errorf("trimmed pod lost fields used by the collectors: %+v", p)
+	}
+}
+
+func TestListPodsExpiredContinue(t *testing.T) {
+	for _, token := range []string{"", "1000"} {
+		t.Run(fmt.Sprintf("410 with token %q restarts", token), func(t *testing.T) {
+			// Arrange: the third call gets a 410; a token in it points to a newer snapshot
+			server := newPagedPods(2000)
+			server.expire = func(call int) (bool, string) { return call == 3, token }
+
+			// Act
+			list, err := listPods(context.Background(), server.client(), metav1.ListOptions{}, 500)
+
+			// Assert: 2 pages, the 410, then 4 pages from scratch without duplicates
+			if err != nil || len(list.Items) != 2000 {
+				t.Fatalf("items = %v err = %v, want 2000", list, err)
+			}
+			if len(server.calls) != 7 || server.calls[3].Continue != "" {
+				t.Errorf("calls = %+v, want a restart after the 410", server.calls)
+			}
+			// The watch starts from the restarted list, not from the compacted first snapshot
+			if list.ResourceVersion != "104" {
+				t.Errorf("resourceVersion = %q, want 104 (first page after the restart)", list.ResourceVersion)
+			}
+		})
+	}
+
+	t.Run("gives up after maxListRestarts", func(t *testing.T) {
+		// Arrange
+		server := newPagedPods(2000)
+		server.expire = func(int) (bool, string) { return true, "" }
+
+		// Act
+		_, err := listPods(context.Background(), server.client(), metav1.ListOptions{}, 500)
+
+		// Assert
+		if !apierrors.IsResourceExpired(err) {
+			t.Fatalf("err = %v, want the expired error", err)
+		}
+		if len(server.calls) != 2*(maxListRestarts+1) {
+			t.Errorf("calls = %d, want %d", len(server.calls), 2*(maxListRestarts+1))
+		}
+	})
+}
+
+func TestPodsCollectorPaginates(t *testing.T) {
+	// Arrange
+	server := newPagedPods(20000)
+	o := &AgentOptions{SpokeClusterName: "cluster1", PodPageSize: 1000, PodLabelSelector: "app=web"}
+	enabled, err := newCollectors(o, []string{CollectorPods, CollectorImages})
+	if err != nil {
+		t.Fatalf("newCollectors: %v", err)
+	}
+	factory := informers.NewSharedInformerFactory(server.client(), 0)
+	defer factory.Shutdown()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	for _, c := range enabled {
+		if err := c.Register(factory, func() {}); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+
+	// Act
+	factory.Start(ctx.Done())
+	factory.WaitForCacheSync(ctx.Done())
+	out, err := enabled[0].Collect(ctx)
+
+	// Assert: one paginated list shared by both collectors, with the selector on every page
+	if err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	if report := out.(*PodReport); report.TotalPods != 20000 || len(report.Pods) != 20000 {
+		t.Errorf("TotalPods = %d pods = %d, want 20000", report.TotalPods, len(report.Pods))
+	}
+	if len(server.calls) != 20 {
+		t.Errorf("list calls = %d, want 20 pages of 1000", len(server.calls))
+	}
+	for i, opts := range server.calls {
+		if opts.Limit != 1000 || opts.LabelSelector != "app=web" {
+			t.Fatalf("call %d: limit = %d selector = %q", i, opts.Limit, opts.LabelSelector)
+		}
+	}
+	pods, _ := factory.Core().V1().Pods().Lister().List(labels.Everything())
+	if len(pods) != 20000 || pods[0].ManagedFields != nil {
+		t.Errorf("cache = %d pods, want 20000 trimmed pods", len(pods))
+	}
+}
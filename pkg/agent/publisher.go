@@ -0,0 +1,298 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	reportsv1alpha1 "github.com/totvs/addon-framework-basic/pkg/apis/reports/v1alpha1"
+	"github.com/totvs/addon-framework-basic/pkg/apply"
+	reportsapply "github.com/totvs/addon-framework-basic/pkg/client/applyconfiguration/reports/v1alpha1"
+	reportsclient "github.com/totvs/addon-framework-basic/pkg/client/clientset/versioned"
+)
+
+const (
+	// BackendCRD grava o relatório no recurso PodReport (reports.basic-addon.open-cluster-management.io).
+	BackendCRD = "crd"
+
+	// BackendConfigMap grava o relatório no ConfigMap pod-report (formato original).
+	// Mantido durante a migração para o CRD; suporta shards e gzip.
+	BackendConfigMap = "configmap"
+
+	// PodReportName é o nome do PodReport no namespace do cluster no hub.
+	PodReportName = "pod-report"
+)
+
+// reportPublisher grava o relatório no hub.
+// Cada backend (--report-backend) tem sua implementação.
+type reportPublisher interface {
+	// Publish grava o relatório, com o hash do conteúdo em ContentHashAnnotation.
+	Publish(ctx context.Context, report *PodReport) error
+	// PublishedHash devolve o hash do relatório gravado no hub (vazio se não existe).
+	PublishedHash(ctx context.Context) (string, error)
+	// Heartbeat grava só a anotação HeartbeatAnnotation (relatório sem mudanças).
+	Heartbeat(ctx context.Context, now time.Time) error
+}
+
+// newPublisher cria o publisher do backend configurado em --report-backend.
+func (o *AgentOptions) newPublisher(hubClient kubernetes.Interface, reportClient reportsclient.Interface) (reportPublisher, error) {
+	switch o.ReportBackend {
+	case "", BackendCRD:
+		return &podReportPublisher{client: reportClient, namespace: o.SpokeClusterName}, nil
+	case BackendConfigMap:
+		encoding := o.ReportEncoding
+		if encoding == "" {
+			encoding = EncodingJSON
+		}
+		if !validEncoding(encoding) {
+			return nil, fmt.Errorf("--%s inválido: %q (use %s ou %s)", FlagReportEncoding, encoding, EncodingJSON, EncodingGzip)
+		}
+		return &configMapPublisher{client: hubClient, namespace: o.SpokeClusterName, encoding: encoding}, nil
+	default:
+		return nil, fmt.Errorf("--%s inválido: %q (use %s ou %s)", FlagReportBackend, o.ReportBackend, BackendCRD, BackendConfigMap)
+	}
+}
+
+// configMapPublisher grava o relatório no ConfigMap pod-report do namespace do cluster.
+// Relatórios grandes são divididos em shards (ver publishConfigMap).
+type configMapPublisher struct {
+	client    kubernetes.Interface
+	namespace string
+	encoding  string
+}
+
+// Publish codifica o relatório (json ou gzip) e grava o ConfigMap.
+func (p *configMapPublisher) Publish(ctx context.Context, report *PodReport) error {
+	data, err := encodeReport(report, p.encoding)
+	if err != nil {
+		return permanent(fmt.Errorf("falha ao codificar relatório: %w", err))
+	}
+	return publishConfigMap(ctx, p.client, p.namespace, ConfigMapName, data, p.encoding,
+		map[string]string{ContentHashAnnotation: reportHash(report)}, MaxObjectBytes)
+}
+
+// PublishedHash lê ContentHashAnnotation do ConfigMap principal.
+// O principal é gravado depois dos shards, então o hash só aparece com o relatório completo.
+func (p *configMapPublisher) PublishedHash(ctx context.Context) (string, error) {
+	cm, err := p.client.CoreV1().ConfigMaps(p.namespace).Get(ctx, ConfigMapName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return cm.Annotations[ContentHashAnnotation], nil
+}
+
+// Heartbeat grava a anotação de heartbeat no ConfigMap principal.
+func (p *configMapPublisher) Heartbeat(ctx context.Context, now time.Time) error {
+	_, err := p.client.CoreV1().ConfigMaps(p.namespace).Patch(ctx, ConfigMapName, types.MergePatchType,
+		heartbeatPatch(now), metav1.PatchOptions{FieldManager: HeartbeatFieldManager})
+	return err
+}
+
+// podReportPublisher grava o relatório no PodReport do namespace do cluster.
+type podReportPublisher struct {
+	client    reportsclient.Interface
+	namespace string
+}
+
+// Publish grava o PodReport pod-report com server-side apply (ver applyPodReport).
+func (p *podReportPublisher) Publish(ctx context.Context, report *PodReport) error {
+	return p.applyPodReport(ctx, PodReportName, report, nil,
+		map[string]string{ContentHashAnnotation: reportHash(report)})
+}
+
+// applyPodReport grava o PodReport name com server-side apply (field manager FieldManager).
+// O status é um subresource, por isso são dois applies: spec (com labels e annotations)
+// e depois status. Se o status falhar, as anotações ficam à frente do status; por isso
+// PublishedHash recalcula o hash a partir do status em vez de confiar na anotação.
+func (p *podReportPublisher) applyPodReport(ctx context.Context, name string, report *PodReport, labels, annotations map[string]string) error {
+	client := p.client.ReportsV1alpha1().PodReports(p.namespace)
+	target := apply.Object{
+		Kind:         "PodReport",
+		Namespace:    p.namespace,
+		Name:         name,
+		FieldManager: FieldManager,
+		Get: func(ctx context.Context) (runtime.Object, error) {
+			return client.Get(ctx, name, metav1.GetOptions{})
+		},
+		Patch: func(ctx context.Context, patch []byte) error {
+			_, err := client.Patch(ctx, name, types.JSONPatchType, patch, metav1.PatchOptions{})
+			return err
+		},
+	}
+
+	spec := reportsapply.PodReport(name, p.namespace).
+		WithAnnotations(annotations).
+		WithSpec(reportsapply.PodReportSpec().WithClusterName(report.ClusterName))
+	if len(labels) > 0 {
+		spec.WithLabels(labels)
+	}
+	err := apply.Do(ctx, target, func(ctx context.Context) error {
+		_, err := client.Apply(ctx, spec, metav1.ApplyOptions{FieldManager: FieldManager})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	status, err := podReportStatusApply(toPodReportStatus(report))
+	if err != nil {
+		return permanent(fmt.Errorf("falha ao codificar relatório: %w", err))
+	}
+	target.Subresource = "status"
+	target.Patch = func(ctx context.Context, patch []byte) error {
+		_, err := client.Patch(ctx, name, types.JSONPatchType, patch, metav1.PatchOptions{}, "status")
+		return err
+	}
+	return apply.Do(ctx, target, func(ctx context.Context) error {
+		ac := reportsapply.PodReport(name, p.namespace).WithStatus(status)
+		_, err := client.ApplyStatus(ctx, ac, metav1.ApplyOptions{FieldManager: FieldManager})
+		return err
+	})
+}
+
+// PublishedHash calcula o hash do relatório a partir do status gravado no hub.
+// Com Truncated o hash não confere e o relatório é regravado (uma vez por restart do agent).
+func (p *podReportPublisher) PublishedHash(ctx context.Context) (string, error) {
+	obj, err := p.client.ReportsV1alpha1().PodReports(p.namespace).Get(ctx, PodReportName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if obj.Status.Truncated {
+		return "", nil
+	}
+	return reportHash(fromPodReportStatus(obj.Status)), nil
+}
+
+// Heartbeat grava a anotação de heartbeat no PodReport.
+func (p *podReportPublisher) Heartbeat(ctx context.Context, now time.Time) error {
+	_, err := p.client.ReportsV1alpha1().PodReports(p.namespace).Patch(ctx, PodReportName, types.MergePatchType,
+		heartbeatPatch(now), metav1.PatchOptions{FieldManager: HeartbeatFieldManager})
+	return err
+}
+
+// podReportStatusApply converte o status no applyconfiguration gerado.
+// Os dois tipos têm os mesmos campos JSON; a conversão via JSON evita copiar campo a campo.
+func podReportStatusApply(status reportsv1alpha1.PodReportStatus) (*reportsapply.PodReportStatusApplyConfiguration, error) {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return nil, err
+	}
+	ac := &reportsapply.PodReportStatusApplyConfiguration{}
+	if err := json.Unmarshal(data, ac); err != nil {
+		return nil, err
+	}
+	return ac, nil
+}
+
+// toPodReportStatus converte o PodReport do agent no status do recurso.
+// Se a lista de pods passar de MaxObjectBytes, ela é omitida e Truncated fica true:
+// o recurso não suporta shards como o ConfigMap.
+func toPodReportStatus(report *PodReport) reportsv1alpha1.PodReportStatus {
+	status := reportsv1alpha1.PodReportStatus{
+		ClusterName:  report.ClusterName,
+		TotalPods:    int32(report.TotalPods),
+		LastSyncTime: metav1.NewTime(report.Timestamp),
+		Pods:         make([]reportsv1alpha1.PodInfo, len(report.Pods)),
+	}
+	for i, p := range report.Pods {
+		status.Pods[i] = reportsv1alpha1.PodInfo{
+			Name:      p.Name,
+			Namespace: p.Namespace,
+			Status:    p.Status,
+			Restarts:  p.Restarts,
+		}
+		if p.Workload != nil {
+			status.Pods[i].Workload = &reportsv1alpha1.WorkloadRef{Kind: p.Workload.Kind, Name: p.Workload.Name}
+		}
+		if p.Usage != nil {
+			status.Pods[i].Usage = &reportsv1alpha1.ResourceAmounts{CPU: p.Usage.CPU, Memory: p.Usage.Memory}
+		}
+		for _, c := range p.Containers {
+			status.Pods[i].Containers = append(status.Pods[i].Containers, reportsv1alpha1.ContainerInfo(c))
+		}
+	}
+
+	if report.Resources != nil {
+		status.Resources = &reportsv1alpha1.ResourceSummary{}
+		if err := convertJSON(report.Resources, status.Resources); err != nil {
+			klog.Warningf("Falha ao converter resumo de recursos: %v", err)
+			status.Resources = nil
+		}
+	}
+
+	if report.Filters != nil {
+		f := reportsv1alpha1.CollectionFilters(*report.Filters)
+		status.Filters = &f
+	}
+
+	if data, err := json.Marshal(status.Pods); err == nil && len(data) > MaxObjectBytes {
+		klog.Warningf("Lista de pods (%d bytes) excede o limite do PodReport; publicando só o resumo", len(data))
+		status.Pods = nil
+		status.Truncated = true
+	}
+	return status
+}
+
+// fromPodReportStatus converte o status do recurso de volta no PodReport do agent.
+func fromPodReportStatus(status reportsv1alpha1.PodReportStatus) *PodReport {
+	report := &PodReport{
+		APIVersion:  SchemaVersion,
+		ClusterName: status.ClusterName,
+		Timestamp:   status.LastSyncTime.Time,
+		TotalPods:   int(status.TotalPods),
+		Pods:        make([]PodInfo, len(status.Pods)),
+	}
+	for i, p := range status.Pods {
+		report.Pods[i] = PodInfo{
+			Name:      p.Name,
+			Namespace: p.Namespace,
+			Status:    p.Status,
+			Restarts:  p.Restarts,
+		}
+		if p.Workload != nil {
+			report.Pods[i].Workload = &WorkloadRef{Kind: p.Workload.Kind, Name: p.Workload.Name}
+		}
+		if p.Usage != nil {
+			report.Pods[i].Usage = &ResourceAmounts{CPU: p.Usage.CPU, Memory: p.Usage.Memory}
+		}
+		for _, c := range p.Containers {
+			report.Pods[i].Containers = append(report.Pods[i].Containers, ContainerInfo(c))
+		}
+	}
+	report.Phases = countPhases(report.Pods)
+	if status.Resources != nil {
+		report.Resources = &ResourceSummary{}
+		if err := convertJSON(status.Resources, report.Resources); err != nil {
+			report.Resources = nil
+		}
+	}
+	if status.Filters != nil {
+		f := CollectionFilters(*status.Filters)
+		report.Filters = &f
+	}
+	return report
+}
+
+// convertJSON converte in em out via JSON. Usado entre os tipos do agent e os do CRD,
+// que têm o mesmo formato JSON.
+func convertJSON(in, out interface{}) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
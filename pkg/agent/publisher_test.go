@@ -0,0 +1,101 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	reportsfake "github.com/totvs/addon-framework-basic/pkg/client/clientset/versioned/fake"
+)
+
+func TestPodReportPublisher(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	reportClient := reportsfake.NewClientset()
+	publisher := &podReportPublisher{client: reportClient, namespace: "cluster1"}
+	report := testReport(2)
+	report.Timestamp = time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	report.Pods[0].Containers = []ContainerInfo{{Name: "app", WaitingReason: "CrashLoopBackOff"}}
+
+	// Act: the first publish creates the resource, the second updates it
+	if err := publisher.Publish(ctx, &report); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	report.TotalPods = 3
+	if err := publisher.Publish(ctx, &report); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	// Assert
+	obj, err := reportClient.ReportsV1alpha1().PodReports("cluster1").Get(ctx, PodReportName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get PodReport: %v", err)
+	}
+	// The fake tracker ignores the status subresource, so only .status is checked here
+	if obj.Status.ClusterName != "cluster1" {
+		t.Errorf("status.clusterName = %q, want cluster1", obj.Status.ClusterName)
+	}
+	if obj.Status.TotalPods != 3 {
+		t.Errorf("TotalPods = %d, want 3", obj.Status.TotalPods)
+	}
+	if !obj.Status.LastSyncTime.Time.Equal(report.Timestamp) {
+		t.Errorf("LastSyncTime = %v, want %v", obj.Status.LastSyncTime, report.Timestamp)
+	}
+	if len(obj.Status.Pods) != 2 || obj.Status.Pods[0].Containers[0].WaitingReason != "CrashLoopBackOff" {
+		t.Errorf("Pods = %+v, want 2 pods with container detail", obj.Status.Pods)
+	}
+}
+
+func TestToPodReportStatusTruncatesOversizedPods(t *testing.T) {
+	// Arrange: enough pods to exceed MaxObjectBytes
+	report := testReport(MaxObjectBytes / 50)
+
+	// Act
+	status := toPodReportStatus(&report)
+
+	// Assert
+	if !status.Truncated || status.Pods != nil {
+		t.Errorf("Truncated = %v, len(Pods) = %d, want truncated summary", status.Truncated, len(status.Pods))
+	}
+	if int(status.TotalPods) != report.TotalPods {
+		t.Errorf("TotalPods = %d, want %d", status.TotalPods, report.TotalPods)
+	}
+}
+
+func TestNewPublisher(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    AgentOptions
+		want    string
+		wantErr bool
+	}{
+		{name: "default is crd", opts: AgentOptions{}, want: "*agent.podReportPublisher"},
+		{name: "configmap", opts: AgentOptions{ReportBackend: BackendConfigMap}, want: "*agent.configMapPublisher"},
+		{name: "unknown backend", opts: AgentOptions{ReportBackend: "s3"}, wantErr: true},
+		{name: "unknown encoding", opts: AgentOptions{ReportBackend: BackendConfigMap, ReportEncoding: "zstd"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			p, err := tt.opts.newPublisher(fake.NewClientset(), reportsfake.NewClientset())
+
+			// Assert
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := fmt.Sprintf("%T", p); got != tt.want {
+				t.Errorf("publisher = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package agent
+
+import (
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// ResourceSummary soma requests e limits de CPU e memória dos pods, por cluster e por namespace.
+// Pods em fase terminal (Succeeded, Failed) ficam de fora, como na ResourceQuota.
+// Quantidades seguem o formato do Kubernetes (ex: cpu "1500m", memory "3Gi").
+type ResourceSummary struct {
+	Requests   ResourceAmounts      `json:"requests"`
+	Limits     ResourceAmounts      `json:"limits"`
+	QoSClasses map[string]int       `json:"qosClasses,omitempty"` // Guaranteed, Burstable, BestEffort -> pods
+	Usage      *ResourceAmounts     `json:"usage,omitempty"`      // Uso medido pelo metrics-server (só com --metrics)
+	Namespaces []NamespaceResources `json:"namespaces,omitempty"`
+}
+
+// ResourceAmounts são as quantidades de CPU e memória.
+type ResourceAmounts struct {
+	CPU    string `json:"cpu"`
+	Memory string `json:"memory"`
+}
+
+// NamespaceResources é o resumo de recursos de um namespace.
+type NamespaceResources struct {
+	Namespace  string           `json:"namespace"`
+	Pods       int              `json:"pods"`
+	Requests   ResourceAmounts  `json:"requests"`
+	Limits     ResourceAmounts  `json:"limits"`
+	QoSClasses map[string]int   `json:"qosClasses,omitempty"`
+	Usage      *ResourceAmounts `json:"usage,omitempty"`
+	Quotas     []QuotaUsage     `json:"quotas,omitempty"`
+}
+
+// QuotaUsage compara o uso calculado pelo agent com o limite (hard) de uma ResourceQuota.
+// Só os recursos de CPU e memória (cpu, memory, requests.*, limits.*) entram.
+type QuotaUsage struct {
+	Quota    string `json:"quota"`
+	Resource string `json:"resource"`
+	Hard     string `json:"hard"`
+	Used     string `json:"used"`
+	Percent  int64  `json:"percent"` // Used / Hard em %, arredondado para baixo
+}
+
+// resourceTotals acumula as somas de um escopo (cluster ou namespace).
+type resourceTotals struct {
+	pods                int
+	requestsCPU, limCPU resource.Quantity
+	requestsMem, limMem resource.Quantity
+	qos                 map[string]int
+	usage               corev1.ResourceList // nil sem métricas
+}
+
+func (t *resourceTotals) add(requests, limits, usage corev1.ResourceList, qos string) {
+	t.pods++
+	if usage != nil {
+		if t.usage == nil {
+			t.usage = corev1.ResourceList{}
+		}
+		addResources(t.usage, usage)
+	}
+	t.requestsCPU.Add(requests[corev1.ResourceCPU])
+	t.requestsMem.Add(requests[corev1.ResourceMemory])
+	t.limCPU.Add(limits[corev1.ResourceCPU])
+	t.limMem.Add(limits[corev1.ResourceMemory])
+	if t.qos == nil {
+		t.qos = map[string]int{}
+	}
+	t.qos[qos]++
+}
+
+func (t *resourceTotals) requests() ResourceAmounts {
+	return ResourceAmounts{CPU: t.requestsCPU.String(), Memory: t.requestsMem.String()}
+}
+
+func (t *resourceTotals) limits() ResourceAmounts {
+	return ResourceAmounts{CPU: t.limCPU.String(), Memory: t.limMem.String()}
+}
+
+// quotaResources mapeia os recursos de CPU e memória da ResourceQuota para a soma correspondente.
+var quotaResources = map[corev1.ResourceName]func(t *resourceTotals) resource.Quantity{
+	corev1.ResourceCPU:            func(t *resourceTotals) resource.Quantity { return t.requestsCPU },
+	corev1.ResourceMemory:         func(t *resourceTotals) resource.Quantity { return t.requestsMem },
+	corev1.ResourceRequestsCPU:    func(t *resourceTotals) resource.Quantity { return t.requestsCPU },
+	corev1.ResourceRequestsMemory: func(t *resourceTotals) resource.Quantity { return t.requestsMem },
+	corev1.ResourceLimitsCPU:      func(t *resourceTotals) resource.Quantity { return t.limCPU },
+	corev1.ResourceLimitsMemory:   func(t *resourceTotals) resource.Quantity { return t.limMem },
+}
+
+// buildResourceSummary soma requests, limits e classes de QoS dos pods e compara o uso de
+// cada namespace com as ResourceQuotas dele. Namespaces só com quota (sem pods) também entram.
+// usage é o uso medido de cada pod (chave namespace/nome); nil deixa Usage de fora.
+func buildResourceSummary(pods []*corev1.Pod, quotas []*corev1.ResourceQuota, usage map[string]corev1.ResourceList) *ResourceSummary {
+	cluster := &resourceTotals{qos: map[string]int{}}
+	namespaces := map[string]*resourceTotals{}
+	scope := func(ns string) *resourceTotals {
+		if namespaces[ns] == nil {
+			namespaces[ns] = &resourceTotals{qos: map[string]int{}}
+		}
+		return namespaces[ns]
+	}
+	for _, p := range pods {
+		if p.Status.Phase == corev1.PodSucceeded || p.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		requests, limits := podRequests(p), podLimits(p)
+		qos, used := podQOSClass(p), usage[p.Namespace+"/"+p.Name]
+		cluster.add(requests, limits, used, qos)
+		scope(p.Namespace).add(requests, limits, used, qos)
+	}
+	byNamespace := map[string][]*corev1.ResourceQuota{}
+	for _, q := range quotas {
+		scope(q.Namespace)
+		byNamespace[q.Namespace] = append(byNamespace[q.Namespace], q)
+	}
+
+	summary := &ResourceSummary{
+		Requests:   cluster.requests(),
+		Limits:     cluster.limits(),
+		QoSClasses: cluster.qos,
+		Usage:      usageAmounts(cluster.usage),
+		Namespaces: make([]NamespaceResources, 0, len(namespaces)),
+	}
+	for ns, t := range namespaces {
+		summary.Namespaces = append(summary.Namespaces, NamespaceResources{
+			Namespace:  ns,
+			Pods:       t.pods,
+			Requests:   t.requests(),
+			Limits:     t.limits(),
+			QoSClasses: t.qos,
+			Usage:      usageAmounts(t.usage),
+			Quotas:     quotaUsage(byNamespace[ns], t),
+		})
+	}
+	sort.Slice(summary.Namespaces, func(i, j int) bool {
+		return summary.Namespaces[i].Namespace < summary.Namespaces[j].Namespace
+	})
+	return summary
+}
+
+// quotaUsage compara as somas do namespace com os limites das quotas, ordenado por quota/recurso.
+func quotaUsage(quotas []*corev1.ResourceQuota, t *resourceTotals) []QuotaUsage {
+	var usage []QuotaUsage
+	for _, q := range quotas {
+		for name, hard := range q.Spec.Hard {
+			used, ok := quotaResources[name]
+			if !ok {
+				continue
+			}
+			u := used(t)
+			entry := QuotaUsage{Quota: q.Name, Resource: string(name), Hard: hard.String(), Used: u.String()}
+			if hard.MilliValue() > 0 {
+				entry.Percent = u.MilliValue() * 100 / hard.MilliValue()
+			}
+			usage = append(usage, entry)
+		}
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].Quota != usage[j].Quota {
+			return usage[i].Quota < usage[j].Quota
+		}
+		return usage[i].Resource < usage[j].Resource
+	})
+	return usage
+}
+
+// podRequests calcula os requests efetivos do pod, como o scheduler:
+// max(soma dos containers + sidecars, maior init container + sidecars iniciados antes dele) + overhead.
+func podRequests(p *corev1.Pod) corev1.ResourceList {
+	return effectiveResources(p, func(c corev1.Container) corev1.ResourceList { return c.Resources.Requests })
+}
+
+// podLimits calcula os limits efetivos do pod, com a mesma regra de podRequests.
+// Containers sem limit não somam (o limit do pod fica subestimado, não infinito).
+func podLimits(p *corev1.Pod) corev1.ResourceList {
+	return effectiveResources(p, func(c corev1.Container) corev1.ResourceList { return c.Resources.Limits })
+}
+
+func effectiveResources(p *corev1.Pod, get func(corev1.Container) corev1.ResourceList) corev1.ResourceList {
+	total := corev1.ResourceList{}
+	for _, c := range p.Spec.Containers {
+		addResources(total, get(c))
+	}
+	sidecars := corev1.ResourceList{}
+	initMax := corev1.ResourceList{}
+	for _, c := range p.Spec.InitContainers {
+		if c.RestartPolicy != nil && *c.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+			// sidecar: roda junto com os containers do pod
+			addResources(sidecars, get(c))
+			addResources(total, get(c))
+			continue
+		}
+		step := corev1.ResourceList{}
+		addResources(step, sidecars)
+		addResources(step, get(c))
+		maxResources(initMax, step)
+	}
+	maxResources(total, initMax)
+	addResources(total, p.Spec.Overhead)
+	return total
+}
+
+// addResources soma CPU e memória de src em dst.
+func addResources(dst, src corev1.ResourceList) {
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		q, ok := src[name]
+		if !ok {
+			continue
+		}
+		sum := dst[name]
+		sum.Add(q)
+		dst[name] = sum
+	}
+}
+
+// maxResources guarda em dst o maior valor de CPU e memória entre dst e src.
+func maxResources(dst, src corev1.ResourceList) {
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		q, ok := src[name]
+		if !ok {
+			continue
+		}
+		if current, ok := dst[name]; !ok || q.Cmp(current) > 0 {
+			dst[name] = q
+		}
+	}
+}
+
+// podQOSClass devolve a classe de QoS do pod: Status.QOSClass, preenchido pelo apiserver,
+// ou calculada a partir dos containers quando vazio.
+func podQOSClass(p *corev1.Pod) string {
+	if p.Status.QOSClass != "" {
+		return string(p.Status.QOSClass)
+	}
+	containers := append(append([]corev1.Container{}, p.Spec.InitContainers...), p.Spec.Containers...)
+	bestEffort, guaranteed := true, true
+	for _, c := range containers {
+		for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			req, hasReq := c.Resources.Requests[name]
+			lim, hasLim := c.Resources.Limits[name]
+			if hasReq || hasLim {
+				bestEffort = false
+			}
+			if !hasLim || (hasReq && req.Cmp(lim) != 0) {
+				guaranteed = false
+			}
+		}
+	}
+	switch {
+	case bestEffort:
+		return string(corev1.PodQOSBestEffort)
+	case guaranteed:
+		return string(corev1.PodQOSGuaranteed)
+	default:
+		return string(corev1.PodQOSBurstable)
+	}
+}
@@ -0,0 +1,121 @@
+package agent
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func resources(cpuReq, memReq, cpuLim, memLim string) corev1.ResourceRequirements {
+	r := corev1.ResourceRequirements{Requests: corev1.ResourceList{}, Limits: corev1.ResourceList{}}
+	set := func(list corev1.ResourceList, name corev1.ResourceName, v string) {
+		if v != "" {
+			list[name] = resource.MustParse(v)
+		}
+	}
+	set(r.Requests, corev1.ResourceCPU, cpuReq)
+	set(r.Requests, corev1.ResourceMemory, memReq)
+	set(r.Limits, corev1.ResourceCPU, cpuLim)
+	set(r.Limits, corev1.ResourceMemory, memLim)
+	return r
+}
+
+func podWithResources(namespace, name string, phase corev1.PodPhase, containers ...corev1.ResourceRequirements) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Status:     corev1.PodStatus{Phase: phase},
+	}
+	for _, r := range containers {
+		pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: "c", Resources: r})
+	}
+	return pod
+}
+
+func TestBuildResourceSummary(t *testing.T) {
+	// Arrange
+	pods := []*corev1.Pod{
+		podWithResources("team-a", "guaranteed", corev1.PodRunning, resources("500m", "1Gi", "500m", "1Gi")),
+		podWithResources("team-a", "burstable", corev1.PodRunning, resources("250m", "512Mi", "1", ""), resources("250m", "", "", "")),
+		podWithResources("team-b", "besteffort", corev1.PodPending, resources("", "", "", "")),
+		podWithResources("team-b", "done", corev1.PodSucceeded, resources("4", "8Gi", "", "")),
+	}
+	quotas := []*corev1.ResourceQuota{{
+		ObjectMeta: metav1.ObjectMeta{Name: "compute", Namespace: "team-a"},
+		Spec: corev1.ResourceQuotaSpec{Hard: corev1.ResourceList{
+			corev1.ResourceRequestsCPU:  resource.MustParse("2"),
+			corev1.ResourceLimitsMemory: resource.MustParse("4Gi"),
+			corev1.ResourcePods:         resource.MustParse("10"),
+		}},
+	}}
+
+	// Act
+	summary := buildResourceSummary(pods, quotas, nil)
+
+	// Assert: terminal pods are not counted
+	if summary.Requests != (ResourceAmounts{CPU: "1", Memory: "1536Mi"}) {
+		t.Errorf("cluster requests = %+v, want 1/1536Mi", summary.Requests)
+	}
+	if summary.Limits != (ResourceAmounts{CPU: "1500m", Memory: "1Gi"}) {
+		t.Errorf("cluster limits = %+v, want 1500m/1Gi", summary.Limits)
+	}
+	if summary.QoSClasses["Guaranteed"] != 1 || summary.QoSClasses["Burstable"] != 1 || summary.QoSClasses["BestEffort"] != 1 {
+		t.Errorf("QoSClasses = %v", summary.QoSClasses)
+	}
+	if len(summary.Namespaces) != 2 || summary.Namespaces[0].Namespace != "team-a" || summary.Namespaces[0].Pods != 2 {
+		t.Fatalf("Namespaces = %+v", summary.Namespaces)
+	}
+
+	// Assert: only CPU and memory quota resources are compared
+	quotaUsage := summary.Namespaces[0].Quotas
+	want := []QuotaUsage{
+		{Quota: "compute", Resource: "limits.memory", Hard: "4Gi", Used: "1Gi", Percent: 25},
+		{Quota: "compute", Resource: "requests.cpu", Hard: "2", Used: "1", Percent: 50},
+	}
+	if len(quotaUsage) != len(want) {
+		t.Fatalf("Quotas = %+v, want %+v", quotaUsage, want)
+	}
+	for i := range want {
+		if quotaUsage[i] != want[i] {
+			t.Errorf("Quotas[%d] = %+v, want %+v", i, quotaUsage[i], want[i])
+		}
+	}
+}
+
+func TestPodRequestsWithInitContainers(t *testing.T) {
+	// Arrange: init container larger than the app; a sidecar runs along the app
+	always := corev1.ContainerRestartPolicyAlways
+	pod := podWithResources("default", "web", corev1.PodRunning, resources("100m", "128Mi", "", ""))
+	pod.Spec.InitContainers = []corev1.Container{
+		{Name: "sidecar", RestartPolicy: &always, Resources: resources("50m", "64Mi", "", "")},
+		{Name: "migrate", Resources: resources("1", "64Mi", "", "")},
+	}
+	pod.Spec.Overhead = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10m")}
+
+	// Act
+	requests := podRequests(pod)
+
+	// Assert: cpu = max(100m+50m, 50m+1) + 10m overhead; memory = max(128Mi+64Mi, 64Mi+64Mi)
+	if cpu := requests[corev1.ResourceCPU]; cpu.String() != "1060m" {
+		t.Errorf("cpu = %s, want 1060m", cpu.String())
+	}
+	if mem := requests[corev1.ResourceMemory]; mem.String() != "192Mi" {
+		t.Errorf("memory = %s, want 192Mi", mem.String())
+	}
+}
+
+func TestResourcesSurviveStatusRoundTrip(t *testing.T) {
+	// Arrange
+	o := &AgentOptions{SpokeClusterName: "cluster1"}
+	pods := []*corev1.Pod{podWithResources("team-a", "web", corev1.PodRunning, resources("500m", "1Gi", "1", "2Gi"))}
+	report := o.buildReport(pods, nil, nil, nil)
+
+	// Act: the crd backend recomputes the hash from the status
+	roundTrip := fromPodReportStatus(toPodReportStatus(&report))
+
+	// Assert
+	if reportHash(roundTrip) != reportHash(&report) {
+		t.Errorf("hash changed after the status round trip: %+v vs %+v", roundTrip.Resources, report.Resources)
+	}
+}
@@ -0,0 +1,143 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// Versões do schema do PodReport (campo apiVersion do payload).
+//
+//   - v1: relatórios sem apiVersion (agents anteriores ao versionamento). Inclui o formato
+//     original (só name/namespace/status por pod) e o com restarts e containers.
+//   - v2: adiciona apiVersion e phases (quantidade de pods por fase). Depois ganhou workload
+//     e usage por pod, resources e filters (opcionais, omitidos quando vazios), que leitores v2
+//     antigos ignoram.
+//
+// Para mudar o schema: congele a versão atual em tipos podReportVN (como podReportV1), crie
+// a conversão para a nova versão, registre o decoder em reportDecoders e adicione as
+// fixtures em testdata/schema.
+const (
+	SchemaV1 = "v1"
+	SchemaV2 = "v2"
+)
+
+// reportDecoder decodifica o JSON de uma versão do schema e converte para a versão atual.
+type reportDecoder func(data []byte) (*PodReport, error)
+
+// reportDecoders é o registro de decoders por versão do schema.
+var reportDecoders = map[string]reportDecoder{
+	SchemaV1: decodeV1,
+	SchemaV2: decodeLatest,
+}
+
+// decodeVersioned decodifica o relatório conforme o apiVersion do payload.
+// Sem apiVersion o payload é v1. Versões desconhecidas (agent mais novo que o leitor) são
+// decodificadas como a versão atual: campos novos são ignorados e APIVersion mantém a versão
+// original, para o chamador saber que o relatório pode estar incompleto.
+func decodeVersioned(data []byte) (*PodReport, error) {
+	var meta struct {
+		APIVersion string `json:"apiVersion"`
+	}
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, err
+	}
+	version := meta.APIVersion
+	if version == "" {
+		version = SchemaV1
+	}
+
+	decoder, ok := reportDecoders[version]
+	if !ok {
+		klog.V(2).Infof("Versão de schema desconhecida %q, decodificando como %s", version, SchemaVersion)
+		decoder = decodeLatest
+	}
+	report, err := decoder(data)
+	if err != nil {
+		return nil, fmt.Errorf("relatório %s inválido: %w", version, err)
+	}
+	return report, nil
+}
+
+// decodeLatest decodifica a versão atual (sem conversão).
+func decodeLatest(data []byte) (*PodReport, error) {
+	report := &PodReport{}
+	if err := json.Unmarshal(data, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// podReportV1 é o schema v1, congelado. Não altere: payloads v1 gravados no hub dependem dele.
+type podReportV1 struct {
+	ClusterName string      `json:"clusterName"`
+	Timestamp   time.Time   `json:"timestamp"`
+	TotalPods   int         `json:"totalPods"`
+	Pods        []podInfoV1 `json:"pods"`
+}
+
+// podInfoV1 é o pod do schema v1. Restarts e Containers não existem no formato original.
+type podInfoV1 struct {
+	Name       string            `json:"name"`
+	Namespace  string            `json:"namespace"`
+	Status     string            `json:"status"`
+	Restarts   int32             `json:"restarts"`
+	Containers []containerInfoV1 `json:"containers,omitempty"`
+}
+
+// containerInfoV1 é o container do schema v1.
+type containerInfoV1 struct {
+	Name                  string `json:"name"`
+	Init                  bool   `json:"init,omitempty"`
+	Image                 string `json:"image"`
+	ImageID               string `json:"imageID,omitempty"`
+	Ready                 bool   `json:"ready"`
+	RestartCount          int32  `json:"restartCount"`
+	State                 string `json:"state"`
+	WaitingReason         string `json:"waitingReason,omitempty"`
+	LastTerminationReason string `json:"lastTerminationReason,omitempty"`
+}
+
+// decodeV1 decodifica um relatório v1 e converte para a versão atual.
+func decodeV1(data []byte) (*PodReport, error) {
+	in := &podReportV1{}
+	if err := json.Unmarshal(data, in); err != nil {
+		return nil, err
+	}
+	return convertV1ToV2(in), nil
+}
+
+// convertV1ToV2 converte v1 em v2: preenche apiVersion e calcula phases a partir dos pods.
+func convertV1ToV2(in *podReportV1) *PodReport {
+	out := &PodReport{
+		APIVersion:  SchemaV2,
+		ClusterName: in.ClusterName,
+		Timestamp:   in.Timestamp,
+		TotalPods:   in.TotalPods,
+		Pods:        make([]PodInfo, len(in.Pods)),
+	}
+	for i, p := range in.Pods {
+		out.Pods[i] = PodInfo{
+			Name:      p.Name,
+			Namespace: p.Namespace,
+			Status:    p.Status,
+			Restarts:  p.Restarts,
+		}
+		for _, c := range p.Containers {
+			out.Pods[i].Containers = append(out.Pods[i].Containers, ContainerInfo(c))
+		}
+	}
+	out.Phases = countPhases(out.Pods)
+	return out
+}
+
+// countPhases conta os pods por fase (PodInfo.Status).
+func countPhases(pods []PodInfo) map[string]int {
+	phases := map[string]int{}
+	for _, p := range pods {
+		phases[p.Status]++
+	}
+	return phases
+}
@@ -0,0 +1,123 @@
+package agent
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// go test ./pkg/agent -run TestSchemaGoldenFixtures -update regenerates the .golden files.
+var update = flag.Bool("update", false, "update golden files in testdata/schema")
+
+func TestSchemaGoldenFixtures(t *testing.T) {
+	fixtures, err := filepath.Glob("testdata/schema/*.json")
+	if err != nil {
+		t.Fatalf("glob: %v", err)
+	}
+	for _, fixture := range fixtures {
+		if strings.HasSuffix(fixture, ".golden.json") {
+			continue
+		}
+		t.Run(filepath.Base(fixture), func(t *testing.T) {
+			// Arrange
+			data, err := os.ReadFile(fixture)
+			if err != nil {
+				t.Fatalf("read fixture: %v", err)
+			}
+			golden := strings.TrimSuffix(fixture, ".json") + ".golden.json"
+
+			// Act: every version decodes into the latest shape
+			report, err := DecodeReport(data, EncodingJSON)
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			got, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+			got = append(got, '\n')
+
+			// Assert
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatalf("write golden: %v", err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("read golden (run with -update to create it): %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("decoded %s differs from %s:\n%s", fixture, golden, got)
+			}
+		})
+	}
+}
+
+func TestSchemaEveryVersionHasFixture(t *testing.T) {
+	for version := range reportDecoders {
+		matches, _ := filepath.Glob("testdata/schema/" + version + "*.json")
+		if len(matches) == 0 {
+			t.Errorf("version %s has no fixture in testdata/schema", version)
+		}
+	}
+}
+
+func TestDecodeV1UpgradesToLatest(t *testing.T) {
+	// Arrange: the original report shape, without apiVersion
+	data := []byte(`{"clusterName":"c1","totalPods":3,"pods":[` +
+		`{"name":"a","namespace":"x","status":"Running"},` +
+		`{"name":"b","namespace":"x","status":"Running"},` +
+		`{"name":"c","namespace":"x","status":"Failed"}]}`)
+
+	// Act
+	report, err := DecodeReport(data, EncodingJSON)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if report.APIVersion != SchemaVersion {
+		t.Errorf("APIVersion = %q, want %q", report.APIVersion, SchemaVersion)
+	}
+	if report.Phases["Running"] != 2 || report.Phases["Failed"] != 1 {
+		t.Errorf("Phases = %v, want Running=2 Failed=1", report.Phases)
+	}
+}
+
+func TestDecodeFutureVersionKeepsKnownFields(t *testing.T) {
+	// Arrange: a newer agent during a rollout
+	data := []byte(`{"apiVersion":"v99","clusterName":"c1","totalPods":1,"newField":true,` +
+		`"pods":[{"name":"a","namespace":"x","status":"Running"}]}`)
+
+	// Act
+	report, err := DecodeReport(data, EncodingJSON)
+
+	// Assert: decoded best-effort, the original version is kept
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if report.APIVersion != "v99" || report.TotalPods != 1 || report.Pods[0].Name != "a" {
+		t.Errorf("report = %+v", report)
+	}
+}
+
+func TestBuildReportUsesLatestSchema(t *testing.T) {
+	// Arrange
+	o := &AgentOptions{SpokeClusterName: "cluster1"}
+	pods := []*corev1.Pod{newPod("default", "a"), newPod("default", "b")}
+
+	// Act
+	report := o.buildReport(pods, nil, nil, nil)
+
+	// Assert
+	if report.APIVersion != SchemaVersion || report.Phases["Running"] != 2 {
+		t.Errorf("APIVersion = %q, Phases = %v", report.APIVersion, report.Phases)
+	}
+}
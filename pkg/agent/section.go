@@ -0,0 +1,165 @@
+package agent
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// SectionReport é o envelope da seção de um collector (exceto pods) gravada no hub.
+// Data é a saída do collector, serializada em JSON.
+type SectionReport struct {
+	APIVersion  string             `json:"apiVersion"`
+	ClusterName string             `json:"clusterName"`
+	Collector   string             `json:"collector"`
+	Timestamp   time.Time          `json:"timestamp"`
+	Filters     *CollectionFilters `json:"filters,omitempty"` // Filtros de coleta em vigor (ver CollectionFilters)
+	Data        json.RawMessage    `json:"data"`
+}
+
+// SectionName é o nome do ConfigMap da seção do collector no hub: <collector>-report.
+func SectionName(collector string) string {
+	return collector + "-report"
+}
+
+// sectionPublisher grava a seção de cada collector no ConfigMap <collector>-report do
+// namespace do cluster no hub (com shards e encoding, ver publishConfigMap).
+//
+// Assim como o relatório de pods, a seção só é gravada quando Data muda: o hash fica em
+// ContentHashAnnotation e, em memória, por collector. No primeiro sync ele vem do hub.
+// O heartbeat fica só no relatório de pods. Seções com uso medido (usageSection) deixam o uso
+// fora do hash; se só ele mudou, a seção é regravada no máximo a cada heartbeatInterval.
+type sectionPublisher struct {
+	client            kubernetes.Interface
+	namespace         string
+	clusterName       string
+	encoding          string
+	filters           *CollectionFilters
+	heartbeatInterval time.Duration
+	now               func() time.Time
+
+	hashes  map[string]string    // hash da última seção publicada, por collector
+	usage   map[string]string    // hash da última seção com o uso, por collector (vazio sem uso)
+	written map[string]time.Time // última escrita, por collector
+}
+
+// usageSection é a saída de collector com uso medido pelo metrics-server, que muda a cada
+// consulta e fica fora do hash da seção.
+type usageSection interface {
+	withoutUsage() interface{}
+}
+
+// newSectionPublisher cria o publisher das seções. O backend crd também grava as seções em
+// ConfigMaps (só o PodReport tem CRD); o encoding vem de --report-encoding.
+func (o *AgentOptions) newSectionPublisher(hubClient kubernetes.Interface) *sectionPublisher {
+	encoding := o.ReportEncoding
+	if !validEncoding(encoding) {
+		encoding = EncodingJSON
+	}
+	heartbeatInterval := o.HeartbeatInterval
+	if heartbeatInterval <= 0 {
+		heartbeatInterval = HeartbeatInterval
+	}
+	return &sectionPublisher{
+		client:            hubClient,
+		namespace:         o.SpokeClusterName,
+		clusterName:       o.SpokeClusterName,
+		encoding:          encoding,
+		filters:           o.filters(),
+		heartbeatInterval: heartbeatInterval,
+		now:               time.Now,
+		hashes:            map[string]string{},
+		usage:             map[string]string{},
+		written:           map[string]time.Time{},
+	}
+}
+
+// Publish grava a seção do collector se o conteúdo mudou.
+func (p *sectionPublisher) Publish(ctx context.Context, collector string, data interface{}) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("falha ao serializar seção %s: %w", collector, err)
+	}
+	// Com filtros, eles entram no hash: mudar o filtro regrava a seção mesmo com Data igual
+	hashed, usage := raw, ""
+	if u, ok := data.(usageSection); ok {
+		stable, err := json.Marshal(u.withoutUsage())
+		if err != nil {
+			return fmt.Errorf("falha ao serializar seção %s: %w", collector, err)
+		}
+		if string(stable) != string(raw) {
+			sum := sha256.Sum256(raw)
+			hashed, usage = stable, hex.EncodeToString(sum[:])
+		}
+	}
+	if p.filters != nil {
+		filters, _ := json.Marshal(p.filters)
+		hashed = append(append([]byte{}, hashed...), filters...)
+	}
+	sum := sha256.Sum256(hashed)
+	hash := hex.EncodeToString(sum[:])
+
+	name := SectionName(collector)
+	if _, seeded := p.hashes[collector]; !seeded {
+		cm, err := p.client.CoreV1().ConfigMaps(p.namespace).Get(ctx, name, metav1.GetOptions{})
+		switch {
+		case err == nil:
+			p.hashes[collector] = cm.Annotations[ContentHashAnnotation]
+		case !errors.IsNotFound(err):
+			// Sem o hash do hub a seção é regravada
+			klog.Warningf("Falha ao ler hash da seção %s no hub: %v", collector, err)
+		}
+	}
+	now := p.now()
+	if p.hashes[collector] == hash &&
+		(p.usage[collector] == usage || now.Sub(p.written[collector]) < p.heartbeatInterval) {
+		klog.V(2).Infof("Seção %s sem mudanças (hash %s), escrita ignorada", collector, hash)
+		return nil
+	}
+
+	section := &SectionReport{
+		APIVersion:  SchemaVersion,
+		ClusterName: p.clusterName,
+		Collector:   collector,
+		Timestamp:   now.UTC(),
+		Filters:     p.filters,
+		Data:        raw,
+	}
+	payload, err := encodeReport(section, p.encoding)
+	if err != nil {
+		return fmt.Errorf("falha ao codificar seção %s: %w", collector, err)
+	}
+	err = publishConfigMap(ctx, p.client, p.namespace, name, payload, p.encoding,
+		map[string]string{ContentHashAnnotation: hash}, MaxObjectBytes)
+	if err != nil {
+		return err
+	}
+	p.hashes[collector], p.usage[collector], p.written[collector] = hash, usage, now
+	return nil
+}
+
+// ReadSection lê a seção do collector publicada pelo agent no namespace do cluster no hub.
+// Data fica em JSON; o formato é definido pelo collector.
+func ReadSection(ctx context.Context, hubClient kubernetes.Interface, clusterName, collector string) (*SectionReport, error) {
+	data, encoding, err := readPayload(ctx, hubClient, clusterName, SectionName(collector))
+	if err != nil {
+		return nil, err
+	}
+	raw, err := DecodePayload(data, encoding)
+	if err != nil {
+		return nil, err
+	}
+	section := &SectionReport{}
+	if err := json.Unmarshal(raw, section); err != nil {
+		return nil, fmt.Errorf("seção %s inválida: %w", collector, err)
+	}
+	return section, nil
+}
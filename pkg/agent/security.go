@@ -0,0 +1,270 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	corelisters "k8s.io/client-go/listers/core/v1"
+)
+
+// CollectorSecurity é o collector da postura de segurança dos pods (seção security-report no hub).
+const CollectorSecurity = "security"
+
+// Checks da análise de segurança (SecurityFinding.Check).
+const (
+	CheckPrivileged          = "privileged"                   // Container privilegiado
+	CheckHostNetwork         = "hostNetwork"                  // Pod na rede do node
+	CheckHostPID             = "hostPID"                      // Pod no namespace de PIDs do node
+	CheckHostIPC             = "hostIPC"                      // Pod no namespace de IPC do node
+	CheckHostPath            = "hostPath"                     // Volume hostPath montado
+	CheckRunAsRoot           = "runAsRoot"                    // Container pode rodar como root
+	CheckSeccomp             = "seccompProfileMissing"        // Sem seccomp profile (ou Unconfined)
+	CheckAddedCapabilities   = "addedCapabilities"            // Capabilities adicionadas
+	CheckServiceAccountToken = "automountServiceAccountToken" // Token da service account montado
+)
+
+// Severidades dos findings.
+const (
+	SeverityHigh   = "high"
+	SeverityMedium = "medium"
+	SeverityLow    = "low"
+)
+
+// checkSeverity é a severidade de cada check.
+var checkSeverity = map[string]string{
+	CheckPrivileged:          SeverityHigh,
+	CheckHostNetwork:         SeverityHigh,
+	CheckHostPID:             SeverityHigh,
+	CheckHostIPC:             SeverityMedium,
+	CheckHostPath:            SeverityHigh,
+	CheckRunAsRoot:           SeverityMedium,
+	CheckSeccomp:             SeverityLow,
+	CheckAddedCapabilities:   SeverityMedium,
+	CheckServiceAccountToken: SeverityLow,
+}
+
+// SecurityReport é a seção do collector security: findings de postura de segurança dos pods
+// em execução (Succeeded e Failed ficam de fora).
+type SecurityReport struct {
+	TotalPods        int               `json:"totalPods"`        // Pods analisados
+	PodsWithFindings int               `json:"podsWithFindings"` // Pods com ao menos um finding
+	Checks           map[string]int    `json:"checks"`           // check -> findings
+	Severities       map[string]int    `json:"severities"`       // high, medium, low -> findings
+	Findings         []SecurityFinding `json:"findings"`         // Ordenados por namespace/pod/check/container
+}
+
+// SecurityFinding é um problema de postura encontrado em um pod ou container.
+type SecurityFinding struct {
+	Check     string       `json:"check"`
+	Severity  string       `json:"severity"`
+	Namespace string       `json:"namespace"`
+	Pod       string       `json:"pod"`
+	Container string       `json:"container,omitempty"` // Vazio para checks do pod (ex: hostNetwork)
+	Workload  *WorkloadRef `json:"workload,omitempty"`
+	Detail    string       `json:"detail,omitempty"` // ex: caminho do hostPath, capabilities adicionadas
+}
+
+func init() {
+	RegisterCollector(CollectorSecurity, func(o *AgentOptions) Collector { return &securityCollector{options: o, filters: o.filters()} })
+}
+
+// securityCollector analisa os pods do cache (o mesmo informer do collector pods).
+// As service accounts resolvem o automount do token quando o pod não define.
+type securityCollector struct {
+	options         *AgentOptions
+	filters         *CollectionFilters
+	pods            corelisters.PodLister
+	serviceAccounts corelisters.ServiceAccountLister
+	owners          *ownerResolver
+}
+
+// Name implementa Collector.
+func (c *securityCollector) Name() string { return CollectorSecurity }
+
+// Rules implementa Collector.
+func (c *securityCollector) Rules() []rbacv1.PolicyRule {
+	return append([]rbacv1.PolicyRule{{
+		APIGroups: []string{""},
+		Resources: []string{"pods", "serviceaccounts"},
+		Verbs:     []string{"get", "list", "watch"},
+	}}, ownerRules()...)
+}
+
+// Register implementa Collector.
+func (c *securityCollector) Register(factory informers.SharedInformerFactory, changed func()) error {
+	pods, lister := podInformer(factory, c.options)
+	c.pods = lister
+	serviceAccounts := factory.Core().V1().ServiceAccounts()
+	c.serviceAccounts = serviceAccounts.Lister()
+	c.owners = newOwnerResolver(factory)
+	if err := notifyOnChange(serviceAccounts.Informer(), changed); err != nil {
+		return err
+	}
+	return notifyOnChange(pods, changed)
+}
+
+// Collect implementa Collector. Devolve *SecurityReport.
+func (c *securityCollector) Collect(context.Context) (interface{}, error) {
+	pods, err := c.pods.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("falha ao listar pods: %w", err)
+	}
+	return buildSecurityReport(filterNamespaces(c.filters, pods), c.owners, c.automountDefault), nil
+}
+
+// automountDefault devolve o automount da service account do pod (true se ela não define ou não existe).
+func (c *securityCollector) automountDefault(p *corev1.Pod) bool {
+	name := p.Spec.ServiceAccountName
+	if name == "" {
+		name = "default"
+	}
+	sa, err := c.serviceAccounts.ServiceAccounts(p.Namespace).Get(name)
+	if err != nil || sa.AutomountServiceAccountToken == nil {
+		return true
+	}
+	return *sa.AutomountServiceAccountToken
+}
+
+// buildSecurityReport analisa os pods em execução. owners resolve o workload de cada pod;
+// automount diz se a service account do pod monta o token quando o pod não define.
+func buildSecurityReport(pods []*corev1.Pod, owners *ownerResolver, automount func(*corev1.Pod) bool) *SecurityReport {
+	report := &SecurityReport{Checks: map[string]int{}, Severities: map[string]int{}, Findings: []SecurityFinding{}}
+	for _, p := range pods {
+		if p.Status.Phase == corev1.PodSucceeded || p.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		report.TotalPods++
+		findings := podFindings(p, automount)
+		if len(findings) == 0 {
+			continue
+		}
+		report.PodsWithFindings++
+		workload := owners.resolve(p)
+		for _, f := range findings {
+			f.Severity = checkSeverity[f.Check]
+			f.Namespace, f.Pod, f.Workload = p.Namespace, p.Name, workload
+			report.Checks[f.Check]++
+			report.Severities[f.Severity]++
+			report.Findings = append(report.Findings, f)
+		}
+	}
+	sort.Slice(report.Findings, func(i, j int) bool {
+		a, b := report.Findings[i], report.Findings[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Pod != b.Pod {
+			return a.Pod < b.Pod
+		}
+		if a.Check != b.Check {
+			return a.Check < b.Check
+		}
+		return a.Container < b.Container
+	})
+	return report
+}
+
+// podFindings devolve os findings do pod e dos seus containers (init e regulares), só com
+// Check, Container e Detail preenchidos.
+func podFindings(p *corev1.Pod, automount func(*corev1.Pod) bool) []SecurityFinding {
+	var findings []SecurityFinding
+	add := func(check, container, detail string) {
+		findings = append(findings, SecurityFinding{Check: check, Container: container, Detail: detail})
+	}
+
+	if p.Spec.HostNetwork {
+		add(CheckHostNetwork, "", "")
+	}
+	if p.Spec.HostPID {
+		add(CheckHostPID, "", "")
+	}
+	if p.Spec.HostIPC {
+		add(CheckHostIPC, "", "")
+	}
+	for _, v := range p.Spec.Volumes {
+		if v.HostPath != nil {
+			add(CheckHostPath, "", v.HostPath.Path)
+		}
+	}
+	token := p.Spec.AutomountServiceAccountToken
+	if (token == nil && automount(p)) || (token != nil && *token) {
+		add(CheckServiceAccountToken, "", "")
+	}
+
+	podSC := p.Spec.SecurityContext
+	if podSC == nil {
+		podSC = &corev1.PodSecurityContext{}
+	}
+	containers := append(append([]corev1.Container{}, p.Spec.InitContainers...), p.Spec.Containers...)
+	for _, e := range p.Spec.EphemeralContainers {
+		// Containers de debug (kubectl debug) também podem ser privilegiados ou ganhar capabilities
+		containers = append(containers, corev1.Container(e.EphemeralContainerCommon))
+	}
+	for _, c := range containers {
+		sc := c.SecurityContext
+		if sc == nil {
+			sc = &corev1.SecurityContext{}
+		}
+		if sc.Privileged != nil && *sc.Privileged {
+			add(CheckPrivileged, c.Name, "")
+		}
+		if sc.Capabilities != nil && len(sc.Capabilities.Add) > 0 {
+			caps := make([]string, len(sc.Capabilities.Add))
+			for i, capability := range sc.Capabilities.Add {
+				caps[i] = string(capability)
+			}
+			add(CheckAddedCapabilities, c.Name, strings.Join(caps, ","))
+		}
+		if detail, root := runsAsRoot(podSC, sc); root {
+			add(CheckRunAsRoot, c.Name, detail)
+		}
+		if detail, missing := seccompMissing(podSC, sc); missing {
+			add(CheckSeccomp, c.Name, detail)
+		}
+	}
+	return findings
+}
+
+// runsAsRoot diz se o container pode rodar como root. O securityContext do container
+// sobrescreve o do pod. Sem runAsNonRoot nem runAsUser o usuário é o da imagem (pode ser root).
+func runsAsRoot(pod *corev1.PodSecurityContext, c *corev1.SecurityContext) (string, bool) {
+	user, nonRoot := pod.RunAsUser, pod.RunAsNonRoot
+	if c.RunAsUser != nil {
+		user = c.RunAsUser
+	}
+	if c.RunAsNonRoot != nil {
+		nonRoot = c.RunAsNonRoot
+	}
+	switch {
+	case user != nil && *user == 0:
+		return "runAsUser=0", true
+	case user != nil:
+		return "", false
+	case nonRoot == nil:
+		return "runAsNonRoot e runAsUser não definidos", true
+	case !*nonRoot:
+		return "runAsNonRoot=false", true
+	}
+	return "", false
+}
+
+// seccompMissing diz se o container roda sem seccomp profile (não definido ou Unconfined).
+func seccompMissing(pod *corev1.PodSecurityContext, c *corev1.SecurityContext) (string, bool) {
+	profile := pod.SeccompProfile
+	if c.SeccompProfile != nil {
+		profile = c.SeccompProfile
+	}
+	switch {
+	case profile == nil:
+		return "não definido", true
+	case profile.Type == corev1.SeccompProfileTypeUnconfined:
+		return string(profile.Type), true
+	}
+	return "", false
+}
@@ -0,0 +1,169 @@
+package agent
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func boolPtr(b bool) *bool { return &b }
+
+func int64Ptr(i int64) *int64 { return &i }
+
+// hardenedPod é um pod sem nenhum finding.
+func hardenedPod(namespace, name string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: corev1.PodSpec{
+			AutomountServiceAccountToken: boolPtr(false),
+			SecurityContext: &corev1.PodSecurityContext{
+				RunAsNonRoot:   boolPtr(true),
+				SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+			},
+			Containers: []corev1.Container{{Name: "app", Image: "app:1"}},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+func TestBuildSecurityReport(t *testing.T) {
+	// Arrange
+	risky := hardenedPod("kube-system", "node-agent")
+	risky.OwnerReferences = ownedBy("DaemonSet", "node-agent")
+	risky.Spec.HostNetwork, risky.Spec.HostPID, risky.Spec.HostIPC = true, true, true
+	risky.Spec.AutomountServiceAccountToken = nil
+	risky.Spec.Volumes = []corev1.Volume{{Name: "root", VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/"}}}}
+	risky.Spec.SecurityContext.SeccompProfile = nil
+	risky.Spec.InitContainers = []corev1.Container{{
+		Name:            "setup",
+		SecurityContext: &corev1.SecurityContext{RunAsUser: int64Ptr(0), SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}},
+	}}
+	risky.Spec.Containers[0].SecurityContext = &corev1.SecurityContext{
+		Privileged:     boolPtr(true),
+		Capabilities:   &corev1.Capabilities{Add: []corev1.Capability{"NET_ADMIN", "SYS_TIME"}},
+		SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeUnconfined},
+	}
+	done := hardenedPod("default", "job")
+	done.Spec.HostNetwork = true
+	done.Status.Phase = corev1.PodSucceeded
+	pods := []*corev1.Pod{hardenedPod("default", "web"), risky, done}
+
+	// Act
+	report := buildSecurityReport(pods, nil, func(*corev1.Pod) bool { return true })
+
+	// Assert: terminal pods are not analyzed, the hardened pod has no findings
+	if report.TotalPods != 2 || report.PodsWithFindings != 1 {
+		t.Errorf("TotalPods/PodsWithFindings = %d/%d, want 2/1", report.TotalPods, report.PodsWithFindings)
+	}
+	want := []SecurityFinding{
+		{Check: CheckAddedCapabilities, Severity: SeverityMedium, Container: "app", Detail: "NET_ADMIN,SYS_TIME"},
+		{Check: CheckServiceAccountToken, Severity: SeverityLow},
+		{Check: CheckHostIPC, Severity: SeverityMedium},
+		{Check: CheckHostNetwork, Severity: SeverityHigh},
+		{Check: CheckHostPID, Severity: SeverityHigh},
+		{Check: CheckHostPath, Severity: SeverityHigh, Detail: "/"},
+		{Check: CheckPrivileged, Severity: SeverityHigh, Container: "app"},
+		{Check: CheckRunAsRoot, Severity: SeverityMedium, Container: "setup", Detail: "runAsUser=0"},
+		{Check: CheckSeccomp, Severity: SeverityLow, Container: "app", Detail: "Unconfined"},
+	}
+	if len(report.Findings) != len(want) {
+		t.Fatalf("findings = %+v, want %d", report.Findings, len(want))
+	}
+	for i, w := range want {
+		got := report.Findings[i]
+		if got.Check != w.Check || got.Severity != w.Severity || got.Container != w.Container || got.Detail != w.Detail {
+			t.Errorf("findings[%d] = %+v, want %+v", i, got, w)
+		}
+		if got.Namespace != "kube-system" || got.Pod != "node-agent" || got.Workload == nil || got.Workload.Kind != "DaemonSet" {
+			t.Errorf("findings[%d] pod/workload = %s/%s %+v", i, got.Namespace, got.Pod, got.Workload)
+		}
+	}
+	if report.Checks[CheckHostPID] != 1 || report.Severities[SeverityHigh] != 4 {
+		t.Errorf("Checks = %v, Severities = %v", report.Checks, report.Severities)
+	}
+}
+
+func TestBuildSecurityReportScansEphemeralContainers(t *testing.T) {
+	// Arrange: kubectl debug --profile=sysadmin on a hardened pod
+	pod := hardenedPod("default", "web")
+	pod.Spec.EphemeralContainers = []corev1.EphemeralContainer{{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+			Name: "debugger-x7k2",
+			SecurityContext: &corev1.SecurityContext{
+				Privileged:   boolPtr(true),
+				Capabilities: &corev1.Capabilities{Add: []corev1.Capability{"SYS_PTRACE"}},
+			},
+		},
+		TargetContainerName: "app",
+	}}
+
+	// Act
+	report := buildSecurityReport([]*corev1.Pod{pod}, nil, func(*corev1.Pod) bool { return false })
+
+	// Assert
+	want := map[string]string{CheckAddedCapabilities: "SYS_PTRACE", CheckPrivileged: ""}
+	if len(report.Findings) != len(want) {
+		t.Fatalf("findings = %+v, want %v", report.Findings, want)
+	}
+	for _, f := range report.Findings {
+		if detail, ok := want[f.Check]; !ok || f.Container != "debugger-x7k2" || f.Detail != detail {
+			t.Errorf("finding = %+v, want one of %v on debugger-x7k2", f, want)
+		}
+	}
+}
+
+func TestRunsAsRoot(t *testing.T) {
+	cases := []struct {
+		name   string
+		pod    corev1.PodSecurityContext
+		c      corev1.SecurityContext
+		want   bool
+		detail string
+	}{
+		{name: "image user", want: true, detail: "runAsNonRoot e runAsUser não definidos"},
+		{name: "pod runAsNonRoot", pod: corev1.PodSecurityContext{RunAsNonRoot: boolPtr(true)}},
+		{name: "container overrides pod", pod: corev1.PodSecurityContext{RunAsNonRoot: boolPtr(true)}, c: corev1.SecurityContext{RunAsNonRoot: boolPtr(false)}, want: true, detail: "runAsNonRoot=false"},
+		{name: "non-root uid", c: corev1.SecurityContext{RunAsUser: int64Ptr(1000)}},
+		{name: "uid 0", pod: corev1.PodSecurityContext{RunAsUser: int64Ptr(0), RunAsNonRoot: boolPtr(true)}, want: true, detail: "runAsUser=0"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			detail, got := runsAsRoot(&tc.pod, &tc.c)
+
+			// Assert
+			if got != tc.want || detail != tc.detail {
+				t.Errorf("runsAsRoot = %q, %v, want %q, %v", detail, got, tc.detail, tc.want)
+			}
+		})
+	}
+}
+
+func TestSecurityCollectorUsesServiceAccountAutomount(t *testing.T) {
+	// Arrange: the pod does not set automount, its service account disables it
+	pod := hardenedPod("default", "web")
+	pod.Spec.AutomountServiceAccountToken = nil
+	pod.Spec.ServiceAccountName = "web"
+	objs := []runtime.Object{
+		pod,
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}, AutomountServiceAccountToken: boolPtr(false)},
+	}
+
+	// Act
+	report := collect(t, &securityCollector{}, objs...).(*SecurityReport)
+
+	// Assert
+	if len(report.Findings) != 0 {
+		t.Errorf("findings = %+v, want none", report.Findings)
+	}
+
+	// Act: without the service account the token is mounted (API default)
+	report = collect(t, &securityCollector{}, pod).(*SecurityReport)
+
+	// Assert
+	if len(report.Findings) != 1 || report.Findings[0].Check != CheckServiceAccountToken {
+		t.Errorf("findings = %+v, want %s", report.Findings, CheckServiceAccountToken)
+	}
+}
@@ -0,0 +1,280 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	applycorev1 "k8s.io/client-go/applyconfigurations/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"github.com/totvs/addon-framework-basic/pkg/apply"
+)
+
+const (
+	// MaxObjectBytes é o tamanho máximo do payload gravado em um único ConfigMap.
+	// O etcd limita cada objeto a ~1 MiB; a margem cobre metadata, labels e anotações.
+	MaxObjectBytes = 900 * 1024
+
+	// MaxShards é o número máximo de shards de um relatório (~14 MiB com MaxObjectBytes).
+	// Os nomes dos shards entram nos resourceNames da Role do agent no hub (ver ReportObjects).
+	MaxShards = 16
+
+	// ReportKey é a chave do ConfigMap com o relatório (ou com um pedaço dele, nos shards).
+	ReportKey = "report"
+
+	// ManifestKey é a chave do ConfigMap principal que lista os shards.
+	// Só existe quando o relatório não coube em um único ConfigMap.
+	ManifestKey = "manifest"
+
+	// ReportLabel marca os ConfigMaps de relatório no hub.
+	// O valor é o nome do ConfigMap principal (ex: pod-report), inclusive nos shards.
+	ReportLabel = "basic-addon.open-cluster-management.io/report"
+)
+
+// ShardManifest é gravado no ConfigMap principal quando o relatório é dividido em shards.
+// Consumidores no hub concatenam os shards na ordem de Shards e validam Size e Checksum.
+type ShardManifest struct {
+	Shards   []string `json:"shards"`   // Nomes dos ConfigMaps, na ordem do payload
+	Size     int      `json:"size"`     // Tamanho total do payload em bytes
+	Checksum string   `json:"checksum"` // sha256 do payload completo (hex)
+}
+
+// shardName retorna o nome do i-ésimo shard (ex: pod-report-0).
+func shardName(name string, i int) string {
+	return fmt.Sprintf("%s-%d", name, i)
+}
+
+// splitPayload divide o payload em pedaços de até max bytes.
+// Para payload texto (text=true) o corte respeita o limite das runas UTF-8,
+// pois ConfigMap.Data só aceita strings válidas; BinaryData aceita qualquer corte.
+func splitPayload(data []byte, max int, text bool) [][]byte {
+	if len(data) <= max {
+		return [][]byte{data}
+	}
+
+	var chunks [][]byte
+	for len(data) > max {
+		end := max
+		for text && end > 0 && !utf8.RuneStart(data[end]) {
+			end--
+		}
+		if end == 0 {
+			end = max
+		}
+		chunks = append(chunks, data[:end])
+		data = data[end:]
+	}
+	if len(data) > 0 {
+		chunks = append(chunks, data)
+	}
+	return chunks
+}
+
+// publishConfigMap grava o payload no ConfigMap name do namespace do cluster no hub.
+// O payload já vem codificado (ver encodeReport); encoding define se ele vai em Data
+// (json) ou BinaryData (gzip) e é declarado na anotação EncodingAnnotation.
+// extra são anotações só do ConfigMap principal (ex: ContentHashAnnotation).
+//
+// Fluxo:
+// 1. Se o payload cabe em maxBytes, grava tudo na chave "report" (formato original)
+// 2. Senão grava os shards name-0..name-N-1 e depois o ConfigMap principal com o manifest
+// 3. Remove os shards do manifest anterior que não fazem mais parte do relatório
+//
+// Todas as escritas usam server-side apply (ver applyConfigMap). Os shards são gravados
+// antes do manifest, então um leitor nunca vê um manifest apontando para shards inexistentes.
+// Leituras concorrentes podem misturar versões; o Checksum do manifest detecta esse caso
+// (ver ReadReport).
+func publishConfigMap(ctx context.Context, hubClient kubernetes.Interface, namespace, name string, payload []byte, encoding string, extra map[string]string, maxBytes int) error {
+	client := hubClient.CoreV1().ConfigMaps(namespace)
+	labels := map[string]string{ReportLabel: name}
+	annotations := map[string]string{
+		EncodingAnnotation:      encoding,
+		SchemaVersionAnnotation: SchemaVersion,
+	}
+
+	// Manifest anterior: define quais shards podem ter ficado órfãos
+	var previous ShardManifest
+	if existing, err := client.Get(ctx, name, metav1.GetOptions{}); err == nil {
+		if raw, ok := existing.Data[ManifestKey]; ok {
+			if err := json.Unmarshal([]byte(raw), &previous); err != nil {
+				klog.Warningf("Manifest inválido em %s/%s: %v", namespace, name, err)
+			}
+		}
+	} else if !errors.IsNotFound(err) {
+		return err
+	}
+
+	head := applycorev1.ConfigMap(name, namespace).WithLabels(labels).WithAnnotations(annotations)
+	if len(extra) > 0 {
+		head.WithAnnotations(extra)
+	}
+
+	chunks := splitPayload(payload, maxBytes, encoding != EncodingGzip)
+	current := map[string]bool{}
+	if len(chunks) == 1 {
+		setPayload(head, payload, encoding)
+	} else {
+		if len(chunks) > MaxShards {
+			return permanent(fmt.Errorf("relatório %s com %d bytes precisa de %d shards (máximo %d)", name, len(payload), len(chunks), MaxShards))
+		}
+		sum := sha256.Sum256(payload)
+		manifest := ShardManifest{Size: len(payload), Checksum: hex.EncodeToString(sum[:])}
+		for i, chunk := range chunks {
+			shard := applycorev1.ConfigMap(shardName(name, i), namespace).WithLabels(labels).WithAnnotations(annotations)
+			setPayload(shard, chunk, encoding)
+			if err := applyConfigMap(ctx, hubClient, shard); err != nil {
+				return fmt.Errorf("falha ao gravar shard %s: %w", *shard.Name, err)
+			}
+			manifest.Shards = append(manifest.Shards, *shard.Name)
+			current[*shard.Name] = true
+		}
+		data, err := json.Marshal(manifest)
+		if err != nil {
+			return err
+		}
+		head.WithData(map[string]string{ManifestKey: string(data)})
+	}
+
+	if err := applyConfigMap(ctx, hubClient, head); err != nil {
+		return err
+	}
+
+	// Garbage collection dos shards que sobraram (ex: cluster encolheu)
+	for _, shard := range previous.Shards {
+		if current[shard] {
+			continue
+		}
+		if err := client.Delete(ctx, shard, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("falha ao remover shard %s: %w", shard, err)
+		}
+		klog.V(2).Infof("Shard removido: %s/%s", namespace, shard)
+	}
+	return nil
+}
+
+// setPayload grava o payload na chave "report": BinaryData para gzip, Data para json.
+// Como o apply só declara a chave do encoding atual, a outra é removida ao trocar de encoding.
+func setPayload(cm *applycorev1.ConfigMapApplyConfiguration, payload []byte, encoding string) {
+	if encoding == EncodingGzip {
+		cm.WithBinaryData(map[string][]byte{ReportKey: payload})
+		return
+	}
+	cm.WithData(map[string]string{ReportKey: string(payload)})
+}
+
+// getPayload lê a chave "report" de Data ou BinaryData.
+func getPayload(cm *corev1.ConfigMap) []byte {
+	if data, ok := cm.BinaryData[ReportKey]; ok {
+		return data
+	}
+	return []byte(cm.Data[ReportKey])
+}
+
+// applyConfigMap grava o ConfigMap com server-side apply (field manager FieldManager).
+// Labels e anotações de outros field managers são preservadas; conflitos viram *apply.ConflictError.
+func applyConfigMap(ctx context.Context, hubClient kubernetes.Interface, cm *applycorev1.ConfigMapApplyConfiguration) error {
+	client := hubClient.CoreV1().ConfigMaps(*cm.Namespace)
+	return apply.Do(ctx, apply.Object{
+		Kind:         "ConfigMap",
+		Namespace:    *cm.Namespace,
+		Name:         *cm.Name,
+		FieldManager: FieldManager,
+		Get: func(ctx context.Context) (runtime.Object, error) {
+			return client.Get(ctx, *cm.Name, metav1.GetOptions{})
+		},
+		Patch: func(ctx context.Context, patch []byte) error {
+			_, err := client.Patch(ctx, *cm.Name, types.JSONPatchType, patch, metav1.PatchOptions{})
+			return err
+		},
+	}, func(ctx context.Context) error {
+		_, err := client.Apply(ctx, cm, metav1.ApplyOptions{FieldManager: FieldManager})
+		return err
+	})
+}
+
+// deleteConfigMap remove o ConfigMap name e os shards do seu manifest.
+func deleteConfigMap(ctx context.Context, hubClient kubernetes.Interface, namespace, name string) error {
+	client := hubClient.CoreV1().ConfigMaps(namespace)
+	head, err := client.Get(ctx, name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var manifest ShardManifest
+	if raw, ok := head.Data[ManifestKey]; ok {
+		if err := json.Unmarshal([]byte(raw), &manifest); err != nil {
+			klog.Warningf("Manifest inválido em %s/%s: %v", namespace, name, err)
+		}
+	}
+	// Principal primeiro: leitores não encontram um manifest com shards já removidos
+	if err := client.Delete(ctx, name, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	for _, shard := range manifest.Shards {
+		if err := client.Delete(ctx, shard, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("falha ao remover shard %s: %w", shard, err)
+		}
+	}
+	return nil
+}
+
+// readPayload lê o payload do ConfigMap name, remontando os shards quando houver manifest.
+// Retorna o payload ainda codificado e o encoding declarado na anotação.
+func readPayload(ctx context.Context, hubClient kubernetes.Interface, namespace, name string) ([]byte, string, error) {
+	client := hubClient.CoreV1().ConfigMaps(namespace)
+	head, err := client.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, "", err
+	}
+	encoding := head.Annotations[EncodingAnnotation]
+
+	raw, ok := head.Data[ManifestKey]
+	if !ok {
+		return getPayload(head), encoding, nil
+	}
+
+	var manifest ShardManifest
+	if err := json.Unmarshal([]byte(raw), &manifest); err != nil {
+		return nil, "", fmt.Errorf("manifest inválido em %s/%s: %w", namespace, name, err)
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(manifest.Size)
+	for _, shardName := range manifest.Shards {
+		shard, err := client.Get(ctx, shardName, metav1.GetOptions{})
+		if err != nil {
+			return nil, "", fmt.Errorf("falha ao ler shard %s: %w", shardName, err)
+		}
+		buf.Write(getPayload(shard))
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+	if buf.Len() != manifest.Size || hex.EncodeToString(sum[:]) != manifest.Checksum {
+		// Shards de outra versão do relatório (escrita em andamento): o chamador deve tentar de novo
+		return nil, "", fmt.Errorf("shards de %s/%s não conferem com o manifest", namespace, name)
+	}
+	return buf.Bytes(), encoding, nil
+}
+
+// ReadReport lê o PodReport publicado pelo agent no namespace do cluster no hub.
+// Funciona com o ConfigMap único ou dividido em shards, em json ou gzip.
+func ReadReport(ctx context.Context, hubClient kubernetes.Interface, clusterName string) (*PodReport, error) {
+	data, encoding, err := readPayload(ctx, hubClient, clusterName, ConfigMapName)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeReport(data, encoding)
+}
@@ -0,0 +1,205 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"unicode/utf8"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/totvs/addon-framework-basic/pkg/apply"
+)
+
+func TestSplitPayloadKeepsRunesIntact(t *testing.T) {
+	// Arrange: "ç" takes 2 bytes and lands on a chunk boundary
+	data := []byte("abçdeçfg")
+
+	// Act
+	chunks := splitPayload(data, 3, true)
+
+	// Assert
+	var joined []byte
+	for _, c := range chunks {
+		if len(c) > 3 {
+			t.Errorf("chunk %q has %d bytes, want <= 3", c, len(c))
+		}
+		if !utf8.Valid(c) {
+			t.Errorf("chunk %q is not valid UTF-8", c)
+		}
+		joined = append(joined, c...)
+	}
+	if string(joined) != string(data) {
+		t.Errorf("joined = %q, want %q", joined, data)
+	}
+}
+
+func TestPublishConfigMapShardsAndGarbageCollects(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	hubClient := fake.NewClientset()
+	big := testReport(200)
+	payload, _ := json.Marshal(big)
+	maxBytes := len(payload) / 3 // forces at least 4 shards
+
+	// Act
+	if err := publishConfigMap(ctx, hubClient, "cluster1", ConfigMapName, payload, EncodingJSON, nil, maxBytes); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	// Assert: manifest on the head ConfigMap and the report reassembled
+	head, err := hubClient.CoreV1().ConfigMaps("cluster1").Get(ctx, ConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get head: %v", err)
+	}
+	var manifest ShardManifest
+	if err := json.Unmarshal([]byte(head.Data[ManifestKey]), &manifest); err != nil {
+		t.Fatalf("manifest: %v", err)
+	}
+	if len(manifest.Shards) < 4 {
+		t.Fatalf("len(Shards) = %d, want >= 4", len(manifest.Shards))
+	}
+	if _, ok := head.Data[ReportKey]; ok {
+		t.Errorf("head should not carry %q when sharded", ReportKey)
+	}
+	report, err := ReadReport(ctx, hubClient, "cluster1")
+	if err != nil {
+		t.Fatalf("ReadReport: %v", err)
+	}
+	if report.TotalPods != 200 || len(report.Pods) != 200 {
+		t.Errorf("TotalPods = %d, len(Pods) = %d, want 200", report.TotalPods, len(report.Pods))
+	}
+
+	// Act: the cluster shrank and the report fits a single ConfigMap again
+	small, _ := json.Marshal(testReport(1))
+	if err := publishConfigMap(ctx, hubClient, "cluster1", ConfigMapName, small, EncodingJSON, nil, maxBytes); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	// Assert: stale shards removed
+	for _, name := range manifest.Shards {
+		if _, err := hubClient.CoreV1().ConfigMaps("cluster1").Get(ctx, name, metav1.GetOptions{}); err == nil {
+			t.Errorf("shard %s should have been garbage-collected", name)
+		}
+	}
+	report, err = ReadReport(ctx, hubClient, "cluster1")
+	if err != nil {
+		t.Fatalf("ReadReport: %v", err)
+	}
+	if report.TotalPods != 1 {
+		t.Errorf("TotalPods = %d, want 1", report.TotalPods)
+	}
+}
+
+func TestReadReportDetectsMismatchedShards(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	hubClient := fake.NewClientset()
+	payload, _ := json.Marshal(testReport(50))
+	if err := publishConfigMap(ctx, hubClient, "cluster1", ConfigMapName, payload, EncodingJSON, nil, len(payload)/2); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	// Act: simulate a shard overwritten by another report version
+	shard, _ := hubClient.CoreV1().ConfigMaps("cluster1").Get(ctx, shardName(ConfigMapName, 0), metav1.GetOptions{})
+	shard.Data[ReportKey] = "{}"
+	if _, err := hubClient.CoreV1().ConfigMaps("cluster1").Update(ctx, shard, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("update shard: %v", err)
+	}
+	_, err := ReadReport(ctx, hubClient, "cluster1")
+
+	// Assert
+	if err == nil {
+		t.Error("ReadReport should fail when shards do not match the manifest")
+	}
+}
+
+func testReport(pods int) PodReport {
+	report := PodReport{APIVersion: SchemaVersion, ClusterName: "cluster1", TotalPods: pods}
+	for i := 0; i < pods; i++ {
+		report.Pods = append(report.Pods, PodInfo{
+			Name:      fmt.Sprintf("pod-%d", i),
+			Namespace: "default",
+			Status:    "Running",
+		})
+	}
+	return report
+}
+
+func TestPublishConfigMapPreservesForeignLabels(t *testing.T) {
+	// Arrange: report labelled by another tool after the first publish
+	ctx := context.Background()
+	hubClient := fake.NewClientset()
+	payload, _ := json.Marshal(testReport(1))
+	if err := publishConfigMap(ctx, hubClient, "cluster1", ConfigMapName, payload, EncodingJSON, nil, MaxObjectBytes); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	patch := []byte(`{"metadata":{"labels":{"team":"platform"}}}`)
+	if _, err := hubClient.CoreV1().ConfigMaps("cluster1").Patch(ctx, ConfigMapName, types.MergePatchType, patch,
+		metav1.PatchOptions{FieldManager: "gitops"}); err != nil {
+		t.Fatalf("label: %v", err)
+	}
+
+	// Act
+	payload, _ = json.Marshal(testReport(2))
+	err := publishConfigMap(ctx, hubClient, "cluster1", ConfigMapName, payload, EncodingJSON, nil, MaxObjectBytes)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	head, _ := hubClient.CoreV1().ConfigMaps("cluster1").Get(ctx, ConfigMapName, metav1.GetOptions{})
+	if head.Labels["team"] != "platform" {
+		t.Errorf("labels = %v, foreign label was removed", head.Labels)
+	}
+	report, err := ReadReport(ctx, hubClient, "cluster1")
+	if err != nil || report.TotalPods != 2 {
+		t.Errorf("ReadReport = %+v, %v; want 2 pods", report, err)
+	}
+}
+
+func TestPublishConfigMapConflict(t *testing.T) {
+	// Arrange: another field manager owns the report key
+	ctx := context.Background()
+	hubClient := fake.NewClientset()
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: ConfigMapName, Namespace: "cluster1"},
+		Data:       map[string]string{ReportKey: "{}"},
+	}
+	if _, err := hubClient.CoreV1().ConfigMaps("cluster1").Create(ctx, cm, metav1.CreateOptions{FieldManager: "kubectl-edit"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	// Act
+	payload, _ := json.Marshal(testReport(1))
+	err := publishConfigMap(ctx, hubClient, "cluster1", ConfigMapName, payload, EncodingJSON, nil, MaxObjectBytes)
+
+	// Assert
+	if !apply.IsConflict(err) {
+		t.Errorf("err = %v, want *apply.ConflictError", err)
+	}
+}
+
+func TestPublishConfigMapRejectsTooManyShards(t *testing.T) {
+	// Arrange: the payload needs more shards than the hub Role grants by name
+	ctx := context.Background()
+	hubClient := fake.NewClientset()
+	payload, _ := json.Marshal(testReport(200))
+	maxBytes := len(payload)/(MaxShards+1) - 1
+
+	// Act
+	err := publishConfigMap(ctx, hubClient, "cluster1", ConfigMapName, payload, EncodingJSON, nil, maxBytes)
+
+	// Assert: nothing is written
+	if err == nil {
+		t.Fatal("publish succeeded, want an error above MaxShards")
+	}
+	list, _ := hubClient.CoreV1().ConfigMaps("cluster1").List(ctx, metav1.ListOptions{})
+	if len(list.Items) != 0 {
+		t.Errorf("len(ConfigMaps) = %d, want 0", len(list.Items))
+	}
+}
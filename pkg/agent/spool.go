@@ -0,0 +1,298 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// SpoolSize é o número padrão de relatórios guardados no spool enquanto o hub está fora.
+	SpoolSize = 10
+
+	// SpoolLabel marca os ConfigMaps do spool no namespace do addon no spoke.
+	SpoolLabel = "basic-addon.open-cluster-management.io/spool"
+
+	// spoolPrefix é o prefixo dos ConfigMaps do spool: pod-report-spool-<seq>.
+	spoolPrefix = ConfigMapName + "-spool-"
+)
+
+// spoolBackoff é o backoff exponencial do replay: 5s, 10s, 20s... até 5m.
+var spoolBackoff = wait.Backoff{
+	Duration: 5 * time.Second,
+	Factor:   2,
+	Jitter:   0.1,
+	Steps:    math.MaxInt32,
+	Cap:      5 * time.Minute,
+}
+
+// spoolEntry é um relatório não enviado. name vazio indica que ele só está em memória
+// (falha ao gravar o ConfigMap no spoke).
+type spoolEntry struct {
+	seq    int
+	name   string
+	report *PodReport
+}
+
+// spoolPublisher guarda no spoke os relatórios que não chegaram ao hub.
+//
+// Fluxo:
+// 1. Publish tenta gravar no hub; se o hub está inacessível, o relatório vai para o spool
+// 2. O spool é persistido em ConfigMaps do namespace do addon no spoke (sobrevive a restarts)
+// 3. Com o spool pendente, novos relatórios entram no fim da fila (a ordem é preservada)
+// 4. Replay envia a fila em ordem, com backoff exponencial entre as tentativas (ver retryAfter)
+//
+// Só erros transitórios (ver retriable) vão para o spool; conflitos, erros de permissão e
+// erros permanentes do próprio agent (ver permanentError) são devolvidos ao chamador, já que o
+// replay não resolveria. No replay eles descartam a entrada, para não travar a fila.
+type spoolPublisher struct {
+	reportPublisher
+	client    kubernetes.Interface // cliente do spoke
+	namespace string               // namespace do addon no spoke
+	max       int
+	now       func() time.Time
+
+	entries     []spoolEntry
+	nextSeq     int // sequência da próxima entrada (não reutiliza nomes de ConfigMaps)
+	backoff     wait.Backoff
+	nextAttempt time.Time
+}
+
+// newSpoolPublisher envolve publisher com o spool e carrega os relatórios pendentes do spoke.
+func newSpoolPublisher(ctx context.Context, publisher reportPublisher, spokeClient kubernetes.Interface, namespace string, max int) (*spoolPublisher, error) {
+	s := &spoolPublisher{
+		reportPublisher: publisher,
+		client:          spokeClient,
+		namespace:       namespace,
+		max:             max,
+		now:             time.Now,
+		backoff:         spoolBackoff,
+	}
+	if err := s.load(ctx); err != nil {
+		return nil, fmt.Errorf("falha ao carregar spool: %w", err)
+	}
+	if len(s.entries) > 0 {
+		klog.Infof("Spool com %d relatórios pendentes", len(s.entries))
+	}
+	return s, nil
+}
+
+// Publish grava o relatório no hub ou, se o hub está inacessível, no spool.
+func (s *spoolPublisher) Publish(ctx context.Context, report *PodReport) error {
+	if len(s.entries) == 0 {
+		err := s.reportPublisher.Publish(ctx, report)
+		if err == nil || !retriable(err) {
+			return err
+		}
+		klog.Warningf("Hub inacessível, relatório guardado no spool: %v", err)
+		s.nextAttempt = s.now().Add(s.backoff.Step())
+	}
+	s.add(ctx, report)
+
+	// O relatório já está no spool: falhas do replay só adiam o envio
+	if !s.now().Before(s.nextAttempt) {
+		if err := s.Replay(ctx); err != nil {
+			klog.Warningf("%v", err)
+		}
+	}
+	return nil
+}
+
+// PublishedHash considera o último relatório do spool como publicado: ele será enviado no replay.
+func (s *spoolPublisher) PublishedHash(ctx context.Context) (string, error) {
+	if n := len(s.entries); n > 0 {
+		return reportHash(s.entries[n-1].report), nil
+	}
+	return s.reportPublisher.PublishedHash(ctx)
+}
+
+// Heartbeat é ignorado enquanto há relatórios no spool (o hub está inacessível).
+func (s *spoolPublisher) Heartbeat(ctx context.Context, now time.Time) error {
+	if len(s.entries) > 0 {
+		return nil
+	}
+	return s.reportPublisher.Heartbeat(ctx, now)
+}
+
+// Replay envia os relatórios do spool em ordem. Para no primeiro erro e agenda a
+// próxima tentativa com backoff exponencial; o backoff volta ao início quando a fila esvazia.
+func (s *spoolPublisher) Replay(ctx context.Context) error {
+	for len(s.entries) > 0 {
+		entry := s.entries[0]
+		if err := s.reportPublisher.Publish(ctx, entry.report); err != nil {
+			if !retriable(err) {
+				// O hub recusa o relatório (ex: conflito) ou ele não pode ser gravado (ex: shards
+				// demais): descarta para não travar a fila
+				klog.Errorf("Relatório %d do spool descartado: %v", entry.seq, err)
+				s.remove(ctx, entry)
+				continue
+			}
+			delay := s.backoff.Step()
+			s.nextAttempt = s.now().Add(delay)
+			return fmt.Errorf("replay do spool falhou (%d pendentes, nova tentativa em %s): %w", len(s.entries), delay, err)
+		}
+		s.remove(ctx, entry)
+		klog.Infof("Relatório %d do spool enviado (%d pendentes)", entry.seq, len(s.entries))
+	}
+	s.backoff = spoolBackoff
+	s.nextAttempt = time.Time{}
+	return nil
+}
+
+// replayTimer devolve o timer da próxima tentativa de replay (nil sem spool ou sem pendentes).
+func (s *spoolPublisher) replayTimer() <-chan time.Time {
+	if s == nil {
+		return nil
+	}
+	delay, pending := s.retryAfter()
+	if !pending {
+		return nil
+	}
+	return time.After(delay)
+}
+
+// retryAfter indica se há relatórios pendentes e quanto falta para a próxima tentativa de replay.
+func (s *spoolPublisher) retryAfter() (time.Duration, bool) {
+	if len(s.entries) == 0 {
+		return 0, false
+	}
+	delay := s.nextAttempt.Sub(s.now())
+	if delay < 0 {
+		delay = 0
+	}
+	return delay, true
+}
+
+// add coloca o relatório no fim da fila e descarta o mais antigo se passar de max.
+func (s *spoolPublisher) add(ctx context.Context, report *PodReport) {
+	seq := s.nextSeq
+	s.nextSeq++
+	entry := spoolEntry{seq: seq, report: report}
+
+	data, err := encodeReport(report, EncodingGzip)
+	if err == nil && len(data) > MaxObjectBytes {
+		err = fmt.Errorf("relatório comprimido com %d bytes", len(data))
+	}
+	if err == nil {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        spoolPrefix + strconv.Itoa(seq),
+				Namespace:   s.namespace,
+				Labels:      map[string]string{SpoolLabel: ConfigMapName},
+				Annotations: map[string]string{EncodingAnnotation: EncodingGzip, SchemaVersionAnnotation: SchemaVersion},
+			},
+			BinaryData: map[string][]byte{ReportKey: data},
+		}
+		_, err = s.client.CoreV1().ConfigMaps(s.namespace).Create(ctx, cm, metav1.CreateOptions{FieldManager: FieldManager})
+		if err == nil {
+			entry.name = cm.Name
+		}
+	}
+	if err != nil {
+		klog.Warningf("Relatório %d do spool mantido só em memória: %v", seq, err)
+	}
+	s.entries = append(s.entries, entry)
+
+	for len(s.entries) > s.max {
+		klog.Warningf("Spool cheio (%d), descartando o relatório mais antigo (%d)", s.max, s.entries[0].seq)
+		s.remove(ctx, s.entries[0])
+	}
+}
+
+// remove tira a entrada da fila e apaga seu ConfigMap.
+func (s *spoolPublisher) remove(ctx context.Context, entry spoolEntry) {
+	for i := range s.entries {
+		if s.entries[i].seq == entry.seq {
+			s.entries = append(s.entries[:i], s.entries[i+1:]...)
+			break
+		}
+	}
+	if entry.name == "" {
+		return
+	}
+	err := s.client.CoreV1().ConfigMaps(s.namespace).Delete(ctx, entry.name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		klog.Warningf("Falha ao remover %s do spool: %v", entry.name, err)
+	}
+}
+
+// load lê os ConfigMaps do spool, em ordem de sequência.
+// Entradas ilegíveis são apagadas: não há como enviá-las.
+func (s *spoolPublisher) load(ctx context.Context) error {
+	list, err := s.client.CoreV1().ConfigMaps(s.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: SpoolLabel + "=" + ConfigMapName,
+	})
+	if err != nil {
+		return err
+	}
+	for i := range list.Items {
+		cm := &list.Items[i]
+		seq, err := strconv.Atoi(strings.TrimPrefix(cm.Name, spoolPrefix))
+		var report *PodReport
+		if err == nil {
+			report, err = DecodeReport(getPayload(cm), cm.Annotations[EncodingAnnotation])
+		}
+		if err != nil {
+			klog.Warningf("Entrada inválida no spool %s: %v", cm.Name, err)
+			s.remove(ctx, spoolEntry{seq: -1, name: cm.Name})
+			continue
+		}
+		s.entries = append(s.entries, spoolEntry{seq: seq, name: cm.Name, report: report})
+	}
+	sort.Slice(s.entries, func(i, j int) bool { return s.entries[i].seq < s.entries[j].seq })
+	if n := len(s.entries); n > 0 {
+		s.nextSeq = s.entries[n-1].seq + 1
+	}
+	for len(s.entries) > s.max {
+		s.remove(ctx, s.entries[0])
+	}
+	return nil
+}
+
+// permanentError é uma falha do agent que nenhuma nova tentativa resolve: o relatório não
+// cabe em MaxShards ou não pôde ser codificado.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+
+func (e *permanentError) Unwrap() error { return e.err }
+
+// permanent marca err como permanentError.
+func permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// retriable indica se o erro é transitório (hub inacessível ou sobrecarregado): erros de rede
+// e de transporte ou respostas do apiserver de sobrecarga. O resto (permanentError, erros
+// locais) não vai para o spool.
+func retriable(err error) bool {
+	var perm *permanentError
+	if errors.As(err, &perm) {
+		return false
+	}
+	var status apierrors.APIStatus
+	if errors.As(err, &status) {
+		return apierrors.IsServerTimeout(err) || apierrors.IsTimeout(err) || apierrors.IsTooManyRequests(err) ||
+			apierrors.IsServiceUnavailable(err) || apierrors.IsInternalError(err) || apierrors.IsUnexpectedServerError(err)
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, context.DeadlineExceeded) || utilnet.IsConnectionRefused(err) || utilnet.IsConnectionReset(err)
+}
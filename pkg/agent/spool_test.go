@@ -0,0 +1,270 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// errHubDown is what client-go returns while the hub is unreachable.
+var errHubDown = &net.OpError{Op: "dial", Net: "tcp", Err: &os.SyscallError{Syscall: "connect", Err: syscall.ECONNREFUSED}}
+
+// flakyPublisher fails with err until it is cleared and records what reached the hub.
+type flakyPublisher struct {
+	recordingPublisher
+	err       error
+	rejected  map[int]error // per-report errors, by TotalPods
+	published []int         // TotalPods of each published report, in order
+}
+
+func (f *flakyPublisher) Publish(ctx context.Context, report *PodReport) error {
+	if f.err != nil {
+		return f.err
+	}
+	if err := f.rejected[report.TotalPods]; err != nil {
+		return err
+	}
+	f.published = append(f.published, report.TotalPods)
+	return f.recordingPublisher.Publish(ctx, report)
+}
+
+// newTestSpool builds a spool over a flaky hub with a controllable clock.
+func newTestSpool(t *testing.T, spokeClient *fake.Clientset, hub *flakyPublisher, max int, now *time.Time) *spoolPublisher {
+	t.Helper()
+	s, err := newSpoolPublisher(context.Background(), hub, spokeClient, "addon-ns", max)
+	if err != nil {
+		t.Fatalf("newSpoolPublisher: %v", err)
+	}
+	s.now = func() time.Time { return *now }
+	return s
+}
+
+func spoolConfigMaps(t *testing.T, spokeClient *fake.Clientset) int {
+	t.Helper()
+	list, err := spokeClient.CoreV1().ConfigMaps("addon-ns").List(context.Background(), metav1.ListOptions{LabelSelector: SpoolLabel})
+	if err != nil {
+		t.Fatalf("list spool: %v", err)
+	}
+	return len(list.Items)
+}
+
+func TestSpoolReplaysInOrderAfterOutage(t *testing.T) {
+	// Arrange: the hub is down
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	spokeClient := fake.NewClientset()
+	hub := &flakyPublisher{err: errHubDown}
+	s := newTestSpool(t, spokeClient, hub, 10, &now)
+
+	// Act: three syncs during the outage
+	for i := 1; i <= 3; i++ {
+		report := testReport(i)
+		if err := s.Publish(ctx, &report); err != nil {
+			t.Fatalf("publish %d: %v", i, err)
+		}
+		now = now.Add(time.Second)
+	}
+
+	// Assert: spooled on the spoke
+	if got := spoolConfigMaps(t, spokeClient); got != 3 {
+		t.Fatalf("spool ConfigMaps = %d, want 3", got)
+	}
+
+	// Act: the hub comes back and the backoff elapses
+	hub.err = nil
+	now = now.Add(time.Minute)
+	if err := s.Replay(ctx); err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+
+	// Assert
+	if len(hub.published) != 3 || hub.published[0] != 1 || hub.published[2] != 3 {
+		t.Errorf("published = %v, want [1 2 3]", hub.published)
+	}
+	if got := spoolConfigMaps(t, spokeClient); got != 0 {
+		t.Errorf("spool ConfigMaps = %d after replay, want 0", got)
+	}
+	if _, pending := s.retryAfter(); pending {
+		t.Error("spool should be empty")
+	}
+}
+
+func TestSpoolSurvivesRestart(t *testing.T) {
+	// Arrange: two reports spooled by a previous agent
+	ctx := context.Background()
+	now := time.Now()
+	spokeClient := fake.NewClientset()
+	hub := &flakyPublisher{err: errHubDown}
+	first := newTestSpool(t, spokeClient, hub, 10, &now)
+	for i := 1; i <= 2; i++ {
+		report := testReport(i)
+		_ = first.Publish(ctx, &report)
+	}
+
+	// Act: the agent restarts with the hub back
+	hub.err = nil
+	restarted := newTestSpool(t, spokeClient, hub, 10, &now)
+	report := testReport(3)
+	err := restarted.Publish(ctx, &report)
+
+	// Assert: the new report is queued behind the spooled ones and everything is sent in order
+	if err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if len(hub.published) != 3 || hub.published[0] != 1 || hub.published[2] != 3 {
+		t.Errorf("published = %v, want [1 2 3]", hub.published)
+	}
+}
+
+func TestSpoolDropsOldestWhenFull(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	now := time.Now()
+	spokeClient := fake.NewClientset()
+	hub := &flakyPublisher{err: errHubDown}
+	s := newTestSpool(t, spokeClient, hub, 2, &now)
+
+	// Act
+	for i := 1; i <= 4; i++ {
+		report := testReport(i)
+		_ = s.Publish(ctx, &report)
+	}
+
+	// Assert: only the two newest are kept
+	if got := spoolConfigMaps(t, spokeClient); got != 2 {
+		t.Errorf("spool ConfigMaps = %d, want 2", got)
+	}
+	hub.err = nil
+	now = now.Add(time.Hour)
+	_ = s.Replay(ctx)
+	if len(hub.published) != 2 || hub.published[0] != 3 || hub.published[1] != 4 {
+		t.Errorf("published = %v, want [3 4]", hub.published)
+	}
+}
+
+func TestSpoolBackoffGrows(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	now := time.Now()
+	hub := &flakyPublisher{err: errHubDown}
+	s := newTestSpool(t, fake.NewClientset(), hub, 10, &now)
+	report := testReport(1)
+	_ = s.Publish(ctx, &report)
+	first, _ := s.retryAfter()
+
+	// Act: the replay fails again
+	now = now.Add(first)
+	err := s.Replay(ctx)
+	second, _ := s.retryAfter()
+
+	// Assert: ~5s then ~10s (10% jitter)
+	if err == nil {
+		t.Fatal("replay should fail while the hub is down")
+	}
+	if first < 5*time.Second || first > 6*time.Second {
+		t.Errorf("first delay = %s, want ~5s", first)
+	}
+	if second < 10*time.Second || second > 12*time.Second {
+		t.Errorf("second delay = %s, want ~10s", second)
+	}
+}
+
+func TestSpoolReturnsPermanentErrors(t *testing.T) {
+	// Arrange: the hub answers, but refuses the write
+	now := time.Now()
+	spokeClient := fake.NewClientset()
+	hub := &flakyPublisher{err: apierrors.NewForbidden(schema.GroupResource{Resource: "configmaps"}, ConfigMapName, errors.New("denied"))}
+	s := newTestSpool(t, spokeClient, hub, 10, &now)
+	report := testReport(1)
+
+	// Act
+	err := s.Publish(context.Background(), &report)
+
+	// Assert
+	if !apierrors.IsForbidden(err) {
+		t.Errorf("err = %v, want forbidden", err)
+	}
+	if got := spoolConfigMaps(t, spokeClient); got != 0 {
+		t.Errorf("spool ConfigMaps = %d, permanent errors should not be spooled", got)
+	}
+}
+
+func TestSpoolDropsReportsThatCannotBeWritten(t *testing.T) {
+	// Arrange: three reports spooled during an outage; the first one needs too many shards
+	ctx := context.Background()
+	now := time.Now()
+	spokeClient := fake.NewClientset()
+	hub := &flakyPublisher{err: errHubDown, rejected: map[int]error{
+		1: permanent(fmt.Errorf("relatório pod-report precisa de %d shards (máximo %d)", MaxShards+1, MaxShards)),
+	}}
+	s := newTestSpool(t, spokeClient, hub, 10, &now)
+	for i := 1; i <= 3; i++ {
+		report := testReport(i)
+		_ = s.Publish(ctx, &report)
+	}
+
+	// Act: the hub comes back
+	hub.err = nil
+	now = now.Add(time.Hour)
+	err := s.Replay(ctx)
+
+	// Assert: the overflowing report is dropped and the queue drains behind it
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if len(hub.published) != 2 || hub.published[0] != 2 || hub.published[1] != 3 {
+		t.Errorf("published = %v, want [2 3]", hub.published)
+	}
+	if got := spoolConfigMaps(t, spokeClient); got != 0 {
+		t.Errorf("spool ConfigMaps = %d, want 0", got)
+	}
+
+	// Act: a new report goes straight to the hub
+	report := testReport(4)
+	err = s.Publish(ctx, &report)
+
+	// Assert
+	if err != nil || len(hub.published) != 3 || hub.published[2] != 4 {
+		t.Errorf("publish: err = %v, published = %v, want [2 3 4]", err, hub.published)
+	}
+}
+
+func TestRetriable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"connection refused", errHubDown, true},
+		{"connection reset", &os.SyscallError{Syscall: "read", Err: syscall.ECONNRESET}, true},
+		{"eof", fmt.Errorf("get: %w", io.EOF), true},
+		{"deadline", context.DeadlineExceeded, true},
+		{"unavailable", apierrors.NewServiceUnavailable("down"), true},
+		{"too many requests", apierrors.NewTooManyRequests("slow down", 1), true},
+		{"forbidden", apierrors.NewForbidden(schema.GroupResource{Resource: "configmaps"}, ConfigMapName, errors.New("denied")), false},
+		{"permanent", permanent(errors.New("shards")), false},
+		{"local", errors.New("json: unsupported value"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := retriable(tt.err)
+
+			// Assert
+			if got != tt.want {
+				t.Errorf("retriable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
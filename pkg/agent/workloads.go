@@ -0,0 +1,372 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	batchlisters "k8s.io/client-go/listers/batch/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+)
+
+// CollectorWorkloads é o collector do rollup de workloads (seção workloads-report no hub).
+const CollectorWorkloads = "workloads"
+
+// Estado do rollout de um workload (WorkloadInfo.Rollout).
+const (
+	RolloutComplete    = "Complete"    // todas as réplicas atualizadas e disponíveis (Job: concluído)
+	RolloutProgressing = "Progressing" // rollout em andamento (Job: rodando)
+	RolloutFailed      = "Failed"      // Deployment com ProgressDeadlineExceeded ou Job falho
+	RolloutPaused      = "Paused"      // Deployment pausado
+	RolloutSuspended   = "Suspended"   // Job suspenso
+)
+
+// WorkloadRef identifica o workload dono de um pod (no mesmo namespace do pod).
+type WorkloadRef struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+// WorkloadReport é a seção do collector workloads: o estado de rollout de cada workload.
+type WorkloadReport struct {
+	TotalWorkloads int            `json:"totalWorkloads"`
+	Workloads      []WorkloadInfo `json:"workloads"`
+}
+
+// WorkloadInfo contém as contagens de réplicas e o estado do rollout de um workload.
+// Em Jobs, Desired é spec.completions, Ready são os pods prontos, Available são os pods
+// concluídos com sucesso e Updated não se aplica (0).
+type WorkloadInfo struct {
+	Kind      string   `json:"kind"`
+	Namespace string   `json:"namespace"`
+	Name      string   `json:"name"`
+	Desired   int32    `json:"desired"`
+	Ready     int32    `json:"ready"`
+	Updated   int32    `json:"updated"`
+	Available int32    `json:"available"`
+	Pods      int      `json:"pods"` // Pods do workload, diretos ou pela cadeia de ownerReferences
+	Rollout   string   `json:"rollout"`
+	Images    []string `json:"images"`
+}
+
+// ownerResolver resolve o workload dono de um pod pela cadeia de ownerReferences:
+// Pod -> ReplicaSet -> Deployment e Pod -> Job -> CronJob. Outros donos (StatefulSet,
+// DaemonSet, ...) são o próprio dono direto.
+type ownerResolver struct {
+	replicaSets appslisters.ReplicaSetLister
+	jobs        batchlisters.JobLister
+}
+
+// newOwnerResolver registra na factory os informers de ReplicaSets e Jobs usados na resolução.
+func newOwnerResolver(factory informers.SharedInformerFactory) *ownerResolver {
+	r := &ownerResolver{
+		replicaSets: factory.Apps().V1().ReplicaSets().Lister(),
+		jobs:        factory.Batch().V1().Jobs().Lister(),
+	}
+	// Lister() sozinho não registra o informer na factory
+	factory.Apps().V1().ReplicaSets().Informer()
+	factory.Batch().V1().Jobs().Informer()
+	return r
+}
+
+// ownerRules são as permissões de leitura usadas pelo ownerResolver.
+func ownerRules() []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{
+		{APIGroups: []string{"apps"}, Resources: []string{"replicasets"}, Verbs: []string{"get", "list", "watch"}},
+		{APIGroups: []string{"batch"}, Resources: []string{"jobs"}, Verbs: []string{"get", "list", "watch"}},
+	}
+}
+
+// resolve devolve o workload dono do pod (nil se o pod não tem controller).
+// Se o intermediário (ReplicaSet ou Job) não está no cache, devolve ele mesmo.
+func (r *ownerResolver) resolve(pod *corev1.Pod) *WorkloadRef {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return nil
+	}
+	ref := &WorkloadRef{Kind: owner.Kind, Name: owner.Name}
+	if r == nil {
+		return ref
+	}
+
+	var parent *metav1.OwnerReference
+	switch owner.Kind {
+	case "ReplicaSet":
+		if rs, err := r.replicaSets.ReplicaSets(pod.Namespace).Get(owner.Name); err == nil {
+			parent = metav1.GetControllerOf(rs)
+		}
+	case "Job":
+		if job, err := r.jobs.Jobs(pod.Namespace).Get(owner.Name); err == nil {
+			parent = metav1.GetControllerOf(job)
+		}
+	}
+	if parent != nil {
+		ref = &WorkloadRef{Kind: parent.Kind, Name: parent.Name}
+	}
+	return ref
+}
+
+func init() {
+	RegisterCollector(CollectorWorkloads, func(o *AgentOptions) Collector { return &workloadsCollector{options: o, filters: o.filters()} })
+}
+
+// workloadsCollector monta o WorkloadReport a partir do cache de Deployments, StatefulSets,
+// DaemonSets, Jobs e pods.
+type workloadsCollector struct {
+	options      *AgentOptions
+	filters      *CollectionFilters
+	deployments  appslisters.DeploymentLister
+	statefulSets appslisters.StatefulSetLister
+	daemonSets   appslisters.DaemonSetLister
+	jobs         batchlisters.JobLister
+	pods         corelisters.PodLister
+	owners       *ownerResolver
+}
+
+// Name implementa Collector.
+func (c *workloadsCollector) Name() string { return CollectorWorkloads }
+
+// Rules implementa Collector.
+func (c *workloadsCollector) Rules() []rbacv1.PolicyRule {
+	return append([]rbacv1.PolicyRule{
+		{APIGroups: []string{"apps"}, Resources: []string{"deployments", "statefulsets", "daemonsets"}, Verbs: []string{"get", "list", "watch"}},
+		{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "list", "watch"}},
+	}, ownerRules()...)
+}
+
+// Register implementa Collector. Só mudanças nos workloads disparam o sync; os pods
+// entram só na contagem (mudanças de pods já mudam o status dos workloads).
+func (c *workloadsCollector) Register(factory informers.SharedInformerFactory, changed func()) error {
+	apps := factory.Apps().V1()
+	c.deployments = apps.Deployments().Lister()
+	c.statefulSets = apps.StatefulSets().Lister()
+	c.daemonSets = apps.DaemonSets().Lister()
+	c.jobs = factory.Batch().V1().Jobs().Lister()
+	_, c.pods = podInformer(factory, c.options)
+	c.owners = newOwnerResolver(factory)
+
+	if err := notifyOnChange(apps.Deployments().Informer(), changed); err != nil {
+		return err
+	}
+	if err := notifyOnChange(apps.StatefulSets().Informer(), changed); err != nil {
+		return err
+	}
+	if err := notifyOnChange(apps.DaemonSets().Informer(), changed); err != nil {
+		return err
+	}
+	return notifyOnChange(factory.Batch().V1().Jobs().Informer(), changed)
+}
+
+// Collect implementa Collector. Devolve *WorkloadReport, ordenado por kind/namespace/nome.
+func (c *workloadsCollector) Collect(context.Context) (interface{}, error) {
+	deployments, err := c.deployments.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("falha ao listar deployments: %w", err)
+	}
+	statefulSets, err := c.statefulSets.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("falha ao listar statefulsets: %w", err)
+	}
+	daemonSets, err := c.daemonSets.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("falha ao listar daemonsets: %w", err)
+	}
+	jobs, err := c.jobs.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("falha ao listar jobs: %w", err)
+	}
+	pods, err := c.pods.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("falha ao listar pods: %w", err)
+	}
+
+	deployments, statefulSets = filterNamespaces(c.filters, deployments), filterNamespaces(c.filters, statefulSets)
+	daemonSets, jobs = filterNamespaces(c.filters, daemonSets), filterNamespaces(c.filters, jobs)
+	pods = filterNamespaces(c.filters, pods)
+
+	var workloads []WorkloadInfo
+	for _, d := range deployments {
+		workloads = append(workloads, deploymentInfo(d))
+	}
+	for _, s := range statefulSets {
+		workloads = append(workloads, statefulSetInfo(s))
+	}
+	for _, d := range daemonSets {
+		workloads = append(workloads, daemonSetInfo(d))
+	}
+	for _, j := range jobs {
+		workloads = append(workloads, jobInfo(j))
+	}
+	return buildWorkloadReport(workloads, pods, c.owners), nil
+}
+
+// buildWorkloadReport ordena os workloads e conta os pods de cada um. O pod conta para o
+// controller direto e para o workload do topo da cadeia (ver ownerResolver): um pod de um Job
+// de CronJob conta no Job e no CronJob.
+func buildWorkloadReport(workloads []WorkloadInfo, pods []*corev1.Pod, owners *ownerResolver) *WorkloadReport {
+	counts := map[string]int{}
+	for _, p := range pods {
+		owner := metav1.GetControllerOf(p)
+		if owner == nil {
+			continue
+		}
+		counts[owner.Kind+"/"+p.Namespace+"/"+owner.Name]++
+		if ref := owners.resolve(p); ref.Kind != owner.Kind || ref.Name != owner.Name {
+			counts[ref.Kind+"/"+p.Namespace+"/"+ref.Name]++
+		}
+	}
+	for i := range workloads {
+		w := &workloads[i]
+		w.Pods = counts[w.Kind+"/"+w.Namespace+"/"+w.Name]
+	}
+	sort.Slice(workloads, func(i, j int) bool {
+		a, b := workloads[i], workloads[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	if workloads == nil {
+		workloads = []WorkloadInfo{}
+	}
+	return &WorkloadReport{TotalWorkloads: len(workloads), Workloads: workloads}
+}
+
+// deploymentInfo monta o WorkloadInfo de um Deployment.
+func deploymentInfo(d *appsv1.Deployment) WorkloadInfo {
+	w := WorkloadInfo{
+		Kind:      "Deployment",
+		Namespace: d.Namespace,
+		Name:      d.Name,
+		Desired:   replicas(d.Spec.Replicas),
+		Ready:     d.Status.ReadyReplicas,
+		Updated:   d.Status.UpdatedReplicas,
+		Available: d.Status.AvailableReplicas,
+		Images:    templateImages(d.Spec.Template.Spec),
+	}
+	switch {
+	case d.Spec.Paused:
+		w.Rollout = RolloutPaused
+	case deploymentProgressFailed(d):
+		w.Rollout = RolloutFailed
+	default:
+		w.Rollout = rolloutState(d.Generation, d.Status.ObservedGeneration, w.Desired, w.Updated, w.Available, d.Status.Replicas)
+	}
+	return w
+}
+
+// deploymentProgressFailed indica se a condição Progressing está False (ProgressDeadlineExceeded).
+func deploymentProgressFailed(d *appsv1.Deployment) bool {
+	for _, cond := range d.Status.Conditions {
+		if cond.Type == appsv1.DeploymentProgressing && cond.Status == corev1.ConditionFalse {
+			return true
+		}
+	}
+	return false
+}
+
+// statefulSetInfo monta o WorkloadInfo de um StatefulSet.
+func statefulSetInfo(s *appsv1.StatefulSet) WorkloadInfo {
+	w := WorkloadInfo{
+		Kind:      "StatefulSet",
+		Namespace: s.Namespace,
+		Name:      s.Name,
+		Desired:   replicas(s.Spec.Replicas),
+		Ready:     s.Status.ReadyReplicas,
+		Updated:   s.Status.UpdatedReplicas,
+		Available: s.Status.AvailableReplicas,
+		Images:    templateImages(s.Spec.Template.Spec),
+	}
+	w.Rollout = rolloutState(s.Generation, s.Status.ObservedGeneration, w.Desired, w.Updated, w.Available, s.Status.Replicas)
+	return w
+}
+
+// daemonSetInfo monta o WorkloadInfo de um DaemonSet (desired = nodes elegíveis).
+func daemonSetInfo(d *appsv1.DaemonSet) WorkloadInfo {
+	w := WorkloadInfo{
+		Kind:      "DaemonSet",
+		Namespace: d.Namespace,
+		Name:      d.Name,
+		Desired:   d.Status.DesiredNumberScheduled,
+		Ready:     d.Status.NumberReady,
+		Updated:   d.Status.UpdatedNumberScheduled,
+		Available: d.Status.NumberAvailable,
+		Images:    templateImages(d.Spec.Template.Spec),
+	}
+	w.Rollout = rolloutState(d.Generation, d.Status.ObservedGeneration, w.Desired, w.Updated, w.Available, d.Status.CurrentNumberScheduled)
+	return w
+}
+
+// jobInfo monta o WorkloadInfo de um Job.
+func jobInfo(j *batchv1.Job) WorkloadInfo {
+	w := WorkloadInfo{
+		Kind:      "Job",
+		Namespace: j.Namespace,
+		Name:      j.Name,
+		Desired:   replicas(j.Spec.Completions),
+		Available: j.Status.Succeeded,
+		Images:    templateImages(j.Spec.Template.Spec),
+		Rollout:   RolloutProgressing,
+	}
+	if j.Status.Ready != nil {
+		w.Ready = *j.Status.Ready
+	}
+	if j.Spec.Suspend != nil && *j.Spec.Suspend {
+		w.Rollout = RolloutSuspended
+	}
+	for _, cond := range j.Status.Conditions {
+		if cond.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch cond.Type {
+		case batchv1.JobComplete:
+			w.Rollout = RolloutComplete
+		case batchv1.JobFailed:
+			w.Rollout = RolloutFailed
+		}
+	}
+	return w
+}
+
+// rolloutState indica se o rollout terminou: o controller viu a última geração e todas as
+// réplicas desejadas estão atualizadas e disponíveis, sem réplicas antigas sobrando.
+func rolloutState(generation, observed int64, desired, updated, available, current int32) string {
+	if observed < generation || updated < desired || available < desired || current > desired {
+		return RolloutProgressing
+	}
+	return RolloutComplete
+}
+
+// replicas devolve o valor de um campo de réplicas opcional (padrão 1, como no apiserver).
+func replicas(n *int32) int32 {
+	if n == nil {
+		return 1
+	}
+	return *n
+}
+
+// templateImages lista as imagens do template (init containers primeiro), sem repetições.
+func templateImages(spec corev1.PodSpec) []string {
+	seen := map[string]bool{}
+	var images []string
+	for _, list := range [][]corev1.Container{spec.InitContainers, spec.Containers} {
+		for _, c := range list {
+			if !seen[c.Image] {
+				seen[c.Image] = true
+				images = append(images, c.Image)
+			}
+		}
+	}
+	return images
+}
@@ -0,0 +1,207 @@
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func ownedBy(kind, name string) []metav1.OwnerReference {
+	controller := true
+	return []metav1.OwnerReference{{Kind: kind, Name: name, Controller: &controller}}
+}
+
+func int32Ptr(n int32) *int32 { return &n }
+
+func podSpec(images ...string) corev1.PodTemplateSpec {
+	var containers []corev1.Container
+	for _, image := range images {
+		containers = append(containers, corev1.Container{Name: "c", Image: image})
+	}
+	return corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: containers}}
+}
+
+// collectWorkloads roda o collector de workloads sobre um fake clientset com objs.
+func collectWorkloads(t *testing.T, objs ...runtime.Object) *WorkloadReport {
+	t.Helper()
+	factory := informers.NewSharedInformerFactory(fake.NewClientset(objs...), 0)
+	defer factory.Shutdown()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel() // runs before Shutdown, which waits for the informers to stop
+	c := &workloadsCollector{}
+	if err := c.Register(factory, func() {}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	factory.Start(ctx.Done())
+	factory.WaitForCacheSync(ctx.Done())
+	out, err := c.Collect(ctx)
+	if err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	return out.(*WorkloadReport)
+}
+
+func TestWorkloadsCollector(t *testing.T) {
+	// Arrange
+	web := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Generation: 2},
+		Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(3), Template: podSpec("nginx:1.27", "envoy:1.31")},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: 2, Replicas: 4, ReadyReplicas: 3, UpdatedReplicas: 2, AvailableReplicas: 3,
+		},
+	}
+	rs := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "web-abc", Namespace: "default", OwnerReferences: ownedBy("Deployment", "web")}}
+	db := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "data", Generation: 1},
+		Spec:       appsv1.StatefulSetSpec{Replicas: int32Ptr(1), Template: podSpec("postgres:17")},
+		Status:     appsv1.StatefulSetStatus{ObservedGeneration: 1, Replicas: 1, ReadyReplicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1},
+	}
+	agent := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "kube-system"},
+		Spec:       appsv1.DaemonSetSpec{Template: podSpec("agent:1")},
+		Status:     appsv1.DaemonSetStatus{DesiredNumberScheduled: 2, CurrentNumberScheduled: 2, NumberReady: 2, UpdatedNumberScheduled: 2, NumberAvailable: 2},
+	}
+	backup := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup-123", Namespace: "data", OwnerReferences: ownedBy("CronJob", "backup")},
+		Spec:       batchv1.JobSpec{Template: podSpec("backup:1")},
+		Status: batchv1.JobStatus{
+			Succeeded:  1,
+			Conditions: []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}},
+		},
+	}
+	pods := []runtime.Object{
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-abc-1", Namespace: "default", OwnerReferences: ownedBy("ReplicaSet", "web-abc")}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-abc-2", Namespace: "default", OwnerReferences: ownedBy("ReplicaSet", "web-abc")}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "db-0", Namespace: "data", OwnerReferences: ownedBy("StatefulSet", "db")}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "backup-123-x", Namespace: "data", OwnerReferences: ownedBy("Job", "backup-123")}},
+	}
+
+	// Act
+	report := collectWorkloads(t, append(pods, web, rs, db, agent, backup)...)
+
+	// Assert: sorted by kind/namespace/name
+	if report.TotalWorkloads != 4 {
+		t.Fatalf("TotalWorkloads = %d, want 4", report.TotalWorkloads)
+	}
+	byKind := map[string]WorkloadInfo{}
+	for _, w := range report.Workloads {
+		byKind[w.Kind] = w
+	}
+	if report.Workloads[0].Kind != "DaemonSet" || report.Workloads[3].Kind != "StatefulSet" {
+		t.Errorf("order = %v", report.Workloads)
+	}
+
+	d := byKind["Deployment"]
+	if d.Desired != 3 || d.Ready != 3 || d.Updated != 2 || d.Available != 3 || d.Rollout != RolloutProgressing {
+		t.Errorf("Deployment = %+v, want 3/3/2/3 Progressing", d)
+	}
+	if d.Pods != 2 {
+		t.Errorf("Deployment pods = %d, want 2 (resolved through the ReplicaSet)", d.Pods)
+	}
+	if len(d.Images) != 2 || d.Images[0] != "nginx:1.27" {
+		t.Errorf("Deployment images = %v", d.Images)
+	}
+	if s := byKind["StatefulSet"]; s.Rollout != RolloutComplete || s.Pods != 1 {
+		t.Errorf("StatefulSet = %+v, want Complete with 1 pod", s)
+	}
+	if ds := byKind["DaemonSet"]; ds.Desired != 2 || ds.Rollout != RolloutComplete {
+		t.Errorf("DaemonSet = %+v, want 2 desired, Complete", ds)
+	}
+	if j := byKind["Job"]; j.Rollout != RolloutComplete || j.Available != 1 {
+		t.Errorf("Job = %+v, want Complete with 1 succeeded", j)
+	}
+	if j := byKind["Job"]; j.Pods != 1 {
+		t.Errorf("Job pods = %d, want 1 (CronJob -> Job -> Pod counts on the Job too)", j.Pods)
+	}
+}
+
+func TestDeploymentRolloutStates(t *testing.T) {
+	tests := []struct {
+		name string
+		d    appsv1.Deployment
+		want string
+	}{
+		{
+			name: "complete",
+			d: appsv1.Deployment{
+				Spec:   appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+				Status: appsv1.DeploymentStatus{Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2},
+			},
+			want: RolloutComplete,
+		},
+		{
+			name: "new generation not observed",
+			d: appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 3},
+				Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+				Status:     appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2},
+			},
+			want: RolloutProgressing,
+		},
+		{
+			name: "deadline exceeded",
+			d: appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+				Status: appsv1.DeploymentStatus{Conditions: []appsv1.DeploymentCondition{
+					{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionFalse, Reason: "ProgressDeadlineExceeded"},
+				}},
+			},
+			want: RolloutFailed,
+		},
+		{
+			name: "paused",
+			d:    appsv1.Deployment{Spec: appsv1.DeploymentSpec{Paused: true}},
+			want: RolloutPaused,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := deploymentInfo(&tt.d).Rollout
+
+			// Assert
+			if got != tt.want {
+				t.Errorf("Rollout = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildReportResolvesWorkloads(t *testing.T) {
+	// Arrange
+	rs := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "web-abc", Namespace: "default", OwnerReferences: ownedBy("Deployment", "web")}}
+	factory := informers.NewSharedInformerFactory(fake.NewClientset(rs), 0)
+	defer factory.Shutdown()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	owners := newOwnerResolver(factory)
+	factory.Start(ctx.Done())
+	factory.WaitForCacheSync(ctx.Done())
+	pods := []*corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "web-abc-1", Namespace: "default", OwnerReferences: ownedBy("ReplicaSet", "web-abc")}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "orphan-rs-1", Namespace: "default", OwnerReferences: ownedBy("ReplicaSet", "orphan-rs")}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "static", Namespace: "default"}},
+	}
+
+	// Act
+	report := (&AgentOptions{}).buildReport(pods, owners, nil, nil)
+
+	// Assert: sorted by name; the ReplicaSet not in the cache stays the owner
+	if w := report.Pods[0].Workload; w == nil || *w != (WorkloadRef{Kind: "ReplicaSet", Name: "orphan-rs"}) {
+		t.Errorf("orphan-rs-1 workload = %v", w)
+	}
+	if report.Pods[1].Workload != nil {
+		t.Errorf("static pod workload = %v, want nil", report.Pods[1].Workload)
+	}
+	if w := report.Pods[2].Workload; w == nil || *w != (WorkloadRef{Kind: "Deployment", Name: "web"}) {
+		t.Errorf("web-abc-1 workload = %v, want Deployment/web", w)
+	}
+}
@@ -0,0 +1,7 @@
+// Package v1alpha1 contém a API reports.basic-addon.open-cluster-management.io/v1alpha1.
+// Define o PodReport, escrito pelo agent no namespace de cada spoke no hub.
+//
+// +k8s:deepcopy-gen=package
+// +k8s:openapi-gen=true
+// +groupName=reports.basic-addon.open-cluster-management.io
+package v1alpha1
@@ -0,0 +1,186 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Namespaced,shortName=podrep
+// +kubebuilder:printcolumn:name="Cluster",type=string,JSONPath=`.status.clusterName`
+// +kubebuilder:printcolumn:name="Total Pods",type=integer,JSONPath=`.status.totalPods`
+// +kubebuilder:printcolumn:name="Last Sync",type=date,JSONPath=`.status.lastSyncTime`
+
+// PodReport é o relatório de pods de um spoke, gravado pelo agent no hub.
+// Fica no namespace do cluster no hub (mesmo nome do ManagedCluster).
+// O resumo e a lista de pods ficam em .status, atualizados a cada sync.
+type PodReport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PodReportSpec   `json:"spec,omitempty"`
+	Status PodReportStatus `json:"status,omitempty"`
+}
+
+// PodReportSpec identifica o cluster de origem do relatório.
+type PodReportSpec struct {
+	// ClusterName é o nome do spoke que publica o relatório.
+	ClusterName string `json:"clusterName"`
+}
+
+// PodReportStatus contém o resumo e os pods do último sync do agent.
+type PodReportStatus struct {
+	// ClusterName é o nome do spoke (repetido aqui para a coluna do kubectl).
+	// +optional
+	ClusterName string `json:"clusterName,omitempty"`
+
+	// TotalPods é o número de pods do spoke no último sync.
+	// +optional
+	TotalPods int32 `json:"totalPods"`
+
+	// LastSyncTime é o horário em que o agent montou o relatório.
+	// +optional
+	LastSyncTime metav1.Time `json:"lastSyncTime,omitempty"`
+
+	// Truncated indica que Pods foi omitido porque não cabe no limite de tamanho do objeto.
+	// Nesse caso use o backend configmap, que divide o relatório em shards.
+	// +optional
+	Truncated bool `json:"truncated,omitempty"`
+
+	// Pods lista os pods do spoke, ordenados por namespace/nome.
+	// +optional
+	// +listType=atomic
+	Pods []PodInfo `json:"pods,omitempty"`
+
+	// Resources soma requests e limits de CPU e memória por cluster e por namespace.
+	// +optional
+	Resources *ResourceSummary `json:"resources,omitempty"`
+
+	// Filters são os filtros de coleta em vigor no agent. Ausente quando o agent coleta tudo.
+	// +optional
+	Filters *CollectionFilters `json:"filters,omitempty"`
+}
+
+// CollectionFilters são os filtros de namespace e de pods aplicados pelo agent.
+type CollectionFilters struct {
+	// IncludeNamespaces são globs dos namespaces coletados (vazio = todos).
+	// +optional
+	// +listType=atomic
+	IncludeNamespaces []string `json:"includeNamespaces,omitempty"`
+	// ExcludeNamespaces são globs dos namespaces ignorados (prioridade sobre IncludeNamespaces).
+	// +optional
+	// +listType=atomic
+	ExcludeNamespaces []string `json:"excludeNamespaces,omitempty"`
+	// +optional
+	PodLabelSelector string `json:"podLabelSelector,omitempty"`
+	// +optional
+	PodFieldSelector string `json:"podFieldSelector,omitempty"`
+}
+
+// ResourceSummary soma requests e limits de CPU e memória dos pods não terminados.
+type ResourceSummary struct {
+	Requests ResourceAmounts `json:"requests"`
+	Limits   ResourceAmounts `json:"limits"`
+	// QoSClasses conta os pods por classe de QoS (Guaranteed, Burstable, BestEffort).
+	// +optional
+	QoSClasses map[string]int32 `json:"qosClasses,omitempty"`
+	// Usage é o uso medido pelo metrics-server (só com --metrics no agent).
+	// +optional
+	Usage *ResourceAmounts `json:"usage,omitempty"`
+	// +optional
+	// +listType=atomic
+	Namespaces []NamespaceResources `json:"namespaces,omitempty"`
+}
+
+// ResourceAmounts são as quantidades de CPU e memória (formato do Kubernetes, ex: 1500m, 3Gi).
+type ResourceAmounts struct {
+	CPU    string `json:"cpu"`
+	Memory string `json:"memory"`
+}
+
+// NamespaceResources é o resumo de recursos de um namespace.
+type NamespaceResources struct {
+	Namespace string          `json:"namespace"`
+	Pods      int32           `json:"pods"`
+	Requests  ResourceAmounts `json:"requests"`
+	Limits    ResourceAmounts `json:"limits"`
+	// +optional
+	QoSClasses map[string]int32 `json:"qosClasses,omitempty"`
+	// +optional
+	Usage *ResourceAmounts `json:"usage,omitempty"`
+	// Quotas compara o uso com os limites das ResourceQuotas do namespace.
+	// +optional
+	// +listType=atomic
+	Quotas []QuotaUsage `json:"quotas,omitempty"`
+}
+
+// QuotaUsage compara o uso calculado pelo agent com o limite (hard) de uma ResourceQuota.
+type QuotaUsage struct {
+	Quota    string `json:"quota"`
+	Resource string `json:"resource"`
+	Hard     string `json:"hard"`
+	Used     string `json:"used"`
+	// Percent é Used / Hard em %, arredondado para baixo.
+	Percent int64 `json:"percent"`
+}
+
+// PodInfo contém informações básicas de um pod.
+type PodInfo struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// Status é a fase do pod (Status.Phase).
+	Status string `json:"status"`
+	// Restarts é a soma dos restarts dos containers (sem init containers).
+	// +optional
+	Restarts int32 `json:"restarts,omitempty"`
+	// +optional
+	// +listType=atomic
+	Containers []ContainerInfo `json:"containers,omitempty"`
+	// Workload é o controller que gerencia o pod, resolvido pelas ownerReferences
+	// (ex: Pod -> ReplicaSet -> Deployment).
+	// +optional
+	Workload *WorkloadRef `json:"workload,omitempty"`
+	// Usage é o uso de CPU e memória medido pelo metrics-server (só com --metrics no agent).
+	// +optional
+	Usage *ResourceAmounts `json:"usage,omitempty"`
+}
+
+// WorkloadRef identifica o workload dono do pod (no mesmo namespace do pod).
+type WorkloadRef struct {
+	// Kind é Deployment, StatefulSet, DaemonSet, Job, CronJob ou o kind do dono direto.
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+// ContainerInfo contém o estado de um container (ou init container) do pod.
+type ContainerInfo struct {
+	Name string `json:"name"`
+	// +optional
+	Init  bool   `json:"init,omitempty"`
+	Image string `json:"image"`
+	// +optional
+	ImageID string `json:"imageID,omitempty"`
+	Ready   bool   `json:"ready"`
+	// +optional
+	RestartCount int32 `json:"restartCount,omitempty"`
+	// State é waiting, running ou terminated.
+	// +optional
+	State string `json:"state,omitempty"`
+	// +optional
+	WaitingReason string `json:"waitingReason,omitempty"`
+	// +optional
+	LastTerminationReason string `json:"lastTerminationReason,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+
+// PodReportList é a lista de PodReport.
+type PodReportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []PodReport `json:"items"`
+}
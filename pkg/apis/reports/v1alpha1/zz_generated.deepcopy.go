@@ -0,0 +1,314 @@
+//go:build !ignore_autogenerated
+// +build !ignore_autogenerated
+
+// Code generated by deepcopy-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CollectionFilters) DeepCopyInto(out *CollectionFilters) {
+	*out = *in
+	if in.IncludeNamespaces != nil {
+		in, out := &in.IncludeNamespaces, &out.IncludeNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludeNamespaces != nil {
+		in, out := &in.ExcludeNamespaces, &out.ExcludeNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CollectionFilters.
+func (in *CollectionFilters) DeepCopy() *CollectionFilters {
+	if in == nil {
+		return nil
+	}
+	out := new(CollectionFilters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerInfo) DeepCopyInto(out *ContainerInfo) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerInfo.
+func (in *ContainerInfo) DeepCopy() *ContainerInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceResources) DeepCopyInto(out *NamespaceResources) {
+	*out = *in
+	out.Requests = in.Requests
+	out.Limits = in.Limits
+	if in.QoSClasses != nil {
+		in, out := &in.QoSClasses, &out.QoSClasses
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Usage != nil {
+		in, out := &in.Usage, &out.Usage
+		*out = new(ResourceAmounts)
+		**out = **in
+	}
+	if in.Quotas != nil {
+		in, out := &in.Quotas, &out.Quotas
+		*out = make([]QuotaUsage, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceResources.
+func (in *NamespaceResources) DeepCopy() *NamespaceResources {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceResources)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodInfo) DeepCopyInto(out *PodInfo) {
+	*out = *in
+	if in.Containers != nil {
+		in, out := &in.Containers, &out.Containers
+		*out = make([]ContainerInfo, len(*in))
+		copy(*out, *in)
+	}
+	if in.Workload != nil {
+		in, out := &in.Workload, &out.Workload
+		*out = new(WorkloadRef)
+		**out = **in
+	}
+	if in.Usage != nil {
+		in, out := &in.Usage, &out.Usage
+		*out = new(ResourceAmounts)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodInfo.
+func (in *PodInfo) DeepCopy() *PodInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(PodInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodReport) DeepCopyInto(out *PodReport) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodReport.
+func (in *PodReport) DeepCopy() *PodReport {
+	if in == nil {
+		return nil
+	}
+	out := new(PodReport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PodReport) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodReportList) DeepCopyInto(out *PodReportList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PodReport, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodReportList.
+func (in *PodReportList) DeepCopy() *PodReportList {
+	if in == nil {
+		return nil
+	}
+	out := new(PodReportList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PodReportList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodReportSpec) DeepCopyInto(out *PodReportSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodReportSpec.
+func (in *PodReportSpec) DeepCopy() *PodReportSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PodReportSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodReportStatus) DeepCopyInto(out *PodReportStatus) {
+	*out = *in
+	in.LastSyncTime.DeepCopyInto(&out.LastSyncTime)
+	if in.Pods != nil {
+		in, out := &in.Pods, &out.Pods
+		*out = make([]PodInfo, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(ResourceSummary)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Filters != nil {
+		in, out := &in.Filters, &out.Filters
+		*out = new(CollectionFilters)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodReportStatus.
+func (in *PodReportStatus) DeepCopy() *PodReportStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PodReportStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuotaUsage) DeepCopyInto(out *QuotaUsage) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaUsage.
+func (in *QuotaUsage) DeepCopy() *QuotaUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(QuotaUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceAmounts) DeepCopyInto(out *ResourceAmounts) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceAmounts.
+func (in *ResourceAmounts) DeepCopy() *ResourceAmounts {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceAmounts)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceSummary) DeepCopyInto(out *ResourceSummary) {
+	*out = *in
+	out.Requests = in.Requests
+	out.Limits = in.Limits
+	if in.QoSClasses != nil {
+		in, out := &in.QoSClasses, &out.QoSClasses
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Usage != nil {
+		in, out := &in.Usage, &out.Usage
+		*out = new(ResourceAmounts)
+		**out = **in
+	}
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]NamespaceResources, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceSummary.
+func (in *ResourceSummary) DeepCopy() *ResourceSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadRef) DeepCopyInto(out *WorkloadRef) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadRef.
+func (in *WorkloadRef) DeepCopy() *WorkloadRef {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadRef)
+	in.DeepCopyInto(out)
+	return out
+}
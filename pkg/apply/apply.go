@@ -0,0 +1,151 @@
+// Package apply contém os helpers de server-side apply usados pelo agent e pelo controller.
+//
+// Agent (relatório no hub) e controller (Role/RoleBinding do agent) gravam seus objetos com
+// server-side apply e um field manager próprio. Assim labels e anotações adicionadas por outras
+// ferramentas (ex: GitOps) não são apagadas, e conflitos de ownership viram *ConflictError.
+package apply
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/csaupgrade"
+	"k8s.io/klog/v2"
+)
+
+// LegacyFieldManager é o field manager das escritas Get + Create/Update anteriores ao
+// server-side apply. O apiserver deriva o nome do User-Agent do binário ("addon").
+// Campos desse manager são migrados para o field manager de apply (ver Do).
+const LegacyFieldManager = "addon"
+
+// conflictManager extrai o field manager da mensagem de conflito do apiserver:
+// `conflict with "kubectl-edit" using v1`.
+var conflictManager = regexp.MustCompile(`conflict with "([^"]*)"`)
+
+// ConflictError indica que outro field manager é dono de campos que o apply tentou alterar.
+// O apply não é forçado: o objeto fica inalterado até o conflito ser resolvido.
+type ConflictError struct {
+	Kind         string   // Tipo do objeto (ex: ConfigMap)
+	Namespace    string   // Namespace do objeto
+	Name         string   // Nome do objeto
+	FieldManager string   // Field manager que tentou o apply
+	Managers     []string // Field managers donos dos campos em conflito
+	Fields       []string // Campos em conflito (ex: .data.report)
+	Err          error    // Erro original do apiserver (409)
+}
+
+// Error implementa error.
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("conflito de server-side apply em %s %s/%s: campos %s pertencem a %s",
+		e.Kind, e.Namespace, e.Name, strings.Join(e.Fields, ", "), strings.Join(e.Managers, ", "))
+}
+
+// Unwrap permite usar apierrors.IsConflict no erro original.
+func (e *ConflictError) Unwrap() error {
+	return e.Err
+}
+
+// IsConflict indica se err (ou algum erro encadeado) é um *ConflictError.
+func IsConflict(err error) bool {
+	var conflict *ConflictError
+	return errors.As(err, &conflict)
+}
+
+// Object identifica o alvo do apply e como lê-lo e corrigi-lo para migrar managedFields.
+type Object struct {
+	Kind         string
+	Namespace    string
+	Name         string
+	FieldManager string
+	Subresource  string // "status" para ApplyStatus; vazio para o objeto principal
+
+	// Get lê o objeto atual (usado só para migrar managedFields do LegacyFieldManager).
+	Get func(ctx context.Context) (runtime.Object, error)
+	// Patch aplica um JSON patch no objeto (mesmo subresource do apply).
+	Patch func(ctx context.Context, jsonPatch []byte) error
+}
+
+// Do executa o apply e converte conflitos (409) em *ConflictError.
+//
+// Se todos os campos em conflito pertencem ao LegacyFieldManager (objetos criados antes do
+// server-side apply), o ownership é migrado com csaupgrade e o apply é repetido uma vez.
+// Conflitos com qualquer outro field manager são retornados ao chamador.
+func Do(ctx context.Context, obj Object, apply func(ctx context.Context) error) error {
+	err := apply(ctx)
+	if !apierrors.IsConflict(err) {
+		return err
+	}
+	conflict := newConflictError(obj, err)
+	if !conflict.legacyOnly() || obj.Get == nil || obj.Patch == nil {
+		return conflict
+	}
+
+	current, err := obj.Get(ctx)
+	if err != nil {
+		return conflict
+	}
+	patch, err := csaupgrade.UpgradeManagedFieldsPatch(current, sets.New(LegacyFieldManager), obj.FieldManager,
+		csaupgrade.Subresource(obj.Subresource))
+	if err != nil || patch == nil {
+		return conflict
+	}
+	if err := obj.Patch(ctx, patch); err != nil {
+		return err
+	}
+	klog.Infof("managedFields de %s %s/%s migrados de %q para %q", obj.Kind, obj.Namespace, obj.Name,
+		LegacyFieldManager, obj.FieldManager)
+
+	err = apply(ctx)
+	if apierrors.IsConflict(err) {
+		return newConflictError(obj, err)
+	}
+	return err
+}
+
+// newConflictError monta o *ConflictError a partir das causas do status 409.
+func newConflictError(obj Object, err error) *ConflictError {
+	conflict := &ConflictError{
+		Kind:         obj.Kind,
+		Namespace:    obj.Namespace,
+		Name:         obj.Name,
+		FieldManager: obj.FieldManager,
+		Err:          err,
+	}
+	var status apierrors.APIStatus
+	if !errors.As(err, &status) || status.Status().Details == nil {
+		return conflict
+	}
+
+	managers := sets.New[string]()
+	for _, cause := range status.Status().Details.Causes {
+		if cause.Type != metav1.CauseTypeFieldManagerConflict {
+			continue
+		}
+		if m := conflictManager.FindStringSubmatch(cause.Message); m != nil {
+			managers.Insert(m[1])
+		}
+		conflict.Fields = append(conflict.Fields, cause.Field)
+	}
+	conflict.Managers = sets.List(managers)
+	return conflict
+}
+
+// legacyOnly indica se todos os conflitos são com o LegacyFieldManager.
+func (e *ConflictError) legacyOnly() bool {
+	if len(e.Managers) == 0 {
+		return false
+	}
+	for _, m := range e.Managers {
+		if m != LegacyFieldManager {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,118 @@
+package apply
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	applycorev1 "k8s.io/client-go/applyconfigurations/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// applyConfigMap applies data["key"] = value to ConfigMap cm in ns "cluster1" via Do.
+func applyConfigMap(ctx context.Context, client kubernetes.Interface, value string) error {
+	cms := client.CoreV1().ConfigMaps("cluster1")
+	obj := Object{
+		Kind:         "ConfigMap",
+		Namespace:    "cluster1",
+		Name:         "cm",
+		FieldManager: "basic-addon-agent",
+		Get: func(ctx context.Context) (runtime.Object, error) {
+			return cms.Get(ctx, "cm", metav1.GetOptions{})
+		},
+		Patch: func(ctx context.Context, patch []byte) error {
+			_, err := cms.Patch(ctx, "cm", types.JSONPatchType, patch, metav1.PatchOptions{})
+			return err
+		},
+	}
+	return Do(ctx, obj, func(ctx context.Context) error {
+		ac := applycorev1.ConfigMap("cm", "cluster1").WithData(map[string]string{"key": value})
+		_, err := cms.Apply(ctx, ac, metav1.ApplyOptions{FieldManager: "basic-addon-agent"})
+		return err
+	})
+}
+
+// createConfigMap creates the ConfigMap the old way (Create) under the given field manager.
+func createConfigMap(t *testing.T, client kubernetes.Interface, manager string) {
+	t.Helper()
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "cluster1"},
+		Data:       map[string]string{"key": "old"},
+	}
+	_, err := client.CoreV1().ConfigMaps("cluster1").Create(context.Background(), cm, metav1.CreateOptions{FieldManager: manager})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+}
+
+func TestDoReturnsTypedConflict(t *testing.T) {
+	// Arrange: another tool owns data.key
+	ctx := context.Background()
+	client := fake.NewClientset()
+	createConfigMap(t, client, "kubectl-edit")
+
+	// Act
+	err := applyConfigMap(ctx, client, "new")
+
+	// Assert
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("err = %v, want *ConflictError", err)
+	}
+	if !IsConflict(err) || !apierrors.IsConflict(err) {
+		t.Errorf("IsConflict and apierrors.IsConflict should both match %v", err)
+	}
+	if len(conflict.Managers) != 1 || conflict.Managers[0] != "kubectl-edit" {
+		t.Errorf("Managers = %v, want [kubectl-edit]", conflict.Managers)
+	}
+	if len(conflict.Fields) != 1 || conflict.Fields[0] != ".data.key" {
+		t.Errorf("Fields = %v, want [.data.key]", conflict.Fields)
+	}
+	cm, _ := client.CoreV1().ConfigMaps("cluster1").Get(ctx, "cm", metav1.GetOptions{})
+	if cm.Data["key"] != "old" {
+		t.Errorf("data.key = %q, the object should be left unchanged", cm.Data["key"])
+	}
+}
+
+func TestDoMigratesLegacyFieldManager(t *testing.T) {
+	// Arrange: object written with Create/Update before server-side apply
+	ctx := context.Background()
+	client := fake.NewClientset()
+	createConfigMap(t, client, LegacyFieldManager)
+
+	// Act
+	err := applyConfigMap(ctx, client, "new")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	cm, _ := client.CoreV1().ConfigMaps("cluster1").Get(ctx, "cm", metav1.GetOptions{})
+	if cm.Data["key"] != "new" {
+		t.Errorf("data.key = %q, want new", cm.Data["key"])
+	}
+	for _, entry := range cm.ManagedFields {
+		if entry.Manager == LegacyFieldManager {
+			t.Errorf("managedFields still has %q: %+v", LegacyFieldManager, cm.ManagedFields)
+		}
+	}
+}
+
+func TestDoPassesThroughOtherErrors(t *testing.T) {
+	// Arrange
+	want := apierrors.NewForbidden(corev1.Resource("configmaps"), "cm", errors.New("denied"))
+
+	// Act
+	err := Do(context.Background(), Object{Kind: "ConfigMap"}, func(context.Context) error { return want })
+
+	// Assert
+	if err != want {
+		t.Errorf("err = %v, want %v", err, want)
+	}
+}
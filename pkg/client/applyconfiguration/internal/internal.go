@@ -0,0 +1,416 @@
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package internal
+
+import (
+	fmt "fmt"
+	sync "sync"
+
+	typed "sigs.k8s.io/structured-merge-diff/v6/typed"
+)
+
+func Parser() *typed.Parser {
+	parserOnce.Do(func() {
+		var err error
+		parser, err = typed.NewParser(schemaYAML)
+		if err != nil {
+			panic(fmt.Sprintf("Failed to parse schema: %v", err))
+		}
+	})
+	return parser
+}
+
+var parserOnce sync.Once
+var parser *typed.Parser
+var schemaYAML = typed.YAMLObject(`types:
+- name: com.github.totvs.addon-framework-basic.pkg.apis.reports.v1alpha1.CollectionFilters
+  map:
+    fields:
+    - name: excludeNamespaces
+      type:
+        list:
+          elementType:
+            scalar: string
+          elementRelationship: atomic
+    - name: includeNamespaces
+      type:
+        list:
+          elementType:
+            scalar: string
+          elementRelationship: atomic
+    - name: podFieldSelector
+      type:
+        scalar: string
+    - name: podLabelSelector
+      type:
+        scalar: string
+- name: com.github.totvs.addon-framework-basic.pkg.apis.reports.v1alpha1.ContainerInfo
+  map:
+    fields:
+    - name: image
+      type:
+        scalar: string
+      default: ""
+    - name: imageID
+      type:
+        scalar: string
+    - name: init
+      type:
+        scalar: boolean
+    - name: lastTerminationReason
+      type:
+        scalar: string
+    - name: name
+      type:
+        scalar: string
+      default: ""
+    - name: ready
+      type:
+        scalar: boolean
+      default: false
+    - name: restartCount
+      type:
+        scalar: numeric
+    - name: state
+      type:
+        scalar: string
+    - name: waitingReason
+      type:
+        scalar: string
+- name: com.github.totvs.addon-framework-basic.pkg.apis.reports.v1alpha1.NamespaceResources
+  map:
+    fields:
+    - name: limits
+      type:
+        namedType: com.github.totvs.addon-framework-basic.pkg.apis.reports.v1alpha1.ResourceAmounts
+      default: {}
+    - name: namespace
+      type:
+        scalar: string
+      default: ""
+    - name: pods
+      type:
+        scalar: numeric
+      default: 0
+    - name: qosClasses
+      type:
+        map:
+          elementType:
+            scalar: numeric
+    - name: quotas
+      type:
+        list:
+          elementType:
+            namedType: com.github.totvs.addon-framework-basic.pkg.apis.reports.v1alpha1.QuotaUsage
+          elementRelationship: atomic
+    - name: requests
+      type:
+        namedType: com.github.totvs.addon-framework-basic.pkg.apis.reports.v1alpha1.ResourceAmounts
+      default: {}
+    - name: usage
+      type:
+        namedType: com.github.totvs.addon-framework-basic.pkg.apis.reports.v1alpha1.ResourceAmounts
+- name: com.github.totvs.addon-framework-basic.pkg.apis.reports.v1alpha1.PodInfo
+  map:
+    fields:
+    - name: containers
+      type:
+        list:
+          elementType:
+            namedType: com.github.totvs.addon-framework-basic.pkg.apis.reports.v1alpha1.ContainerInfo
+          elementRelationship: atomic
+    - name: name
+      type:
+        scalar: string
+      default: ""
+    - name: namespace
+      type:
+        scalar: string
+      default: ""
+    - name: restarts
+      type:
+        scalar: numeric
+    - name: status
+      type:
+        scalar: string
+      default: ""
+    - name: usage
+      type:
+        namedType: com.github.totvs.addon-framework-basic.pkg.apis.reports.v1alpha1.ResourceAmounts
+    - name: workload
+      type:
+        namedType: com.github.totvs.addon-framework-basic.pkg.apis.reports.v1alpha1.WorkloadRef
+- name: com.github.totvs.addon-framework-basic.pkg.apis.reports.v1alpha1.PodReport
+  map:
+    fields:
+    - name: apiVersion
+      type:
+        scalar: string
+    - name: kind
+      type:
+        scalar: string
+    - name: metadata
+      type:
+        namedType: io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta
+      default: {}
+    - name: spec
+      type:
+        namedType: com.github.totvs.addon-framework-basic.pkg.apis.reports.v1alpha1.PodReportSpec
+      default: {}
+    - name: status
+      type:
+        namedType: com.github.totvs.addon-framework-basic.pkg.apis.reports.v1alpha1.PodReportStatus
+      default: {}
+- name: com.github.totvs.addon-framework-basic.pkg.apis.reports.v1alpha1.PodReportSpec
+  map:
+    fields:
+    - name: clusterName
+      type:
+        scalar: string
+      default: ""
+- name: com.github.totvs.addon-framework-basic.pkg.apis.reports.v1alpha1.PodReportStatus
+  map:
+    fields:
+    - name: clusterName
+      type:
+        scalar: string
+    - name: filters
+      type:
+        namedType: com.github.totvs.addon-framework-basic.pkg.apis.reports.v1alpha1.CollectionFilters
+    - name: lastSyncTime
+      type:
+        namedType: io.k8s.apimachinery.pkg.apis.meta.v1.Time
+    - name: pods
+      type:
+        list:
+          elementType:
+            namedType: com.github.totvs.addon-framework-basic.pkg.apis.reports.v1alpha1.PodInfo
+          elementRelationship: atomic
+    - name: resources
+      type:
+        namedType: com.github.totvs.addon-framework-basic.pkg.apis.reports.v1alpha1.ResourceSummary
+    - name: totalPods
+      type:
+        scalar: numeric
+      default: 0
+    - name: truncated
+      type:
+        scalar: boolean
+- name: com.github.totvs.addon-framework-basic.pkg.apis.reports.v1alpha1.QuotaUsage
+  map:
+    fields:
+    - name: hard
+      type:
+        scalar: string
+      default: ""
+    - name: percent
+      type:
+        scalar: numeric
+      default: 0
+    - name: quota
+      type:
+        scalar: string
+      default: ""
+    - name: resource
+      type:
+        scalar: string
+      default: ""
+    - name: used
+      type:
+        scalar: string
+      default: ""
+- name: com.github.totvs.addon-framework-basic.pkg.apis.reports.v1alpha1.ResourceAmounts
+  map:
+    fields:
+    - name: cpu
+      type:
+        scalar: string
+      default: ""
+    - name: memory
+      type:
+        scalar: string
+      default: ""
+- name: com.github.totvs.addon-framework-basic.pkg.apis.reports.v1alpha1.ResourceSummary
+  map:
+    fields:
+    - name: limits
+      type:
+        namedType: com.github.totvs.addon-framework-basic.pkg.apis.reports.v1alpha1.ResourceAmounts
+      default: {}
+    - name: namespaces
+      type:
+        list:
+          elementType:
+            namedType: com.github.totvs.addon-framework-basic.pkg.apis.reports.v1alpha1.NamespaceResources
+          elementRelationship: atomic
+    - name: qosClasses
+      type:
+        map:
+          elementType:
+            scalar: numeric
+    - name: requests
+      type:
+        namedType: com.github.totvs.addon-framework-basic.pkg.apis.reports.v1alpha1.ResourceAmounts
+      default: {}
+    - name: usage
+      type:
+        namedType: com.github.totvs.addon-framework-basic.pkg.apis.reports.v1alpha1.ResourceAmounts
+- name: com.github.totvs.addon-framework-basic.pkg.apis.reports.v1alpha1.WorkloadRef
+  map:
+    fields:
+    - name: kind
+      type:
+        scalar: string
+      default: ""
+    - name: name
+      type:
+        scalar: string
+      default: ""
+- name: io.k8s.apimachinery.pkg.apis.meta.v1.FieldsV1
+  map:
+    elementType:
+      scalar: untyped
+      list:
+        elementType:
+          namedType: __untyped_atomic_
+        elementRelationship: atomic
+      map:
+        elementType:
+          namedType: __untyped_deduced_
+        elementRelationship: separable
+- name: io.k8s.apimachinery.pkg.apis.meta.v1.ManagedFieldsEntry
+  map:
+    fields:
+    - name: apiVersion
+      type:
+        scalar: string
+    - name: fieldsType
+      type:
+        scalar: string
+    - name: fieldsV1
+      type:
+        namedType: io.k8s.apimachinery.pkg.apis.meta.v1.FieldsV1
+    - name: manager
+      type:
+        scalar: string
+    - name: operation
+      type:
+        scalar: string
+    - name: subresource
+      type:
+        scalar: string
+    - name: time
+      type:
+        namedType: io.k8s.apimachinery.pkg.apis.meta.v1.Time
+- name: io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta
+  map:
+    fields:
+    - name: annotations
+      type:
+        map:
+          elementType:
+            scalar: string
+    - name: creationTimestamp
+      type:
+        namedType: io.k8s.apimachinery.pkg.apis.meta.v1.Time
+    - name: deletionGracePeriodSeconds
+      type:
+        scalar: numeric
+    - name: deletionTimestamp
+      type:
+        namedType: io.k8s.apimachinery.pkg.apis.meta.v1.Time
+    - name: finalizers
+      type:
+        list:
+          elementType:
+            scalar: string
+          elementRelationship: associative
+    - name: generateName
+      type:
+        scalar: string
+    - name: generation
+      type:
+        scalar: numeric
+    - name: labels
+      type:
+        map:
+          elementType:
+            scalar: string
+    - name: managedFields
+      type:
+        list:
+          elementType:
+            namedType: io.k8s.apimachinery.pkg.apis.meta.v1.ManagedFieldsEntry
+          elementRelationship: atomic
+    - name: name
+      type:
+        scalar: string
+    - name: namespace
+      type:
+        scalar: string
+    - name: ownerReferences
+      type:
+        list:
+          elementType:
+            namedType: io.k8s.apimachinery.pkg.apis.meta.v1.OwnerReference
+          elementRelationship: associative
+          keys:
+          - uid
+    - name: resourceVersion
+      type:
+        scalar: string
+    - name: selfLink
+      type:
+        scalar: string
+    - name: uid
+      type:
+        scalar: string
+- name: io.k8s.apimachinery.pkg.apis.meta.v1.OwnerReference
+  map:
+    fields:
+    - name: apiVersion
+      type:
+        scalar: string
+      default: ""
+    - name: blockOwnerDeletion
+      type:
+        scalar: boolean
+    - name: controller
+      type:
+        scalar: boolean
+    - name: kind
+      type:
+        scalar: string
+      default: ""
+    - name: name
+      type:
+        scalar: string
+      default: ""
+    - name: uid
+      type:
+        scalar: string
+      default: ""
+    elementRelationship: atomic
+- name: io.k8s.apimachinery.pkg.apis.meta.v1.Time
+  scalar: untyped
+- name: __untyped_atomic_
+  scalar: untyped
+  list:
+    elementType:
+      namedType: __untyped_atomic_
+    elementRelationship: atomic
+  map:
+    elementType:
+      namedType: __untyped_atomic_
+    elementRelationship: atomic
+- name: __untyped_deduced_
+  scalar: untyped
+  list:
+    elementType:
+      namedType: __untyped_atomic_
+    elementRelationship: atomic
+  map:
+    elementType:
+      namedType: __untyped_deduced_
+    elementRelationship: separable
+`)
@@ -0,0 +1,54 @@
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// CollectionFiltersApplyConfiguration represents a declarative configuration of the CollectionFilters type for use
+// with apply.
+type CollectionFiltersApplyConfiguration struct {
+	IncludeNamespaces []string `json:"includeNamespaces,omitempty"`
+	ExcludeNamespaces []string `json:"excludeNamespaces,omitempty"`
+	PodLabelSelector  *string  `json:"podLabelSelector,omitempty"`
+	PodFieldSelector  *string  `json:"podFieldSelector,omitempty"`
+}
+
+// CollectionFiltersApplyConfiguration constructs a declarative configuration of the CollectionFilters type for use with
+// apply.
+func CollectionFilters() *CollectionFiltersApplyConfiguration {
+	return &CollectionFiltersApplyConfiguration{}
+}
+
+// WithIncludeNamespaces adds the given value to the IncludeNamespaces field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the IncludeNamespaces field.
+func (b *CollectionFiltersApplyConfiguration) WithIncludeNamespaces(values ...string) *CollectionFiltersApplyConfiguration {
+	for i := range values {
+		b.IncludeNamespaces = append(b.IncludeNamespaces, values[i])
+	}
+	return b
+}
+
+// WithExcludeNamespaces adds the given value to the ExcludeNamespaces field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the ExcludeNamespaces field.
+func (b *CollectionFiltersApplyConfiguration) WithExcludeNamespaces(values ...string) *CollectionFiltersApplyConfiguration {
+	for i := range values {
+		b.ExcludeNamespaces = append(b.ExcludeNamespaces, values[i])
+	}
+	return b
+}
+
+// WithPodLabelSelector sets the PodLabelSelector field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the PodLabelSelector field is set to the value of the last call.
+func (b *CollectionFiltersApplyConfiguration) WithPodLabelSelector(value string) *CollectionFiltersApplyConfiguration {
+	b.PodLabelSelector = &value
+	return b
+}
+
+// WithPodFieldSelector sets the PodFieldSelector field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the PodFieldSelector field is set to the value of the last call.
+func (b *CollectionFiltersApplyConfiguration) WithPodFieldSelector(value string) *CollectionFiltersApplyConfiguration {
+	b.PodFieldSelector = &value
+	return b
+}
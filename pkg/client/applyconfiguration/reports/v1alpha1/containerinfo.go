@@ -0,0 +1,95 @@
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// ContainerInfoApplyConfiguration represents a declarative configuration of the ContainerInfo type for use
+// with apply.
+type ContainerInfoApplyConfiguration struct {
+	Name                  *string `json:"name,omitempty"`
+	Init                  *bool   `json:"init,omitempty"`
+	Image                 *string `json:"image,omitempty"`
+	ImageID               *string `json:"imageID,omitempty"`
+	Ready                 *bool   `json:"ready,omitempty"`
+	RestartCount          *int32  `json:"restartCount,omitempty"`
+	State                 *string `json:"state,omitempty"`
+	WaitingReason         *string `json:"waitingReason,omitempty"`
+	LastTerminationReason *string `json:"lastTerminationReason,omitempty"`
+}
+
+// ContainerInfoApplyConfiguration constructs a declarative configuration of the ContainerInfo type for use with
+// apply.
+func ContainerInfo() *ContainerInfoApplyConfiguration {
+	return &ContainerInfoApplyConfiguration{}
+}
+
+// WithName sets the Name field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Name field is set to the value of the last call.
+func (b *ContainerInfoApplyConfiguration) WithName(value string) *ContainerInfoApplyConfiguration {
+	b.Name = &value
+	return b
+}
+
+// WithInit sets the Init field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Init field is set to the value of the last call.
+func (b *ContainerInfoApplyConfiguration) WithInit(value bool) *ContainerInfoApplyConfiguration {
+	b.Init = &value
+	return b
+}
+
+// WithImage sets the Image field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Image field is set to the value of the last call.
+func (b *ContainerInfoApplyConfiguration) WithImage(value string) *ContainerInfoApplyConfiguration {
+	b.Image = &value
+	return b
+}
+
+// WithImageID sets the ImageID field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ImageID field is set to the value of the last call.
+func (b *ContainerInfoApplyConfiguration) WithImageID(value string) *ContainerInfoApplyConfiguration {
+	b.ImageID = &value
+	return b
+}
+
+// WithReady sets the Ready field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Ready field is set to the value of the last call.
+func (b *ContainerInfoApplyConfiguration) WithReady(value bool) *ContainerInfoApplyConfiguration {
+	b.Ready = &value
+	return b
+}
+
+// WithRestartCount sets the RestartCount field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the RestartCount field is set to the value of the last call.
+func (b *ContainerInfoApplyConfiguration) WithRestartCount(value int32) *ContainerInfoApplyConfiguration {
+	b.RestartCount = &value
+	return b
+}
+
+// WithState sets the State field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the State field is set to the value of the last call.
+func (b *ContainerInfoApplyConfiguration) WithState(value string) *ContainerInfoApplyConfiguration {
+	b.State = &value
+	return b
+}
+
+// WithWaitingReason sets the WaitingReason field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the WaitingReason field is set to the value of the last call.
+func (b *ContainerInfoApplyConfiguration) WithWaitingReason(value string) *ContainerInfoApplyConfiguration {
+	b.WaitingReason = &value
+	return b
+}
+
+// WithLastTerminationReason sets the LastTerminationReason field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the LastTerminationReason field is set to the value of the last call.
+func (b *ContainerInfoApplyConfiguration) WithLastTerminationReason(value string) *ContainerInfoApplyConfiguration {
+	b.LastTerminationReason = &value
+	return b
+}
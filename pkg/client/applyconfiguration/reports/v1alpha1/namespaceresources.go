@@ -0,0 +1,88 @@
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// NamespaceResourcesApplyConfiguration represents a declarative configuration of the NamespaceResources type for use
+// with apply.
+type NamespaceResourcesApplyConfiguration struct {
+	Namespace  *string                            `json:"namespace,omitempty"`
+	Pods       *int32                             `json:"pods,omitempty"`
+	Requests   *ResourceAmountsApplyConfiguration `json:"requests,omitempty"`
+	Limits     *ResourceAmountsApplyConfiguration `json:"limits,omitempty"`
+	QoSClasses map[string]int32                   `json:"qosClasses,omitempty"`
+	Usage      *ResourceAmountsApplyConfiguration `json:"usage,omitempty"`
+	Quotas     []QuotaUsageApplyConfiguration     `json:"quotas,omitempty"`
+}
+
+// NamespaceResourcesApplyConfiguration constructs a declarative configuration of the NamespaceResources type for use with
+// apply.
+func NamespaceResources() *NamespaceResourcesApplyConfiguration {
+	return &NamespaceResourcesApplyConfiguration{}
+}
+
+// WithNamespace sets the Namespace field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Namespace field is set to the value of the last call.
+func (b *NamespaceResourcesApplyConfiguration) WithNamespace(value string) *NamespaceResourcesApplyConfiguration {
+	b.Namespace = &value
+	return b
+}
+
+// WithPods sets the Pods field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Pods field is set to the value of the last call.
+func (b *NamespaceResourcesApplyConfiguration) WithPods(value int32) *NamespaceResourcesApplyConfiguration {
+	b.Pods = &value
+	return b
+}
+
+// WithRequests sets the Requests field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Requests field is set to the value of the last call.
+func (b *NamespaceResourcesApplyConfiguration) WithRequests(value *ResourceAmountsApplyConfiguration) *NamespaceResourcesApplyConfiguration {
+	b.Requests = value
+	return b
+}
+
+// WithLimits sets the Limits field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Limits field is set to the value of the last call.
+func (b *NamespaceResourcesApplyConfiguration) WithLimits(value *ResourceAmountsApplyConfiguration) *NamespaceResourcesApplyConfiguration {
+	b.Limits = value
+	return b
+}
+
+// WithQoSClasses puts the entries into the QoSClasses field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the QoSClasses field,
+// overwriting an existing map entries in QoSClasses field with the same key.
+func (b *NamespaceResourcesApplyConfiguration) WithQoSClasses(entries map[string]int32) *NamespaceResourcesApplyConfiguration {
+	if b.QoSClasses == nil && len(entries) > 0 {
+		b.QoSClasses = make(map[string]int32, len(entries))
+	}
+	for k, v := range entries {
+		b.QoSClasses[k] = v
+	}
+	return b
+}
+
+// WithUsage sets the Usage field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Usage field is set to the value of the last call.
+func (b *NamespaceResourcesApplyConfiguration) WithUsage(value *ResourceAmountsApplyConfiguration) *NamespaceResourcesApplyConfiguration {
+	b.Usage = value
+	return b
+}
+
+// WithQuotas adds the given value to the Quotas field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Quotas field.
+func (b *NamespaceResourcesApplyConfiguration) WithQuotas(values ...*QuotaUsageApplyConfiguration) *NamespaceResourcesApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithQuotas")
+		}
+		b.Quotas = append(b.Quotas, *values[i])
+	}
+	return b
+}
@@ -0,0 +1,82 @@
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// PodInfoApplyConfiguration represents a declarative configuration of the PodInfo type for use
+// with apply.
+type PodInfoApplyConfiguration struct {
+	Name       *string                            `json:"name,omitempty"`
+	Namespace  *string                            `json:"namespace,omitempty"`
+	Status     *string                            `json:"status,omitempty"`
+	Restarts   *int32                             `json:"restarts,omitempty"`
+	Containers []ContainerInfoApplyConfiguration  `json:"containers,omitempty"`
+	Workload   *WorkloadRefApplyConfiguration     `json:"workload,omitempty"`
+	Usage      *ResourceAmountsApplyConfiguration `json:"usage,omitempty"`
+}
+
+// PodInfoApplyConfiguration constructs a declarative configuration of the PodInfo type for use with
+// apply.
+func PodInfo() *PodInfoApplyConfiguration {
+	return &PodInfoApplyConfiguration{}
+}
+
+// WithName sets the Name field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Name field is set to the value of the last call.
+func (b *PodInfoApplyConfiguration) WithName(value string) *PodInfoApplyConfiguration {
+	b.Name = &value
+	return b
+}
+
+// WithNamespace sets the Namespace field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Namespace field is set to the value of the last call.
+func (b *PodInfoApplyConfiguration) WithNamespace(value string) *PodInfoApplyConfiguration {
+	b.Namespace = &value
+	return b
+}
+
+// WithStatus sets the Status field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Status field is set to the value of the last call.
+func (b *PodInfoApplyConfiguration) WithStatus(value string) *PodInfoApplyConfiguration {
+	b.Status = &value
+	return b
+}
+
+// WithRestarts sets the Restarts field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Restarts field is set to the value of the last call.
+func (b *PodInfoApplyConfiguration) WithRestarts(value int32) *PodInfoApplyConfiguration {
+	b.Restarts = &value
+	return b
+}
+
+// WithContainers adds the given value to the Containers field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Containers field.
+func (b *PodInfoApplyConfiguration) WithContainers(values ...*ContainerInfoApplyConfiguration) *PodInfoApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithContainers")
+		}
+		b.Containers = append(b.Containers, *values[i])
+	}
+	return b
+}
+
+// WithWorkload sets the Workload field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Workload field is set to the value of the last call.
+func (b *PodInfoApplyConfiguration) WithWorkload(value *WorkloadRefApplyConfiguration) *PodInfoApplyConfiguration {
+	b.Workload = value
+	return b
+}
+
+// WithUsage sets the Usage field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Usage field is set to the value of the last call.
+func (b *PodInfoApplyConfiguration) WithUsage(value *ResourceAmountsApplyConfiguration) *PodInfoApplyConfiguration {
+	b.Usage = value
+	return b
+}
@@ -0,0 +1,23 @@
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// PodReportSpecApplyConfiguration represents a declarative configuration of the PodReportSpec type for use
+// with apply.
+type PodReportSpecApplyConfiguration struct {
+	ClusterName *string `json:"clusterName,omitempty"`
+}
+
+// PodReportSpecApplyConfiguration constructs a declarative configuration of the PodReportSpec type for use with
+// apply.
+func PodReportSpec() *PodReportSpecApplyConfiguration {
+	return &PodReportSpecApplyConfiguration{}
+}
+
+// WithClusterName sets the ClusterName field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ClusterName field is set to the value of the last call.
+func (b *PodReportSpecApplyConfiguration) WithClusterName(value string) *PodReportSpecApplyConfiguration {
+	b.ClusterName = &value
+	return b
+}
@@ -0,0 +1,86 @@
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PodReportStatusApplyConfiguration represents a declarative configuration of the PodReportStatus type for use
+// with apply.
+type PodReportStatusApplyConfiguration struct {
+	ClusterName  *string                              `json:"clusterName,omitempty"`
+	TotalPods    *int32                               `json:"totalPods,omitempty"`
+	LastSyncTime *v1.Time                             `json:"lastSyncTime,omitempty"`
+	Truncated    *bool                                `json:"truncated,omitempty"`
+	Pods         []PodInfoApplyConfiguration          `json:"pods,omitempty"`
+	Resources    *ResourceSummaryApplyConfiguration   `json:"resources,omitempty"`
+	Filters      *CollectionFiltersApplyConfiguration `json:"filters,omitempty"`
+}
+
+// PodReportStatusApplyConfiguration constructs a declarative configuration of the PodReportStatus type for use with
+// apply.
+func PodReportStatus() *PodReportStatusApplyConfiguration {
+	return &PodReportStatusApplyConfiguration{}
+}
+
+// WithClusterName sets the ClusterName field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ClusterName field is set to the value of the last call.
+func (b *PodReportStatusApplyConfiguration) WithClusterName(value string) *PodReportStatusApplyConfiguration {
+	b.ClusterName = &value
+	return b
+}
+
+// WithTotalPods sets the TotalPods field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the TotalPods field is set to the value of the last call.
+func (b *PodReportStatusApplyConfiguration) WithTotalPods(value int32) *PodReportStatusApplyConfiguration {
+	b.TotalPods = &value
+	return b
+}
+
+// WithLastSyncTime sets the LastSyncTime field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the LastSyncTime field is set to the value of the last call.
+func (b *PodReportStatusApplyConfiguration) WithLastSyncTime(value v1.Time) *PodReportStatusApplyConfiguration {
+	b.LastSyncTime = &value
+	return b
+}
+
+// WithTruncated sets the Truncated field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Truncated field is set to the value of the last call.
+func (b *PodReportStatusApplyConfiguration) WithTruncated(value bool) *PodReportStatusApplyConfiguration {
+	b.Truncated = &value
+	return b
+}
+
+// WithPods adds the given value to the Pods field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Pods field.
+func (b *PodReportStatusApplyConfiguration) WithPods(values ...*PodInfoApplyConfiguration) *PodReportStatusApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithPods")
+		}
+		b.Pods = append(b.Pods, *values[i])
+	}
+	return b
+}
+
+// WithResources sets the Resources field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Resources field is set to the value of the last call.
+func (b *PodReportStatusApplyConfiguration) WithResources(value *ResourceSummaryApplyConfiguration) *PodReportStatusApplyConfiguration {
+	b.Resources = value
+	return b
+}
+
+// WithFilters sets the Filters field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Filters field is set to the value of the last call.
+func (b *PodReportStatusApplyConfiguration) WithFilters(value *CollectionFiltersApplyConfiguration) *PodReportStatusApplyConfiguration {
+	b.Filters = value
+	return b
+}
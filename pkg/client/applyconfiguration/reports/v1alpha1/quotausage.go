@@ -0,0 +1,59 @@
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// QuotaUsageApplyConfiguration represents a declarative configuration of the QuotaUsage type for use
+// with apply.
+type QuotaUsageApplyConfiguration struct {
+	Quota    *string `json:"quota,omitempty"`
+	Resource *string `json:"resource,omitempty"`
+	Hard     *string `json:"hard,omitempty"`
+	Used     *string `json:"used,omitempty"`
+	Percent  *int64  `json:"percent,omitempty"`
+}
+
+// QuotaUsageApplyConfiguration constructs a declarative configuration of the QuotaUsage type for use with
+// apply.
+func QuotaUsage() *QuotaUsageApplyConfiguration {
+	return &QuotaUsageApplyConfiguration{}
+}
+
+// WithQuota sets the Quota field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Quota field is set to the value of the last call.
+func (b *QuotaUsageApplyConfiguration) WithQuota(value string) *QuotaUsageApplyConfiguration {
+	b.Quota = &value
+	return b
+}
+
+// WithResource sets the Resource field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Resource field is set to the value of the last call.
+func (b *QuotaUsageApplyConfiguration) WithResource(value string) *QuotaUsageApplyConfiguration {
+	b.Resource = &value
+	return b
+}
+
+// WithHard sets the Hard field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Hard field is set to the value of the last call.
+func (b *QuotaUsageApplyConfiguration) WithHard(value string) *QuotaUsageApplyConfiguration {
+	b.Hard = &value
+	return b
+}
+
+// WithUsed sets the Used field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Used field is set to the value of the last call.
+func (b *QuotaUsageApplyConfiguration) WithUsed(value string) *QuotaUsageApplyConfiguration {
+	b.Used = &value
+	return b
+}
+
+// WithPercent sets the Percent field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Percent field is set to the value of the last call.
+func (b *QuotaUsageApplyConfiguration) WithPercent(value int64) *QuotaUsageApplyConfiguration {
+	b.Percent = &value
+	return b
+}
@@ -0,0 +1,32 @@
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// ResourceAmountsApplyConfiguration represents a declarative configuration of the ResourceAmounts type for use
+// with apply.
+type ResourceAmountsApplyConfiguration struct {
+	CPU    *string `json:"cpu,omitempty"`
+	Memory *string `json:"memory,omitempty"`
+}
+
+// ResourceAmountsApplyConfiguration constructs a declarative configuration of the ResourceAmounts type for use with
+// apply.
+func ResourceAmounts() *ResourceAmountsApplyConfiguration {
+	return &ResourceAmountsApplyConfiguration{}
+}
+
+// WithCPU sets the CPU field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the CPU field is set to the value of the last call.
+func (b *ResourceAmountsApplyConfiguration) WithCPU(value string) *ResourceAmountsApplyConfiguration {
+	b.CPU = &value
+	return b
+}
+
+// WithMemory sets the Memory field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Memory field is set to the value of the last call.
+func (b *ResourceAmountsApplyConfiguration) WithMemory(value string) *ResourceAmountsApplyConfiguration {
+	b.Memory = &value
+	return b
+}
@@ -0,0 +1,70 @@
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// ResourceSummaryApplyConfiguration represents a declarative configuration of the ResourceSummary type for use
+// with apply.
+type ResourceSummaryApplyConfiguration struct {
+	Requests   *ResourceAmountsApplyConfiguration     `json:"requests,omitempty"`
+	Limits     *ResourceAmountsApplyConfiguration     `json:"limits,omitempty"`
+	QoSClasses map[string]int32                       `json:"qosClasses,omitempty"`
+	Usage      *ResourceAmountsApplyConfiguration     `json:"usage,omitempty"`
+	Namespaces []NamespaceResourcesApplyConfiguration `json:"namespaces,omitempty"`
+}
+
+// ResourceSummaryApplyConfiguration constructs a declarative configuration of the ResourceSummary type for use with
+// apply.
+func ResourceSummary() *ResourceSummaryApplyConfiguration {
+	return &ResourceSummaryApplyConfiguration{}
+}
+
+// WithRequests sets the Requests field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Requests field is set to the value of the last call.
+func (b *ResourceSummaryApplyConfiguration) WithRequests(value *ResourceAmountsApplyConfiguration) *ResourceSummaryApplyConfiguration {
+	b.Requests = value
+	return b
+}
+
+// WithLimits sets the Limits field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Limits field is set to the value of the last call.
+func (b *ResourceSummaryApplyConfiguration) WithLimits(value *ResourceAmountsApplyConfiguration) *ResourceSummaryApplyConfiguration {
+	b.Limits = value
+	return b
+}
+
+// WithQoSClasses puts the entries into the QoSClasses field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the QoSClasses field,
+// overwriting an existing map entries in QoSClasses field with the same key.
+func (b *ResourceSummaryApplyConfiguration) WithQoSClasses(entries map[string]int32) *ResourceSummaryApplyConfiguration {
+	if b.QoSClasses == nil && len(entries) > 0 {
+		b.QoSClasses = make(map[string]int32, len(entries))
+	}
+	for k, v := range entries {
+		b.QoSClasses[k] = v
+	}
+	return b
+}
+
+// WithUsage sets the Usage field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Usage field is set to the value of the last call.
+func (b *ResourceSummaryApplyConfiguration) WithUsage(value *ResourceAmountsApplyConfiguration) *ResourceSummaryApplyConfiguration {
+	b.Usage = value
+	return b
+}
+
+// WithNamespaces adds the given value to the Namespaces field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Namespaces field.
+func (b *ResourceSummaryApplyConfiguration) WithNamespaces(values ...*NamespaceResourcesApplyConfiguration) *ResourceSummaryApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithNamespaces")
+		}
+		b.Namespaces = append(b.Namespaces, *values[i])
+	}
+	return b
+}
@@ -0,0 +1,32 @@
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// WorkloadRefApplyConfiguration represents a declarative configuration of the WorkloadRef type for use
+// with apply.
+type WorkloadRefApplyConfiguration struct {
+	Kind *string `json:"kind,omitempty"`
+	Name *string `json:"name,omitempty"`
+}
+
+// WorkloadRefApplyConfiguration constructs a declarative configuration of the WorkloadRef type for use with
+// apply.
+func WorkloadRef() *WorkloadRefApplyConfiguration {
+	return &WorkloadRefApplyConfiguration{}
+}
+
+// WithKind sets the Kind field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Kind field is set to the value of the last call.
+func (b *WorkloadRefApplyConfiguration) WithKind(value string) *WorkloadRefApplyConfiguration {
+	b.Kind = &value
+	return b
+}
+
+// WithName sets the Name field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Name field is set to the value of the last call.
+func (b *WorkloadRefApplyConfiguration) WithName(value string) *WorkloadRefApplyConfiguration {
+	b.Name = &value
+	return b
+}
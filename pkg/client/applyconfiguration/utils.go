@@ -0,0 +1,48 @@
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package applyconfiguration
+
+import (
+	v1alpha1 "github.com/totvs/addon-framework-basic/pkg/apis/reports/v1alpha1"
+	internal "github.com/totvs/addon-framework-basic/pkg/client/applyconfiguration/internal"
+	reportsv1alpha1 "github.com/totvs/addon-framework-basic/pkg/client/applyconfiguration/reports/v1alpha1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	managedfields "k8s.io/apimachinery/pkg/util/managedfields"
+)
+
+// ForKind returns an apply configuration type for the given GroupVersionKind, or nil if no
+// apply configuration type exists for the given GroupVersionKind.
+func ForKind(kind schema.GroupVersionKind) interface{} {
+	switch kind {
+	// Group=reports.basic-addon.open-cluster-management.io, Version=v1alpha1
+	case v1alpha1.SchemeGroupVersion.WithKind("CollectionFilters"):
+		return &reportsv1alpha1.CollectionFiltersApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("ContainerInfo"):
+		return &reportsv1alpha1.ContainerInfoApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("NamespaceResources"):
+		return &reportsv1alpha1.NamespaceResourcesApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("PodInfo"):
+		return &reportsv1alpha1.PodInfoApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("PodReport"):
+		return &reportsv1alpha1.PodReportApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("PodReportSpec"):
+		return &reportsv1alpha1.PodReportSpecApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("PodReportStatus"):
+		return &reportsv1alpha1.PodReportStatusApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("QuotaUsage"):
+		return &reportsv1alpha1.QuotaUsageApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("ResourceAmounts"):
+		return &reportsv1alpha1.ResourceAmountsApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("ResourceSummary"):
+		return &reportsv1alpha1.ResourceSummaryApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("WorkloadRef"):
+		return &reportsv1alpha1.WorkloadRefApplyConfiguration{}
+
+	}
+	return nil
+}
+
+func NewTypeConverter(scheme *runtime.Scheme) managedfields.TypeConverter {
+	return managedfields.NewSchemeTypeConverter(scheme, internal.Parser())
+}
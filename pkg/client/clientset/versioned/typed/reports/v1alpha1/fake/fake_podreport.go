@@ -0,0 +1,35 @@
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1alpha1 "github.com/totvs/addon-framework-basic/pkg/apis/reports/v1alpha1"
+	reportsv1alpha1 "github.com/totvs/addon-framework-basic/pkg/client/applyconfiguration/reports/v1alpha1"
+	typedreportsv1alpha1 "github.com/totvs/addon-framework-basic/pkg/client/clientset/versioned/typed/reports/v1alpha1"
+	gentype "k8s.io/client-go/gentype"
+)
+
+// fakePodReports implements PodReportInterface
+type fakePodReports struct {
+	*gentype.FakeClientWithListAndApply[*v1alpha1.PodReport, *v1alpha1.PodReportList, *reportsv1alpha1.PodReportApplyConfiguration]
+	Fake *FakeReportsV1alpha1
+}
+
+func newFakePodReports(fake *FakeReportsV1alpha1, namespace string) typedreportsv1alpha1.PodReportInterface {
+	return &fakePodReports{
+		gentype.NewFakeClientWithListAndApply[*v1alpha1.PodReport, *v1alpha1.PodReportList, *reportsv1alpha1.PodReportApplyConfiguration](
+			fake.Fake,
+			namespace,
+			v1alpha1.SchemeGroupVersion.WithResource("podreports"),
+			v1alpha1.SchemeGroupVersion.WithKind("PodReport"),
+			func() *v1alpha1.PodReport { return &v1alpha1.PodReport{} },
+			func() *v1alpha1.PodReportList { return &v1alpha1.PodReportList{} },
+			func(dst, src *v1alpha1.PodReportList) { dst.ListMeta = src.ListMeta },
+			func(list *v1alpha1.PodReportList) []*v1alpha1.PodReport { return gentype.ToPointerSlice(list.Items) },
+			func(list *v1alpha1.PodReportList, items []*v1alpha1.PodReport) {
+				list.Items = gentype.FromPointerSlice(items)
+			},
+		),
+		fake,
+	}
+}
@@ -0,0 +1,24 @@
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1alpha1 "github.com/totvs/addon-framework-basic/pkg/client/clientset/versioned/typed/reports/v1alpha1"
+	rest "k8s.io/client-go/rest"
+	testing "k8s.io/client-go/testing"
+)
+
+type FakeReportsV1alpha1 struct {
+	*testing.Fake
+}
+
+func (c *FakeReportsV1alpha1) PodReports(namespace string) v1alpha1.PodReportInterface {
+	return newFakePodReports(c, namespace)
+}
+
+// RESTClient returns a RESTClient that is used to communicate
+// with API server by this client implementation.
+func (c *FakeReportsV1alpha1) RESTClient() rest.Interface {
+	var ret *rest.RESTClient
+	return ret
+}
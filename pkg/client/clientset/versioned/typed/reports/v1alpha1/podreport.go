@@ -0,0 +1,58 @@
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	context "context"
+
+	reportsv1alpha1 "github.com/totvs/addon-framework-basic/pkg/apis/reports/v1alpha1"
+	applyconfigurationreportsv1alpha1 "github.com/totvs/addon-framework-basic/pkg/client/applyconfiguration/reports/v1alpha1"
+	scheme "github.com/totvs/addon-framework-basic/pkg/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	gentype "k8s.io/client-go/gentype"
+)
+
+// PodReportsGetter has a method to return a PodReportInterface.
+// A group's client should implement this interface.
+type PodReportsGetter interface {
+	PodReports(namespace string) PodReportInterface
+}
+
+// PodReportInterface has methods to work with PodReport resources.
+type PodReportInterface interface {
+	Create(ctx context.Context, podReport *reportsv1alpha1.PodReport, opts v1.CreateOptions) (*reportsv1alpha1.PodReport, error)
+	Update(ctx context.Context, podReport *reportsv1alpha1.PodReport, opts v1.UpdateOptions) (*reportsv1alpha1.PodReport, error)
+	// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+	UpdateStatus(ctx context.Context, podReport *reportsv1alpha1.PodReport, opts v1.UpdateOptions) (*reportsv1alpha1.PodReport, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*reportsv1alpha1.PodReport, error)
+	List(ctx context.Context, opts v1.ListOptions) (*reportsv1alpha1.PodReportList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *reportsv1alpha1.PodReport, err error)
+	Apply(ctx context.Context, podReport *applyconfigurationreportsv1alpha1.PodReportApplyConfiguration, opts v1.ApplyOptions) (result *reportsv1alpha1.PodReport, err error)
+	// Add a +genclient:noStatus comment above the type to avoid generating ApplyStatus().
+	ApplyStatus(ctx context.Context, podReport *applyconfigurationreportsv1alpha1.PodReportApplyConfiguration, opts v1.ApplyOptions) (result *reportsv1alpha1.PodReport, err error)
+	PodReportExpansion
+}
+
+// podReports implements PodReportInterface
+type podReports struct {
+	*gentype.ClientWithListAndApply[*reportsv1alpha1.PodReport, *reportsv1alpha1.PodReportList, *applyconfigurationreportsv1alpha1.PodReportApplyConfiguration]
+}
+
+// newPodReports returns a PodReports
+func newPodReports(c *ReportsV1alpha1Client, namespace string) *podReports {
+	return &podReports{
+		gentype.NewClientWithListAndApply[*reportsv1alpha1.PodReport, *reportsv1alpha1.PodReportList, *applyconfigurationreportsv1alpha1.PodReportApplyConfiguration](
+			"podreports",
+			c.RESTClient(),
+			scheme.ParameterCodec,
+			namespace,
+			func() *reportsv1alpha1.PodReport { return &reportsv1alpha1.PodReport{} },
+			func() *reportsv1alpha1.PodReportList { return &reportsv1alpha1.PodReportList{} },
+		),
+	}
+}
@@ -0,0 +1,298 @@
+package hub
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	addonclient "open-cluster-management.io/api/client/addon/clientset/versioned"
+	addoninformers "open-cluster-management.io/api/client/addon/informers/externalversions"
+	addonlisterv1alpha1 "open-cluster-management.io/api/client/addon/listers/addon/v1alpha1"
+	clusterclient "open-cluster-management.io/api/client/cluster/clientset/versioned"
+	clusterinformers "open-cluster-management.io/api/client/cluster/informers/externalversions"
+	clusterlisterv1 "open-cluster-management.io/api/client/cluster/listers/cluster/v1"
+
+	addonagent "github.com/totvs/addon-framework-basic/pkg/agent"
+	reportsv1alpha1 "github.com/totvs/addon-framework-basic/pkg/apis/reports/v1alpha1"
+)
+
+const (
+	// CleanupGracePeriod é o tempo padrão entre o addon (ou a ManagedCluster) sumir e os objetos
+	// do relatório serem removidos do hub. Dá tempo de arquivar os dados antes.
+	CleanupGracePeriod = time.Hour
+
+	// CleanupInterval é o intervalo da varredura dos objetos do relatório sem addon.
+	CleanupInterval = time.Minute
+
+	// informerResync é o resync dos informers de addons e clusters do cleaner.
+	informerResync = 10 * time.Minute
+)
+
+var (
+	configMapsResource = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	podReportsResource = reportsv1alpha1.SchemeGroupVersion.WithResource("podreports")
+)
+
+// ReportCleaner remove do hub os objetos do relatório (relatório, histórico, seções e shards)
+// quando o ManagedClusterAddOn ou a ManagedCluster do namespace é removido.
+//
+// São objetos do relatório todos os PodReports e os ConfigMaps com addonagent.ReportLabel.
+// O pod-report gravado pelos agents anteriores aos shards não tem a label: ele é buscado pelo
+// nome (ver legacyConfigMaps), só nos namespaces de ManagedClusters sem o addon ativo.
+//
+// Fluxo:
+// 1. A cada CleanupInterval (e a cada addon ou cluster removido) lista os objetos do relatório
+// 2. Namespace sem o addon, ou com addon ou cluster sendo removido, fica órfão
+// 3. Depois de gracePeriod órfão, os objetos são removidos; se o addon voltar antes, nada é apagado
+//
+// Só lê metadata (metadata.Interface), sem trazer os relatórios para a memória do controller.
+// O início do período órfão fica em memória: um restart do controller recomeça a contagem,
+// o que só atrasa a remoção.
+type ReportCleaner struct {
+	client      metadata.Interface
+	addons      addonlisterv1alpha1.ManagedClusterAddOnLister
+	clusters    clusterlisterv1.ManagedClusterLister
+	addonName   string
+	gracePeriod time.Duration
+	now         func() time.Time
+
+	orphanedSince map[string]time.Time // namespace → quando ficou sem addon
+
+	// Preenchidos por NewReportCleaner; nil nos testes
+	addonInformers   addoninformers.SharedInformerFactory
+	clusterInformers clusterinformers.SharedInformerFactory
+}
+
+// NewReportCleaner cria o cleaner dos objetos do relatório do addon addonName no hub.
+func NewReportCleaner(kubeConfig *rest.Config, addonName string, gracePeriod time.Duration) (*ReportCleaner, error) {
+	client, err := metadata.NewForConfig(kubeConfig)
+	if err != nil {
+		return nil, err
+	}
+	addons, err := addonclient.NewForConfig(kubeConfig)
+	if err != nil {
+		return nil, err
+	}
+	clusters, err := clusterclient.NewForConfig(kubeConfig)
+	if err != nil {
+		return nil, err
+	}
+	addonInformers := addoninformers.NewSharedInformerFactory(addons, informerResync)
+	clusterInformers := clusterinformers.NewSharedInformerFactory(clusters, informerResync)
+	c := newReportCleaner(client,
+		addonInformers.Addon().V1alpha1().ManagedClusterAddOns().Lister(),
+		clusterInformers.Cluster().V1().ManagedClusters().Lister(),
+		addonName, gracePeriod)
+	c.addonInformers, c.clusterInformers = addonInformers, clusterInformers
+	return c, nil
+}
+
+// newReportCleaner monta o cleaner a partir dos clientes e listers.
+func newReportCleaner(client metadata.Interface, addons addonlisterv1alpha1.ManagedClusterAddOnLister,
+	clusters clusterlisterv1.ManagedClusterLister, addonName string, gracePeriod time.Duration) *ReportCleaner {
+	return &ReportCleaner{
+		client:        client,
+		addons:        addons,
+		clusters:      clusters,
+		addonName:     addonName,
+		gracePeriod:   gracePeriod,
+		now:           time.Now,
+		orphanedSince: map[string]time.Time{},
+	}
+}
+
+// Run inicia os informers e varre os objetos do relatório até ctx ser cancelado.
+func (c *ReportCleaner) Run(ctx context.Context) error {
+	// Canal com buffer 1: várias remoções seguidas viram uma única varredura pendente.
+	removed := make(chan struct{}, 1)
+	handler := cache.ResourceEventHandlerFuncs{
+		DeleteFunc: func(interface{}) {
+			select {
+			case removed <- struct{}{}:
+			default:
+			}
+		},
+	}
+	if _, err := c.addonInformers.Addon().V1alpha1().ManagedClusterAddOns().Informer().AddEventHandler(handler); err != nil {
+		return err
+	}
+	if _, err := c.clusterInformers.Cluster().V1().ManagedClusters().Informer().AddEventHandler(handler); err != nil {
+		return err
+	}
+
+	c.addonInformers.Start(ctx.Done())
+	c.clusterInformers.Start(ctx.Done())
+	defer c.addonInformers.Shutdown()
+	defer c.clusterInformers.Shutdown()
+	for informerType, ok := range c.addonInformers.WaitForCacheSync(ctx.Done()) {
+		if !ok {
+			return fmt.Errorf("cache do informer %v não sincronizou", informerType)
+		}
+	}
+	for informerType, ok := range c.clusterInformers.WaitForCacheSync(ctx.Done()) {
+		if !ok {
+			return fmt.Errorf("cache do informer %v não sincronizou", informerType)
+		}
+	}
+
+	klog.Infof("Cleaner dos relatórios iniciado (grace period %s)", c.gracePeriod)
+	ticker := time.NewTicker(CleanupInterval)
+	defer ticker.Stop()
+	for {
+		if err := c.sweep(ctx); err != nil {
+			klog.Errorf("Falha na limpeza dos relatórios: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		case <-removed:
+		}
+	}
+}
+
+// sweep lista os objetos do relatório por namespace e remove os dos namespaces órfãos há mais
+// de gracePeriod. Erros de um namespace não impedem os outros.
+func (c *ReportCleaner) sweep(ctx context.Context) error {
+	objects := map[string][]reportObject{}
+	podReports, err := c.client.Resource(podReportsResource).Namespace(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil && !errors.IsNotFound(err) { // NotFound: CRD do PodReport não instalado
+		return fmt.Errorf("falha ao listar podreports: %w", err)
+	}
+	if err == nil {
+		for _, item := range podReports.Items {
+			objects[item.Namespace] = append(objects[item.Namespace], reportObject{resource: podReportsResource, name: item.Name})
+		}
+	}
+	configMaps, err := c.client.Resource(configMapsResource).Namespace(metav1.NamespaceAll).List(ctx,
+		metav1.ListOptions{LabelSelector: addonagent.ReportLabel})
+	if err != nil {
+		return fmt.Errorf("falha ao listar configmaps: %w", err)
+	}
+	for _, item := range configMaps.Items {
+		objects[item.Namespace] = append(objects[item.Namespace], reportObject{resource: configMapsResource, name: item.Name})
+	}
+	if err := c.addLegacyConfigMaps(ctx, objects); err != nil {
+		return err
+	}
+
+	// Namespaces sem objetos não precisam mais de acompanhamento
+	for namespace := range c.orphanedSince {
+		if _, ok := objects[namespace]; !ok {
+			delete(c.orphanedSince, namespace)
+		}
+	}
+
+	now := c.now()
+	var failed []string
+	for namespace, objs := range objects {
+		if c.active(namespace) {
+			if _, ok := c.orphanedSince[namespace]; ok {
+				klog.Infof("Addon %s voltou ao cluster %s, relatórios mantidos", c.addonName, namespace)
+				delete(c.orphanedSince, namespace)
+			}
+			continue
+		}
+		since, ok := c.orphanedSince[namespace]
+		if !ok {
+			since = now
+			c.orphanedSince[namespace] = now
+			klog.Infof("Addon %s removido do cluster %s: %d objetos do relatório serão removidos em %s",
+				c.addonName, namespace, len(objs), now.Add(c.gracePeriod).Format(time.RFC3339))
+		}
+		if now.Sub(since) < c.gracePeriod {
+			continue
+		}
+		if err := c.deleteObjects(ctx, namespace, objs); err != nil {
+			klog.Errorf("Falha ao remover relatórios do cluster %s: %v", namespace, err)
+			failed = append(failed, namespace)
+			continue
+		}
+		delete(c.orphanedSince, namespace)
+		klog.Infof("Relatórios do cluster %s removidos (%d objetos)", namespace, len(objs))
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("relatórios não removidos nos clusters %v", failed)
+	}
+	return nil
+}
+
+// legacyConfigMaps são os ConfigMaps do relatório gravados sem addonagent.ReportLabel, pelos
+// agents anteriores aos shards. Os outros nomes (shards, histórico, seções) sempre tiveram a label.
+var legacyConfigMaps = []string{addonagent.ConfigMapName}
+
+// addLegacyConfigMaps busca pelo nome os legacyConfigMaps sem a label nos namespaces das
+// ManagedClusters sem o addon ativo (nos ativos nada é removido). São Gets por namespace, em
+// vez de listar todos os ConfigMaps do hub a cada varredura.
+func (c *ReportCleaner) addLegacyConfigMaps(ctx context.Context, objects map[string][]reportObject) error {
+	clusters, err := c.clusters.List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("falha ao listar clusters: %w", err)
+	}
+	for _, cluster := range clusters {
+		namespace := cluster.Name
+		if c.active(namespace) {
+			continue
+		}
+		for _, name := range legacyConfigMaps {
+			obj := reportObject{resource: configMapsResource, name: name}
+			if hasObject(objects[namespace], obj) {
+				continue // já tem a label
+			}
+			_, err := c.client.Resource(configMapsResource).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+			if errors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("falha ao ler configmap %s/%s: %w", namespace, name, err)
+			}
+			objects[namespace] = append(objects[namespace], obj)
+		}
+	}
+	return nil
+}
+
+// hasObject diz se obj está em objs.
+func hasObject(objs []reportObject, obj reportObject) bool {
+	for _, o := range objs {
+		if o == obj {
+			return true
+		}
+	}
+	return false
+}
+
+// active diz se o namespace tem o addon e a ManagedCluster, sem remoção em andamento.
+func (c *ReportCleaner) active(namespace string) bool {
+	addon, err := c.addons.ManagedClusterAddOns(namespace).Get(c.addonName)
+	if err != nil || addon.DeletionTimestamp != nil {
+		return false
+	}
+	cluster, err := c.clusters.Get(namespace)
+	return err == nil && cluster.DeletionTimestamp == nil
+}
+
+// reportObject identifica um objeto do relatório no namespace do cluster.
+type reportObject struct {
+	resource schema.GroupVersionResource
+	name     string
+}
+
+// deleteObjects remove os objetos do relatório do namespace (NotFound é ignorado).
+func (c *ReportCleaner) deleteObjects(ctx context.Context, namespace string, objs []reportObject) error {
+	for _, obj := range objs {
+		err := c.client.Resource(obj.resource).Namespace(namespace).Delete(ctx, obj.name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("falha ao remover %s %s: %w", obj.resource.Resource, obj.name, err)
+		}
+	}
+	return nil
+}
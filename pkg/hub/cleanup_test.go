@@ -0,0 +1,254 @@
+package hub
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	metadatafake "k8s.io/client-go/metadata/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+	addonapiv1alpha1 "open-cluster-management.io/api/addon/v1alpha1"
+	addonlisterv1alpha1 "open-cluster-management.io/api/client/addon/listers/addon/v1alpha1"
+	clusterlisterv1 "open-cluster-management.io/api/client/cluster/listers/cluster/v1"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+
+	addonagent "github.com/totvs/addon-framework-basic/pkg/agent"
+)
+
+var (
+	configMaps = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	podReports = schema.GroupVersionResource{Group: "reports.basic-addon.open-cluster-management.io", Version: "v1alpha1", Resource: "podreports"}
+)
+
+// objectMeta cria o metadata de um objeto no hub; head não vazio marca com addonagent.ReportLabel.
+func objectMeta(apiVersion, kind, namespace, name, head string) *metav1.PartialObjectMetadata {
+	obj := &metav1.PartialObjectMetadata{
+		TypeMeta:   metav1.TypeMeta{APIVersion: apiVersion, Kind: kind},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+	}
+	if head != "" {
+		obj.Labels = map[string]string{addonagent.ReportLabel: head}
+	}
+	return obj
+}
+
+// cleanerFixture é o cleaner com clientes e listers em memória e relógio controlado.
+type cleanerFixture struct {
+	cleaner  *ReportCleaner
+	client   *metadatafake.FakeMetadataClient
+	addons   cache.Indexer
+	clusters cache.Indexer
+	now      time.Time
+}
+
+func newCleanerFixture(t *testing.T, gracePeriod time.Duration, objects ...runtime.Object) *cleanerFixture {
+	t.Helper()
+	scheme := metadatafake.NewTestScheme()
+	if err := metav1.AddMetaToScheme(scheme); err != nil {
+		t.Fatalf("scheme: %v", err)
+	}
+	f := &cleanerFixture{
+		client:   metadatafake.NewSimpleMetadataClient(scheme, objects...),
+		addons:   cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}),
+		clusters: cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{}),
+		now:      time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+	f.cleaner = newReportCleaner(f.client, addonlisterv1alpha1.NewManagedClusterAddOnLister(f.addons),
+		clusterlisterv1.NewManagedClusterLister(f.clusters), "basic-addon", gracePeriod)
+	f.cleaner.now = func() time.Time { return f.now }
+	return f
+}
+
+// enable registra a ManagedCluster e o addon do namespace.
+func (f *cleanerFixture) enable(cluster string) {
+	_ = f.clusters.Add(&clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: cluster}})
+	_ = f.addons.Add(&addonapiv1alpha1.ManagedClusterAddOn{ObjectMeta: metav1.ObjectMeta{Name: "basic-addon", Namespace: cluster}})
+}
+
+// disable remove o addon do namespace (make disable).
+func (f *cleanerFixture) disable(cluster string) {
+	_ = f.addons.Delete(&addonapiv1alpha1.ManagedClusterAddOn{ObjectMeta: metav1.ObjectMeta{Name: "basic-addon", Namespace: cluster}})
+}
+
+// names lista os objetos de resource no namespace.
+func (f *cleanerFixture) names(t *testing.T, resource schema.GroupVersionResource, namespace string) []string {
+	t.Helper()
+	list, err := f.client.Resource(resource).Namespace(namespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("list %s: %v", resource.Resource, err)
+	}
+	var names []string
+	for _, item := range list.Items {
+		names = append(names, item.Name)
+	}
+	return names
+}
+
+// reportFixture são os objetos de um cluster: relatório com shards, histórico e seção,
+// mais um ConfigMap de outra ferramenta.
+func reportFixture(namespace string) []runtime.Object {
+	return []runtime.Object{
+		objectMeta("v1", "ConfigMap", namespace, "pod-report-history-0", "pod-report-history-0"),
+		objectMeta("v1", "ConfigMap", namespace, "pod-report-history-0-0", "pod-report-history-0"),
+		objectMeta("v1", "ConfigMap", namespace, "nodes-report", "nodes-report"),
+		objectMeta("v1", "ConfigMap", namespace, "kube-root-ca.crt", ""),
+		objectMeta("reports.basic-addon.open-cluster-management.io/v1alpha1", "PodReport", namespace, "pod-report", ""),
+		objectMeta("reports.basic-addon.open-cluster-management.io/v1alpha1", "PodReport", namespace, "pod-report-history-1", ""),
+	}
+}
+
+func TestReportCleanerWaitsForGracePeriod(t *testing.T) {
+	// Arrange: the addon is enabled on cluster1 and cluster2
+	ctx := context.Background()
+	f := newCleanerFixture(t, time.Hour, append(reportFixture("cluster1"), reportFixture("cluster2")...)...)
+	f.enable("cluster1")
+	f.enable("cluster2")
+	if err := f.cleaner.sweep(ctx); err != nil {
+		t.Fatalf("sweep: %v", err)
+	}
+
+	// Act: the addon is disabled on cluster1 and the grace period is not over yet
+	f.disable("cluster1")
+	if err := f.cleaner.sweep(ctx); err != nil {
+		t.Fatalf("sweep: %v", err)
+	}
+	f.now = f.now.Add(59 * time.Minute)
+	if err := f.cleaner.sweep(ctx); err != nil {
+		t.Fatalf("sweep: %v", err)
+	}
+
+	// Assert: data is still there to be archived
+	if got := f.names(t, configMaps, "cluster1"); len(got) != 4 {
+		t.Errorf("ConfigMaps = %v, want all kept during the grace period", got)
+	}
+
+	// Act: the grace period is over
+	f.now = f.now.Add(time.Minute)
+	if err := f.cleaner.sweep(ctx); err != nil {
+		t.Fatalf("sweep: %v", err)
+	}
+
+	// Assert: report, history, sections and shards are gone; foreign objects and cluster2 are kept
+	if got := f.names(t, configMaps, "cluster1"); len(got) != 1 || got[0] != "kube-root-ca.crt" {
+		t.Errorf("ConfigMaps = %v, want only kube-root-ca.crt", got)
+	}
+	if got := f.names(t, podReports, "cluster1"); len(got) != 0 {
+		t.Errorf("PodReports = %v, want none", got)
+	}
+	if got := f.names(t, configMaps, "cluster2"); len(got) != 4 {
+		t.Errorf("cluster2 ConfigMaps = %v, want all kept", got)
+	}
+	if got := f.names(t, podReports, "cluster2"); len(got) != 2 {
+		t.Errorf("cluster2 PodReports = %v, want all kept", got)
+	}
+}
+
+func TestReportCleanerKeepsDataWhenAddonReturns(t *testing.T) {
+	// Arrange: the addon was disabled on cluster1
+	ctx := context.Background()
+	f := newCleanerFixture(t, time.Hour, reportFixture("cluster1")...)
+	f.enable("cluster1")
+	f.disable("cluster1")
+	if err := f.cleaner.sweep(ctx); err != nil {
+		t.Fatalf("sweep: %v", err)
+	}
+
+	// Act: it is enabled again within the grace period, then disabled once more
+	f.now = f.now.Add(30 * time.Minute)
+	f.enable("cluster1")
+	if err := f.cleaner.sweep(ctx); err != nil {
+		t.Fatalf("sweep: %v", err)
+	}
+	f.disable("cluster1")
+	if err := f.cleaner.sweep(ctx); err != nil {
+		t.Fatalf("sweep: %v", err)
+	}
+	f.now = f.now.Add(59 * time.Minute)
+	if err := f.cleaner.sweep(ctx); err != nil {
+		t.Fatalf("sweep: %v", err)
+	}
+
+	// Assert: the grace period restarted with the second removal
+	if got := f.names(t, podReports, "cluster1"); len(got) != 2 {
+		t.Errorf("PodReports = %v, want all kept", got)
+	}
+}
+
+func TestReportCleanerOnClusterDeletion(t *testing.T) {
+	// Arrange: the ManagedCluster is being deleted, the addon is still there
+	ctx := context.Background()
+	f := newCleanerFixture(t, 0, reportFixture("cluster1")...)
+	f.enable("cluster1")
+	deleting := metav1.NewTime(f.now)
+	_ = f.clusters.Update(&clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster1", DeletionTimestamp: &deleting}})
+
+	// Act
+	err := f.cleaner.sweep(ctx)
+
+	// Assert: without a grace period the objects are removed on the first sweep
+	if err != nil {
+		t.Fatalf("sweep: %v", err)
+	}
+	if got := f.names(t, configMaps, "cluster1"); len(got) != 1 {
+		t.Errorf("ConfigMaps = %v, want only the foreign one", got)
+	}
+	if got := f.names(t, podReports, "cluster1"); len(got) != 0 {
+		t.Errorf("PodReports = %v, want none", got)
+	}
+}
+
+func TestReportCleanerRemovesUnlabelledLegacyConfigMap(t *testing.T) {
+	// Arrange: an agent from before the shards left pod-report without addonagent.ReportLabel
+	// next to the PodReport; other namespaces have a pod-report of some other tool
+	ctx := context.Background()
+	f := newCleanerFixture(t, time.Hour,
+		objectMeta("v1", "ConfigMap", "cluster1", "pod-report", ""),
+		objectMeta("v1", "ConfigMap", "cluster1", "kube-root-ca.crt", ""),
+		objectMeta("reports.basic-addon.open-cluster-management.io/v1alpha1", "PodReport", "cluster1", "pod-report", ""),
+		objectMeta("v1", "ConfigMap", "cluster2", "pod-report", ""),
+		objectMeta("v1", "ConfigMap", "monitoring", "pod-report", ""),
+	)
+	f.enable("cluster1")
+	f.enable("cluster2")
+	if err := f.cleaner.sweep(ctx); err != nil {
+		t.Fatalf("sweep: %v", err)
+	}
+
+	// Act: the addon is disabled on cluster1 and cluster2 and the grace period is over
+	f.disable("cluster1")
+	f.disable("cluster2")
+	if err := f.cleaner.sweep(ctx); err != nil {
+		t.Fatalf("sweep: %v", err)
+	}
+	f.now = f.now.Add(time.Hour)
+	if err := f.cleaner.sweep(ctx); err != nil {
+		t.Fatalf("sweep: %v", err)
+	}
+
+	// Assert: ConfigMaps are only listed by label, the legacy one is read by name
+	for _, action := range f.client.Actions() {
+		list, ok := action.(k8stesting.ListAction)
+		if ok && list.GetResource() == configMaps && list.GetListRestrictions().Labels.Empty() {
+			t.Errorf("ConfigMaps listed without a label selector in %q", list.GetNamespace())
+		}
+	}
+
+	// Assert: the legacy ConfigMap is removed in cluster namespaces, with or without a PodReport
+	if got := f.names(t, configMaps, "cluster1"); len(got) != 1 || got[0] != "kube-root-ca.crt" {
+		t.Errorf("cluster1 ConfigMaps = %v, want only kube-root-ca.crt", got)
+	}
+	if got := f.names(t, podReports, "cluster1"); len(got) != 0 {
+		t.Errorf("cluster1 PodReports = %v, want none", got)
+	}
+	if got := f.names(t, configMaps, "cluster2"); len(got) != 0 {
+		t.Errorf("cluster2 ConfigMaps = %v, want none", got)
+	}
+	// Assert: a namespace without a ManagedCluster or report objects is not touched
+	if got := f.names(t, configMaps, "monitoring"); len(got) != 1 {
+		t.Errorf("monitoring ConfigMaps = %v, want kept", got)
+	}
+}
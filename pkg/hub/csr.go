@@ -0,0 +1,199 @@
+package hub
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	"open-cluster-management.io/addon-framework/pkg/agent"
+	addonapiv1alpha1 "open-cluster-management.io/api/addon/v1alpha1"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	operatorapiv1 "open-cluster-management.io/api/operator/v1"
+)
+
+const (
+	// CSRDenyLabel na ManagedCluster segura a aprovação dos CSRs do agent (ex: cluster em
+	// quarentena). Os CSRs ficam pendentes; ao remover a label o CSRRechecker os manda de novo
+	// para o CSRApprover.
+	CSRDenyLabel = "basic-addon.open-cluster-management.io/deny-registration"
+
+	// CSRMaxExpiration é a maior validade aceita em spec.expirationSeconds. Sem o campo vale a
+	// validade padrão do signer.
+	CSRMaxExpiration = 365 * 24 * time.Hour
+
+	// grpcServiceAccount cria os CSRs em nome dos agents quando o registro é via gRPC; o usuário
+	// real fica na anotação operatorapiv1.CSRUsernameAnnotation (mesma regra do DefaultCSRApprover).
+	grpcServiceAccount = "system:serviceaccount:open-cluster-management-hub:grpc-server-sa"
+)
+
+// Reasons dos Events gravados a cada decisão sobre um CSR do agent.
+const (
+	ReasonCSRApproved = "CSRApproved"
+	ReasonCSRRejected = "CSRRejected"
+	ReasonCSRHeld     = "CSRHeld" // ManagedCluster com CSRDenyLabel
+)
+
+// csrKeyUsages são os key usages aceitos; client auth é obrigatório.
+var csrKeyUsages = map[certificatesv1.KeyUsage]bool{
+	certificatesv1.UsageDigitalSignature: true,
+	certificatesv1.UsageKeyEncipherment:  true,
+	certificatesv1.UsageClientAuth:       true,
+}
+
+// CSRApprover valida os CSRs do agent antes de aprovar, no lugar do utils.DefaultCSRApprover.
+//
+// Confere:
+// 1. A ManagedCluster não tem CSRDenyLabel (senão segura o CSR, sem rejeitar)
+// 2. Signer kubernetes.io/kube-apiserver-client e assinatura do request válida
+// 3. Organizations iguais a agent.DefaultGroups e commonName igual a agent.DefaultUser (com AgentName)
+// 4. Quem pediu é a identidade de registro do cluster (system:open-cluster-management:<cluster>:...)
+// 5. Key usages só de cliente (ver csrKeyUsages) e validade até CSRMaxExpiration
+//
+// Cada decisão vira um Event no ManagedClusterAddOn. Com kubeConfig nil só loga (útil para testes).
+func CSRApprover(kubeConfig *rest.Config) agent.CSRApproveFunc {
+	return (&csrApprover{recorder: newEventRecorder(kubeConfig)}).approve
+}
+
+// csrApprover guarda o recorder dos Events das decisões.
+type csrApprover struct {
+	recorder record.EventRecorder // nil não grava Events
+}
+
+// approve implementa agent.CSRApproveFunc.
+func (a *csrApprover) approve(cluster *clusterv1.ManagedCluster, addon *addonapiv1alpha1.ManagedClusterAddOn,
+	csr *certificatesv1.CertificateSigningRequest) bool {
+
+	reason, message := a.check(cluster, addon, csr)
+	eventType := corev1.EventTypeWarning
+	if reason == ReasonCSRApproved {
+		eventType = corev1.EventTypeNormal
+		klog.Infof("CSR %s do cluster %s aprovado", csr.Name, cluster.Name)
+	} else {
+		klog.Warningf("CSR %s do cluster %s não aprovado (%s): %s", csr.Name, cluster.Name, reason, message)
+	}
+	if a.recorder != nil {
+		a.recorder.Eventf(addon, eventType, reason, "CSR %s: %s", csr.Name, message)
+	}
+	return reason == ReasonCSRApproved
+}
+
+// check devolve a decisão (reason do Event) e o motivo.
+func (a *csrApprover) check(cluster *clusterv1.ManagedCluster, addon *addonapiv1alpha1.ManagedClusterAddOn,
+	csr *certificatesv1.CertificateSigningRequest) (string, string) {
+
+	if _, ok := cluster.Labels[CSRDenyLabel]; ok {
+		return ReasonCSRHeld, fmt.Sprintf("aprovação suspensa pela label %s na ManagedCluster", CSRDenyLabel)
+	}
+	if csr.Spec.SignerName != certificatesv1.KubeAPIServerClientSignerName {
+		return ReasonCSRRejected, fmt.Sprintf("signer %q inesperado", csr.Spec.SignerName)
+	}
+
+	block, _ := pem.Decode(csr.Spec.Request)
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		return ReasonCSRRejected, "request não é um PEM CERTIFICATE REQUEST"
+	}
+	request, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return ReasonCSRRejected, fmt.Sprintf("request inválido: %v", err)
+	}
+	if err := request.CheckSignature(); err != nil {
+		return ReasonCSRRejected, fmt.Sprintf("assinatura do request inválida: %v", err)
+	}
+
+	groups := agent.DefaultGroups(cluster.Name, addon.Name)
+	if !sameSet(request.Subject.Organization, groups) {
+		return ReasonCSRRejected, fmt.Sprintf("organizations %v, esperado %v", request.Subject.Organization, groups)
+	}
+	if user := agent.DefaultUser(cluster.Name, addon.Name, AgentName(addon.Name, cluster)); request.Subject.CommonName != user {
+		return ReasonCSRRejected, fmt.Sprintf("commonName %q, esperado %q", request.Subject.CommonName, user)
+	}
+
+	// O ":" no fim do prefixo evita que o cluster "a" aceite usuários do cluster "ab"
+	identity := "system:open-cluster-management:" + cluster.Name
+	username := csr.Spec.Username
+	if username == grpcServiceAccount {
+		username = csr.Annotations[operatorapiv1.CSRUsernameAnnotation]
+	} else if !contains(csr.Spec.Groups, identity) {
+		return ReasonCSRRejected, fmt.Sprintf("usuário %q fora do grupo %s", username, identity)
+	}
+	if !strings.HasPrefix(username, identity+":") {
+		return ReasonCSRRejected, fmt.Sprintf("usuário %q não é a identidade de registro do cluster", username)
+	}
+
+	hasClientAuth := false
+	for _, usage := range csr.Spec.Usages {
+		if !csrKeyUsages[usage] {
+			return ReasonCSRRejected, fmt.Sprintf("key usage %q não permitido", usage)
+		}
+		hasClientAuth = hasClientAuth || usage == certificatesv1.UsageClientAuth
+	}
+	if !hasClientAuth {
+		return ReasonCSRRejected, fmt.Sprintf("key usage %q ausente", certificatesv1.UsageClientAuth)
+	}
+	if s := csr.Spec.ExpirationSeconds; s != nil && time.Duration(*s)*time.Second > CSRMaxExpiration {
+		return ReasonCSRRejected, fmt.Sprintf("validade de %s acima do máximo de %s", time.Duration(*s)*time.Second, CSRMaxExpiration)
+	}
+	return ReasonCSRApproved, "aprovado para " + request.Subject.CommonName
+}
+
+// newEventRecorder cria o recorder dos Events do controller no hub (nil sem kubeConfig).
+func newEventRecorder(kubeConfig *rest.Config) record.EventRecorder {
+	if kubeConfig == nil {
+		return nil
+	}
+	client, err := kubernetes.NewForConfig(kubeConfig)
+	if err != nil {
+		klog.Errorf("Falha ao criar cliente para Events: %v", err)
+		return nil
+	}
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		klog.Errorf("Falha ao montar scheme dos Events: %v", err)
+		return nil
+	}
+	if err := addonapiv1alpha1.Install(scheme); err != nil {
+		klog.Errorf("Falha ao montar scheme dos Events: %v", err)
+		return nil
+	}
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: client.CoreV1().Events("")})
+	return broadcaster.NewRecorder(scheme, corev1.EventSource{Component: FieldManager})
+}
+
+// sameSet diz se a e b têm os mesmos valores, sem considerar ordem.
+func sameSet(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	x, y := append([]string{}, a...), append([]string{}, b...)
+	sort.Strings(x)
+	sort.Strings(y)
+	for i := range x {
+		if x[i] != y[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// contains diz se values contém s.
+func contains(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
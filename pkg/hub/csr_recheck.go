@@ -0,0 +1,141 @@
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	addonapiv1alpha1 "open-cluster-management.io/api/addon/v1alpha1"
+	clusterclient "open-cluster-management.io/api/client/cluster/clientset/versioned"
+	clusterinformers "open-cluster-management.io/api/client/cluster/informers/externalversions"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+)
+
+const (
+	// CSRRecheckAnnotation é gravada nos CSRs pendentes do agent quando CSRDenyLabel sai da
+	// ManagedCluster. O update faz o CSRApprovingController do addon-framework, que só reage a
+	// eventos de CSR, passar o CSR de novo pelo CSRApprover.
+	CSRRecheckAnnotation = "basic-addon.open-cluster-management.io/recheck"
+
+	// csrRecheckTimeout limita a marcação dos CSRs de um cluster.
+	csrRecheckTimeout = 30 * time.Second
+)
+
+// CSRRechecker reenvia ao CSRApprover os CSRs do agent segurados por CSRDenyLabel quando a label
+// é removida da ManagedCluster. Sem ele os CSRs ficariam pendentes até o spoke mandar outro.
+type CSRRechecker struct {
+	client    kubernetes.Interface
+	addonName string
+	now       func() time.Time
+
+	clusterInformers clusterinformers.SharedInformerFactory // nil nos testes
+}
+
+// NewCSRRechecker cria o rechecker dos CSRs do addon addonName.
+func NewCSRRechecker(kubeConfig *rest.Config, addonName string) (*CSRRechecker, error) {
+	client, err := kubernetes.NewForConfig(kubeConfig)
+	if err != nil {
+		return nil, err
+	}
+	clusters, err := clusterclient.NewForConfig(kubeConfig)
+	if err != nil {
+		return nil, err
+	}
+	c := newCSRRechecker(client, addonName)
+	c.clusterInformers = clusterinformers.NewSharedInformerFactory(clusters, informerResync)
+	return c, nil
+}
+
+// newCSRRechecker monta o rechecker a partir do cliente.
+func newCSRRechecker(client kubernetes.Interface, addonName string) *CSRRechecker {
+	return &CSRRechecker{client: client, addonName: addonName, now: time.Now}
+}
+
+// Run observa as ManagedClusters até ctx ser cancelado.
+func (c *CSRRechecker) Run(ctx context.Context) error {
+	informer := c.clusterInformers.Cluster().V1().ManagedClusters().Informer()
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj interface{}) { c.clusterUpdated(ctx, oldObj, newObj) },
+	})
+	if err != nil {
+		return err
+	}
+	c.clusterInformers.Start(ctx.Done())
+	defer c.clusterInformers.Shutdown()
+	<-ctx.Done()
+	return nil
+}
+
+// clusterUpdated reavalia os CSRs pendentes do cluster se CSRDenyLabel foi removida.
+func (c *CSRRechecker) clusterUpdated(ctx context.Context, oldObj, newObj interface{}) {
+	old, ok1 := oldObj.(*clusterv1.ManagedCluster)
+	cluster, ok2 := newObj.(*clusterv1.ManagedCluster)
+	if !ok1 || !ok2 || !released(old, cluster) {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, csrRecheckTimeout)
+	defer cancel()
+	if err := c.recheck(ctx, cluster.Name); err != nil {
+		klog.Errorf("Falha ao reavaliar CSRs do cluster %s: %v", cluster.Name, err)
+	}
+}
+
+// released diz se CSRDenyLabel saiu da ManagedCluster.
+func released(old, cluster *clusterv1.ManagedCluster) bool {
+	_, wasHeld := old.Labels[CSRDenyLabel]
+	_, held := cluster.Labels[CSRDenyLabel]
+	return wasHeld && !held
+}
+
+// recheck marca com CSRRecheckAnnotation os CSRs pendentes do agent no cluster.
+func (c *CSRRechecker) recheck(ctx context.Context, clusterName string) error {
+	selector := labels.SelectorFromSet(labels.Set{
+		addonapiv1alpha1.AddonLabelKey: c.addonName,
+		clusterv1.ClusterNameLabelKey:  clusterName,
+	})
+	csrs, err := c.client.CertificatesV1().CertificateSigningRequests().List(ctx,
+		metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return err
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{CSRRecheckAnnotation: c.now().UTC().Format(time.RFC3339)},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	for _, csr := range csrs.Items {
+		if !pending(&csr) {
+			continue
+		}
+		_, err := c.client.CertificatesV1().CertificateSigningRequests().Patch(ctx, csr.Name,
+			types.MergePatchType, patch, metav1.PatchOptions{FieldManager: FieldManager})
+		if err != nil {
+			return fmt.Errorf("falha ao marcar CSR %s: %w", csr.Name, err)
+		}
+		klog.Infof("CSR %s do cluster %s liberado pela remoção da label %s", csr.Name, clusterName, CSRDenyLabel)
+	}
+	return nil
+}
+
+// pending diz se o CSR ainda não foi aprovado, negado ou falhou.
+func pending(csr *certificatesv1.CertificateSigningRequest) bool {
+	for _, condition := range csr.Status.Conditions {
+		switch condition.Type {
+		case certificatesv1.CertificateApproved, certificatesv1.CertificateDenied, certificatesv1.CertificateFailed:
+			return false
+		}
+	}
+	return true
+}
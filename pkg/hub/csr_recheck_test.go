@@ -0,0 +1,87 @@
+package hub
+
+import (
+	"context"
+	"testing"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	addonapiv1alpha1 "open-cluster-management.io/api/addon/v1alpha1"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+)
+
+// labelledCSR é o CSR do agent no cluster com as labels que o registration-agent grava.
+func labelledCSR(t *testing.T, name, cluster string) *certificatesv1.CertificateSigningRequest {
+	t.Helper()
+	csr := newAgentCSR(t, agentSubject("cluster1"))
+	csr.Name = name
+	csr.Labels = map[string]string{
+		addonapiv1alpha1.AddonLabelKey: "basic-addon",
+		clusterv1.ClusterNameLabelKey:  cluster,
+	}
+	return csr
+}
+
+func TestCSRRecheckerApprovesHeldCSRAfterLabelRemoval(t *testing.T) {
+	// Arrange: a CSR held by the deny label, an approved CSR and a CSR of another cluster
+	ctx := context.Background()
+	held := labelledCSR(t, "addon-cluster1-basic-addon-held", "cluster1")
+	approved := labelledCSR(t, "addon-cluster1-basic-addon-old", "cluster1")
+	approved.Status.Conditions = []certificatesv1.CertificateSigningRequestCondition{{Type: certificatesv1.CertificateApproved}}
+	other := labelledCSR(t, "addon-cluster2-basic-addon-xyz", "cluster2")
+	client := fake.NewClientset(held, approved, other)
+	rechecker := newCSRRechecker(client, "basic-addon")
+	approve := CSRApprover(nil)
+	addon := &addonapiv1alpha1.ManagedClusterAddOn{ObjectMeta: metav1.ObjectMeta{Name: "basic-addon", Namespace: "cluster1"}}
+	quarantined := testCluster()
+	quarantined.Labels = map[string]string{CSRDenyLabel: "true"}
+	if approve(quarantined, addon, held) {
+		t.Fatal("held CSR approved while the cluster has the deny label")
+	}
+
+	// Act: the label is removed from the ManagedCluster
+	rechecker.clusterUpdated(ctx, quarantined, testCluster())
+
+	// Assert: the held CSR gets an update (which requeues it in the CSRApprovingController)
+	// and now passes the approver
+	got, err := client.CertificatesV1().CertificateSigningRequests().Get(ctx, held.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get csr: %v", err)
+	}
+	if got.Annotations[CSRRecheckAnnotation] == "" {
+		t.Errorf("annotations = %v, want %s", got.Annotations, CSRRecheckAnnotation)
+	}
+	if !approve(testCluster(), addon, got) {
+		t.Error("CSR not approved after the label was removed")
+	}
+	for _, name := range []string{approved.Name, other.Name} {
+		csr, _ := client.CertificatesV1().CertificateSigningRequests().Get(ctx, name, metav1.GetOptions{})
+		if _, ok := csr.Annotations[CSRRecheckAnnotation]; ok {
+			t.Errorf("CSR %s touched, want only pending CSRs of cluster1", name)
+		}
+	}
+}
+
+func TestCSRRecheckerIgnoresOtherUpdates(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	held := labelledCSR(t, "addon-cluster1-basic-addon-held", "cluster1")
+	client := fake.NewClientset(held)
+	rechecker := newCSRRechecker(client, "basic-addon")
+	quarantined := testCluster()
+	quarantined.Labels = map[string]string{CSRDenyLabel: "true"}
+
+	// Act: the label is added, then another label changes while the cluster is held
+	rechecker.clusterUpdated(ctx, testCluster(), quarantined)
+	relabelled := quarantined.DeepCopy()
+	relabelled.Labels["env"] = "prod"
+	rechecker.clusterUpdated(ctx, quarantined, relabelled)
+
+	// Assert
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "patch" {
+			t.Errorf("unexpected patch: %v", action)
+		}
+	}
+}
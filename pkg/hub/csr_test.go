@@ -0,0 +1,246 @@
+package hub
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"open-cluster-management.io/addon-framework/pkg/agent"
+	addonapiv1alpha1 "open-cluster-management.io/api/addon/v1alpha1"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	operatorapiv1 "open-cluster-management.io/api/operator/v1"
+)
+
+// newAgentCSR cria o CSR que o registration-agent do cluster1 manda para o agent do addon.
+func newAgentCSR(t *testing.T, subject pkix.Name) *certificatesv1.CertificateSigningRequest {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{Subject: subject}, key)
+	if err != nil {
+		t.Fatalf("create request: %v", err)
+	}
+	return &certificatesv1.CertificateSigningRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "addon-cluster1-basic-addon-xyz"},
+		Spec: certificatesv1.CertificateSigningRequestSpec{
+			Request:    pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}),
+			SignerName: certificatesv1.KubeAPIServerClientSignerName,
+			Usages:     []certificatesv1.KeyUsage{certificatesv1.UsageDigitalSignature, certificatesv1.UsageKeyEncipherment, certificatesv1.UsageClientAuth},
+			Username:   "system:open-cluster-management:cluster1:k8h2x",
+			Groups:     []string{"system:open-cluster-management:cluster1", "system:open-cluster-management:managed-clusters", "system:authenticated"},
+		},
+	}
+}
+
+// testCluster é a ManagedCluster cluster1 com UID fixo.
+func testCluster() *clusterv1.ManagedCluster {
+	return &clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster1", UID: "6f1c2f4e-uid"}}
+}
+
+// agentSubject é o subject esperado do agent do addon no cluster (com o UID de testCluster).
+func agentSubject(cluster string) pkix.Name {
+	c := testCluster()
+	c.Name = cluster
+	return pkix.Name{
+		CommonName:   agent.DefaultUser(cluster, "basic-addon", AgentName("basic-addon", c)),
+		Organization: agent.DefaultGroups(cluster, "basic-addon"),
+	}
+}
+
+func TestCSRApprover(t *testing.T) {
+	int32Ptr := func(v int32) *int32 { return &v }
+	tests := []struct {
+		name   string
+		csr    func(t *testing.T) *certificatesv1.CertificateSigningRequest
+		labels map[string]string
+		reason string
+		detail string
+	}{
+		{
+			name: "valid",
+			csr: func(t *testing.T) *certificatesv1.CertificateSigningRequest {
+				return newAgentCSR(t, agentSubject("cluster1"))
+			},
+			reason: ReasonCSRApproved,
+		},
+		{
+			name: "valid via gRPC",
+			csr: func(t *testing.T) *certificatesv1.CertificateSigningRequest {
+				csr := newAgentCSR(t, agentSubject("cluster1"))
+				csr.Spec.Username, csr.Spec.Groups = grpcServiceAccount, nil
+				csr.Annotations = map[string]string{operatorapiv1.CSRUsernameAnnotation: "system:open-cluster-management:cluster1:k8h2x"}
+				return csr
+			},
+			reason: ReasonCSRApproved,
+		},
+		{
+			name: "forged extra organization",
+			csr: func(t *testing.T) *certificatesv1.CertificateSigningRequest {
+				subject := agentSubject("cluster1")
+				subject.Organization = append(subject.Organization, "system:masters")
+				return newAgentCSR(t, subject)
+			},
+			reason: ReasonCSRRejected,
+			detail: "organizations",
+		},
+		{
+			name: "forged commonName of another cluster",
+			csr: func(t *testing.T) *certificatesv1.CertificateSigningRequest {
+				return newAgentCSR(t, agentSubject("cluster2"))
+			},
+			reason: ReasonCSRRejected,
+			detail: "organizations",
+		},
+		{
+			name: "forged commonName of another agent",
+			csr: func(t *testing.T) *certificatesv1.CertificateSigningRequest {
+				subject := agentSubject("cluster1")
+				subject.CommonName = agent.DefaultUser("cluster1", "basic-addon", "other")
+				return newAgentCSR(t, subject)
+			},
+			reason: ReasonCSRRejected,
+			detail: "commonName",
+		},
+		{
+			name: "requester from a cluster with the same prefix",
+			csr: func(t *testing.T) *certificatesv1.CertificateSigningRequest {
+				csr := newAgentCSR(t, agentSubject("cluster1"))
+				csr.Spec.Username = "system:open-cluster-management:cluster10:k8h2x"
+				return csr
+			},
+			reason: ReasonCSRRejected,
+			detail: "identidade de registro",
+		},
+		{
+			name: "requester outside the cluster group",
+			csr: func(t *testing.T) *certificatesv1.CertificateSigningRequest {
+				csr := newAgentCSR(t, agentSubject("cluster1"))
+				csr.Spec.Groups = []string{"system:authenticated"}
+				return csr
+			},
+			reason: ReasonCSRRejected,
+			detail: "fora do grupo",
+		},
+		{
+			name: "server auth usage",
+			csr: func(t *testing.T) *certificatesv1.CertificateSigningRequest {
+				csr := newAgentCSR(t, agentSubject("cluster1"))
+				csr.Spec.Usages = append(csr.Spec.Usages, certificatesv1.UsageServerAuth)
+				return csr
+			},
+			reason: ReasonCSRRejected,
+			detail: "server auth",
+		},
+		{
+			name: "missing client auth",
+			csr: func(t *testing.T) *certificatesv1.CertificateSigningRequest {
+				csr := newAgentCSR(t, agentSubject("cluster1"))
+				csr.Spec.Usages = []certificatesv1.KeyUsage{certificatesv1.UsageDigitalSignature}
+				return csr
+			},
+			reason: ReasonCSRRejected,
+			detail: "client auth",
+		},
+		{
+			name: "lifetime above the maximum",
+			csr: func(t *testing.T) *certificatesv1.CertificateSigningRequest {
+				csr := newAgentCSR(t, agentSubject("cluster1"))
+				csr.Spec.ExpirationSeconds = int32Ptr(10 * 365 * 24 * 3600)
+				return csr
+			},
+			reason: ReasonCSRRejected,
+			detail: "validade",
+		},
+		{
+			name: "other signer",
+			csr: func(t *testing.T) *certificatesv1.CertificateSigningRequest {
+				csr := newAgentCSR(t, agentSubject("cluster1"))
+				csr.Spec.SignerName = "example.com/custom"
+				return csr
+			},
+			reason: ReasonCSRRejected,
+			detail: "signer",
+		},
+		{
+			name: "request signed by another key",
+			csr: func(t *testing.T) *certificatesv1.CertificateSigningRequest {
+				csr := newAgentCSR(t, agentSubject("cluster1"))
+				block, _ := pem.Decode(csr.Spec.Request)
+				block.Bytes[len(block.Bytes)-1] ^= 0xff // corrompe a assinatura
+				csr.Spec.Request = pem.EncodeToMemory(block)
+				return csr
+			},
+			reason: ReasonCSRRejected,
+		},
+		{
+			name: "not a PEM request",
+			csr: func(t *testing.T) *certificatesv1.CertificateSigningRequest {
+				csr := newAgentCSR(t, agentSubject("cluster1"))
+				csr.Spec.Request = []byte("not a csr")
+				return csr
+			},
+			reason: ReasonCSRRejected,
+			detail: "PEM",
+		},
+		{
+			name: "cluster with the deny label",
+			csr: func(t *testing.T) *certificatesv1.CertificateSigningRequest {
+				return newAgentCSR(t, agentSubject("cluster1"))
+			},
+			labels: map[string]string{CSRDenyLabel: "true"},
+			reason: ReasonCSRHeld,
+			detail: CSRDenyLabel,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			recorder := record.NewFakeRecorder(1)
+			approver := &csrApprover{recorder: recorder}
+			cluster := testCluster()
+			cluster.Labels = tt.labels
+			addon := &addonapiv1alpha1.ManagedClusterAddOn{ObjectMeta: metav1.ObjectMeta{Name: "basic-addon", Namespace: "cluster1"}}
+
+			// Act
+			approved := approver.approve(cluster, addon, tt.csr(t))
+
+			// Assert
+			if approved != (tt.reason == ReasonCSRApproved) {
+				t.Errorf("approved = %v, want %v", approved, !approved)
+			}
+			event := <-recorder.Events
+			wantType := "Warning"
+			if tt.reason == ReasonCSRApproved {
+				wantType = "Normal"
+			}
+			if !strings.HasPrefix(event, wantType+" "+tt.reason+" ") || !strings.Contains(event, tt.detail) {
+				t.Errorf("event = %q, want %s %s mentioning %q", event, wantType, tt.reason, tt.detail)
+			}
+		})
+	}
+}
+
+func TestCSRApproverWithNilConfig(t *testing.T) {
+	// Arrange
+	approve := CSRApprover(nil)
+	cluster := testCluster()
+	addon := &addonapiv1alpha1.ManagedClusterAddOn{ObjectMeta: metav1.ObjectMeta{Name: "basic-addon", Namespace: "cluster1"}}
+
+	// Act
+	approved := approve(cluster, addon, newAgentCSR(t, agentSubject("cluster1")))
+
+	// Assert: decisions still work without an event recorder
+	if !approved {
+		t.Error("approved = false, want true")
+	}
+}
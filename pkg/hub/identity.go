@@ -0,0 +1,36 @@
+package hub
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"open-cluster-management.io/addon-framework/pkg/agent"
+	addonapiv1alpha1 "open-cluster-management.io/api/addon/v1alpha1"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+)
+
+// agentNameLength é o tamanho do nome do agent (hex do sha256).
+const agentNameLength = 10
+
+// AgentName devolve o nome do agent do addon no cluster, usado no commonName do certificado
+// (agent.DefaultUser). É derivado do nome do addon e do UID da ManagedCluster, então não muda
+// entre restarts ou upgrades do controller: certificados emitidos antes continuam com o mesmo
+// subject e a renovação não força um novo registro. Uma ManagedCluster recriada com o mesmo
+// nome tem outro UID e outra identidade. Sem UID (cluster ainda não persistido) usa o nome.
+func AgentName(addonName string, cluster *clusterv1.ManagedCluster) string {
+	id := string(cluster.UID)
+	if id == "" {
+		id = cluster.Name
+	}
+	sum := sha256.Sum256([]byte(addonName + "/" + id))
+	return hex.EncodeToString(sum[:])[:agentNameLength]
+}
+
+// SignerConfigurations é o agent.KubeClientSignerConfigurations com o nome do agent de cada
+// cluster (ver AgentName) no lugar de um nome fixo por processo do controller.
+func SignerConfigurations(addonName string) agent.CSRConfigurationsFunc {
+	return func(cluster *clusterv1.ManagedCluster,
+		addon *addonapiv1alpha1.ManagedClusterAddOn) ([]addonapiv1alpha1.RegistrationConfig, error) {
+		return agent.KubeClientSignerConfigurations(addonName, AgentName(addonName, cluster))(cluster, addon)
+	}
+}
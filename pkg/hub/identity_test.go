@@ -0,0 +1,57 @@
+package hub
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	addonapiv1alpha1 "open-cluster-management.io/api/addon/v1alpha1"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+)
+
+func TestAgentName(t *testing.T) {
+	// Arrange
+	cluster := testCluster()
+	recreated := testCluster()
+	recreated.UID = "0a9d7c3b-uid"
+
+	// Act
+	name := AgentName("basic-addon", cluster)
+
+	// Assert
+	if len(name) != agentNameLength || name != AgentName("basic-addon", testCluster()) {
+		t.Errorf("AgentName = %q, want a stable %d-char name", name, agentNameLength)
+	}
+	if name == AgentName("basic-addon", recreated) || name == AgentName("other-addon", cluster) {
+		t.Error("AgentName is the same for another cluster UID or addon")
+	}
+	noUID := &clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster1"}}
+	if AgentName("basic-addon", noUID) == "" {
+		t.Error("AgentName without UID is empty")
+	}
+}
+
+func TestSignerConfigurationsSurviveControllerRestart(t *testing.T) {
+	// Arrange: the certificate was requested with the subject of a previous controller process
+	cluster := testCluster()
+	addon := &addonapiv1alpha1.ManagedClusterAddOn{ObjectMeta: metav1.ObjectMeta{Name: "basic-addon", Namespace: "cluster1"}}
+	before, err := SignerConfigurations("basic-addon")(cluster, addon)
+	if err != nil {
+		t.Fatalf("SignerConfigurations: %v", err)
+	}
+
+	// Act: a new controller process builds its configuration and approver from scratch
+	after, err := SignerConfigurations("basic-addon")(cluster, addon)
+	if err != nil {
+		t.Fatalf("SignerConfigurations: %v", err)
+	}
+	csr := newAgentCSR(t, agentSubject("cluster1"))
+	approved := CSRApprover(nil)(cluster, addon, csr)
+
+	// Assert
+	if len(after) != 1 || after[0].Subject.User != before[0].Subject.User {
+		t.Errorf("subject changed across restarts: %+v -> %+v", before, after)
+	}
+	if after[0].Subject.User != agentSubject("cluster1").CommonName || !approved {
+		t.Errorf("user = %q approved = %v, want the renewal CSR accepted", after[0].Subject.User, approved)
+	}
+}
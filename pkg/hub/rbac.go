@@ -7,29 +7,44 @@ import (
 	"fmt"
 
 	rbacv1 "k8s.io/api/rbac/v1"
-	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	applymetav1 "k8s.io/client-go/applyconfigurations/meta/v1"
+	applyrbacv1 "k8s.io/client-go/applyconfigurations/rbac/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"open-cluster-management.io/addon-framework/pkg/agent"
 	addonapiv1alpha1 "open-cluster-management.io/api/addon/v1alpha1"
 	clusterv1 "open-cluster-management.io/api/cluster/v1"
+
+	addonagent "github.com/totvs/addon-framework-basic/pkg/agent"
+	reportsv1alpha1 "github.com/totvs/addon-framework-basic/pkg/apis/reports/v1alpha1"
+	"github.com/totvs/addon-framework-basic/pkg/apply"
 )
 
+// FieldManager é o field manager do controller nos applies de Role e RoleBinding.
+const FieldManager = "basic-addon-controller"
+
 // AddonRBAC cria Role e RoleBinding no namespace do spoke (no hub).
-// Isso permite que o agent escreva ConfigMaps no hub.
+// Isso permite que o agent escreva o relatório no hub: PodReports ou ConfigMaps,
+// conforme o backend (mesmo valor passado ao agent em --report-backend), o histórico
+// (--history-size) e as seções dos collectors (mesmos valores de --collectors).
 //
 // Fluxo:
 // 1. Esta função é chamada pelo controller quando o ManagedClusterAddOn é criado
-// 2. Cria Role com permissão para get/create/update ConfigMaps
-// 3. Cria RoleBinding associando o grupo do agent à Role
+// 2. Aplica (server-side apply) a Role com as permissões do backend (ver agentRules)
+// 3. Aplica a RoleBinding associando o grupo do agent à Role
 // 4. O grupo do agent segue o padrão: system:open-cluster-management:cluster:<cluster>:addon:<addon>
 //
 // Por que Role e não ClusterRole?
 // - Role é namespace-scoped, limita as permissões ao namespace do spoke
 // - Cada spoke tem seu próprio namespace no hub (mesmo nome do cluster)
 // - Isso isola os dados de cada spoke
-func AddonRBAC(kubeConfig *rest.Config) agent.PermissionConfigFunc {
+//
+// Role e RoleBinding têm ownerReference para o ManagedClusterAddOn: quando o addon é removido
+// do cluster, o garbage collector do hub apaga as duas e o agent perde o acesso.
+func AddonRBAC(kubeConfig *rest.Config, backend string, collectors []string, historySize int) agent.PermissionConfigFunc {
 	return func(cluster *clusterv1.ManagedCluster, addon *addonapiv1alpha1.ManagedClusterAddOn) error {
 		// Se não tiver kubeConfig, não faz nada (útil para testes)
 		if kubeConfig == nil {
@@ -41,64 +56,176 @@ func AddonRBAC(kubeConfig *rest.Config) agent.PermissionConfigFunc {
 			return err
 		}
 
-		// Nome da Role segue convenção OCM
-		roleName := fmt.Sprintf("open-cluster-management:%s:agent", addon.Name)
+		role, binding := agentRBAC(cluster.Name, addon, backend, collectors, historySize)
+		return applyAgentRBAC(context.TODO(), client, role, binding)
+	}
+}
 
-		// Grupos do agent (DefaultGroups retorna os grupos padrão do OCM)
-		// Formato: system:open-cluster-management:cluster:<cluster>:addon:<addon>
-		groups := agent.DefaultGroups(cluster.Name, addon.Name)
+// agentRBAC monta a Role e a RoleBinding do agent no namespace do cluster, com ownerReference
+// para o addon.
+func agentRBAC(clusterName string, addon *addonapiv1alpha1.ManagedClusterAddOn, backend string,
+	collectors []string, historySize int) (*rbacv1.Role, *rbacv1.RoleBinding) {
+	// Nome da Role segue convenção OCM
+	roleName := fmt.Sprintf("open-cluster-management:%s:agent", addon.Name)
 
-		// Role com permissão para manipular ConfigMaps
-		role := &rbacv1.Role{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      roleName,
-				Namespace: cluster.Name, // Namespace = nome do cluster spoke
-			},
-			Rules: []rbacv1.PolicyRule{
-				{
-					Verbs:     []string{"get", "create", "update"},
-					Resources: []string{"configmaps"},
-					APIGroups: []string{""},
-				},
-			},
-		}
+	// Grupos do agent (DefaultGroups retorna os grupos padrão do OCM)
+	// Formato: system:open-cluster-management:cluster:<cluster>:addon:<addon>
+	groups := agent.DefaultGroups(clusterName, addon.Name)
 
-		// RoleBinding associa o grupo do agent à Role
-		binding := &rbacv1.RoleBinding{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      roleName,
-				Namespace: cluster.Name,
-			},
-			RoleRef: rbacv1.RoleRef{
+	// O addon fica no mesmo namespace (do cluster), como exige o ownerReference
+	owner := metav1.OwnerReference{
+		APIVersion: addonapiv1alpha1.GroupVersion.String(),
+		Kind:       "ManagedClusterAddOn",
+		Name:       addon.Name,
+		UID:        addon.UID,
+	}
+
+	// Role com permissão para gravar o relatório
+	role := &rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            roleName,
+			Namespace:       clusterName, // Namespace = nome do cluster spoke
+			OwnerReferences: []metav1.OwnerReference{owner},
+		},
+		Rules: agentRules(backend, collectors, historySize),
+	}
+
+	// RoleBinding associa o grupo do agent à Role
+	binding := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            roleName,
+			Namespace:       clusterName,
+			OwnerReferences: []metav1.OwnerReference{owner},
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: "rbac.authorization.k8s.io",
+			Kind:     "Role",
+			Name:     roleName,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:     "Group",
 				APIGroup: "rbac.authorization.k8s.io",
-				Kind:     "Role",
-				Name:     roleName,
-			},
-			Subjects: []rbacv1.Subject{
-				{
-					Kind:     "Group",
-					APIGroup: "rbac.authorization.k8s.io",
-					Name:     groups[0], // Primeiro grupo é o mais específico
-				},
+				Name:     groups[0], // Primeiro grupo é o mais específico
 			},
-		}
+		},
+	}
 
-		// Cria ou atualiza Role
-		if _, err := client.RbacV1().Roles(cluster.Name).Get(context.TODO(), roleName, metav1.GetOptions{}); errors.IsNotFound(err) {
-			_, err = client.RbacV1().Roles(cluster.Name).Create(context.TODO(), role, metav1.CreateOptions{})
-		} else if err == nil {
-			_, err = client.RbacV1().Roles(cluster.Name).Update(context.TODO(), role, metav1.UpdateOptions{})
-		}
-		if err != nil {
+	return role, binding
+}
+
+// applyAgentRBAC grava Role e RoleBinding com server-side apply (field manager FieldManager).
+// Labels e anotações adicionadas por outras ferramentas são preservadas; conflitos com outros
+// field managers retornam *apply.ConflictError.
+func applyAgentRBAC(ctx context.Context, client kubernetes.Interface, role *rbacv1.Role, binding *rbacv1.RoleBinding) error {
+	roles := client.RbacV1().Roles(role.Namespace)
+	roleAC := applyrbacv1.Role(role.Name, role.Namespace).
+		WithOwnerReferences(ownerReferences(role.OwnerReferences)...)
+	for _, rule := range role.Rules {
+		roleAC.WithRules(applyrbacv1.PolicyRule().
+			WithAPIGroups(rule.APIGroups...).
+			WithResources(rule.Resources...).
+			WithResourceNames(rule.ResourceNames...).
+			WithVerbs(rule.Verbs...))
+	}
+	err := apply.Do(ctx, apply.Object{
+		Kind:         "Role",
+		Namespace:    role.Namespace,
+		Name:         role.Name,
+		FieldManager: FieldManager,
+		Get: func(ctx context.Context) (runtime.Object, error) {
+			return roles.Get(ctx, role.Name, metav1.GetOptions{})
+		},
+		Patch: func(ctx context.Context, patch []byte) error {
+			_, err := roles.Patch(ctx, role.Name, types.JSONPatchType, patch, metav1.PatchOptions{})
 			return err
-		}
+		},
+	}, func(ctx context.Context) error {
+		_, err := roles.Apply(ctx, roleAC, metav1.ApplyOptions{FieldManager: FieldManager})
+		return err
+	})
+	if err != nil {
+		return err
+	}
 
-		// Cria ou atualiza RoleBinding
-		if _, err := client.RbacV1().RoleBindings(cluster.Name).Get(context.TODO(), roleName, metav1.GetOptions{}); errors.IsNotFound(err) {
-			_, err = client.RbacV1().RoleBindings(cluster.Name).Create(context.TODO(), binding, metav1.CreateOptions{})
-		} else if err == nil {
-			_, err = client.RbacV1().RoleBindings(cluster.Name).Update(context.TODO(), binding, metav1.UpdateOptions{})
-		}
+	bindings := client.RbacV1().RoleBindings(binding.Namespace)
+	bindingAC := applyrbacv1.RoleBinding(binding.Name, binding.Namespace).
+		WithOwnerReferences(ownerReferences(binding.OwnerReferences)...).
+		WithRoleRef(applyrbacv1.RoleRef().
+			WithAPIGroup(binding.RoleRef.APIGroup).
+			WithKind(binding.RoleRef.Kind).
+			WithName(binding.RoleRef.Name))
+	for _, subject := range binding.Subjects {
+		bindingAC.WithSubjects(applyrbacv1.Subject().
+			WithKind(subject.Kind).
+			WithAPIGroup(subject.APIGroup).
+			WithName(subject.Name))
+	}
+	return apply.Do(ctx, apply.Object{
+		Kind:         "RoleBinding",
+		Namespace:    binding.Namespace,
+		Name:         binding.Name,
+		FieldManager: FieldManager,
+		Get: func(ctx context.Context) (runtime.Object, error) {
+			return bindings.Get(ctx, binding.Name, metav1.GetOptions{})
+		},
+		Patch: func(ctx context.Context, patch []byte) error {
+			_, err := bindings.Patch(ctx, binding.Name, types.JSONPatchType, patch, metav1.PatchOptions{})
+			return err
+		},
+	}, func(ctx context.Context) error {
+		_, err := bindings.Apply(ctx, bindingAC, metav1.ApplyOptions{FieldManager: FieldManager})
 		return err
+	})
+}
+
+// ownerReferences converte as ownerReferences para o formato do apply.
+func ownerReferences(refs []metav1.OwnerReference) []*applymetav1.OwnerReferenceApplyConfiguration {
+	var out []*applymetav1.OwnerReferenceApplyConfiguration
+	for _, ref := range refs {
+		out = append(out, applymetav1.OwnerReference().
+			WithAPIVersion(ref.APIVersion).
+			WithKind(ref.Kind).
+			WithName(ref.Name).
+			WithUID(ref.UID))
+	}
+	return out
+}
+
+// agentRules retorna as regras da Role do agent, restritas pelo nome aos objetos do relatório
+// (ver addonagent.ReportObjects). Não há list: o agent lê o histórico slot a slot.
+// O agent grava com server-side apply, por isso o verbo patch; o primeiro apply cria o objeto e
+// é autorizado como create com o nome, então create também fica restrito por resourceNames.
+// delete é do histórico (pod-report-history-<n>) e dos shards órfãos.
+//   - crd: PodReports e o subresource status; ConfigMaps das seções dos collectors além de pods
+//   - configmap: só ConfigMaps (relatório, histórico e seções, com shards)
+//
+// Regras com resourceNames vazio liberariam todos os objetos, por isso só entram com nomes.
+func agentRules(backend string, collectors []string, historySize int) []rbacv1.PolicyRule {
+	podReports, configMaps := addonagent.ReportObjects(backend, collectors, historySize)
+	var rules []rbacv1.PolicyRule
+	if len(podReports) > 0 {
+		rules = append(rules,
+			rbacv1.PolicyRule{
+				Verbs:         []string{"get", "create", "patch", "delete"},
+				Resources:     []string{"podreports"},
+				APIGroups:     []string{reportsv1alpha1.GroupName},
+				ResourceNames: podReports,
+			},
+			rbacv1.PolicyRule{
+				Verbs:         []string{"get", "patch"},
+				Resources:     []string{"podreports/status"},
+				APIGroups:     []string{reportsv1alpha1.GroupName},
+				ResourceNames: podReports,
+			})
+	}
+	if len(configMaps) > 0 {
+		rules = append(rules, rbacv1.PolicyRule{
+			Verbs:         []string{"get", "create", "patch", "delete"},
+			Resources:     []string{"configmaps"},
+			APIGroups:     []string{""},
+			ResourceNames: configMaps,
+		})
 	}
+	return rules
 }
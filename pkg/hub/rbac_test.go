@@ -1,16 +1,24 @@
 package hub
 
 import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
 	addonapiv1alpha1 "open-cluster-management.io/api/addon/v1alpha1"
 	clusterv1 "open-cluster-management.io/api/cluster/v1"
+
+	addonagent "github.com/totvs/addon-framework-basic/pkg/agent"
 )
 
 func TestAddonRBACWithNilConfig(t *testing.T) {
 	// When kubeConfig is nil, it should return nil without error
-	permissionFunc := AddonRBAC(nil)
+	permissionFunc := AddonRBAC(nil, addonagent.BackendCRD, []string{addonagent.CollectorPods}, 10)
 
 	cluster := &clusterv1.ManagedCluster{
 		ObjectMeta: metav1.ObjectMeta{
@@ -30,3 +38,170 @@ func TestAddonRBACWithNilConfig(t *testing.T) {
 		t.Errorf("AddonRBAC with nil config should return nil, got: %v", err)
 	}
 }
+
+func TestAgentRules(t *testing.T) {
+	tests := []struct {
+		backend  string
+		resource string
+	}{
+		{backend: addonagent.BackendCRD, resource: "podreports"},
+		{backend: addonagent.BackendConfigMap, resource: "configmaps"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.backend, func(t *testing.T) {
+			// Act
+			rules := agentRules(tt.backend, []string{addonagent.CollectorPods}, 2)
+
+			// Assert: only the backend's report objects are granted, by name and without list
+			for _, rule := range rules {
+				for _, resource := range rule.Resources {
+					if resource != tt.resource && resource != tt.resource+"/status" {
+						t.Errorf("backend %s grants %q, want only %s", tt.backend, resource, tt.resource)
+					}
+				}
+				if len(rule.ResourceNames) == 0 {
+					t.Errorf("rule %+v is not scoped by resourceNames", rule)
+				}
+				for _, verb := range rule.Verbs {
+					if verb == "list" || verb == "watch" || verb == "*" {
+						t.Errorf("rule %+v grants %s", rule, verb)
+					}
+				}
+			}
+			if rules[0].Resources[0] != tt.resource {
+				t.Errorf("Resources = %v, want %s", rules[0].Resources, tt.resource)
+			}
+			names := strings.Join(rules[0].ResourceNames, ",")
+			for _, want := range []string{addonagent.ConfigMapName, "pod-report-history-0", "pod-report-history-1"} {
+				if !strings.Contains(names, want) {
+					t.Errorf("ResourceNames = %v, missing %s", rules[0].ResourceNames, want)
+				}
+			}
+			if strings.Contains(names, "pod-report-history-2") {
+				t.Errorf("ResourceNames = %v, want only 2 history slots", rules[0].ResourceNames)
+			}
+		})
+	}
+}
+
+func TestAgentRulesWithSections(t *testing.T) {
+	// Act
+	rules := agentRules(addonagent.BackendCRD, []string{addonagent.CollectorPods, "nodes"}, 0)
+
+	// Assert: sections are ConfigMaps even with the crd backend, named with their shards
+	last := rules[len(rules)-1]
+	if last.Resources[0] != "configmaps" {
+		t.Errorf("Resources = %v, want configmaps for collector sections", last.Resources)
+	}
+	want := []string{"nodes-report", "nodes-report-0", fmt.Sprintf("nodes-report-%d", addonagent.MaxShards-1)}
+	for _, name := range want {
+		if !strings.Contains(","+strings.Join(last.ResourceNames, ",")+",", ","+name+",") {
+			t.Errorf("ResourceNames = %v, missing %s", last.ResourceNames, name)
+		}
+	}
+	if len(last.ResourceNames) != addonagent.MaxShards+1 {
+		t.Errorf("len(ResourceNames) = %d, want %d", len(last.ResourceNames), addonagent.MaxShards+1)
+	}
+}
+
+func TestApplyAgentRBACPreservesForeignLabels(t *testing.T) {
+	// Arrange: Role already applied, then labelled by another tool (e.g. GitOps)
+	ctx := context.Background()
+	client := fake.NewClientset()
+	role, binding := agentRBAC("cluster1", testAddon(), addonagent.BackendCRD, []string{addonagent.CollectorPods}, 10)
+	if err := applyAgentRBAC(ctx, client, role, binding); err != nil {
+		t.Fatalf("first apply: %v", err)
+	}
+	patch := []byte(`{"metadata":{"labels":{"team":"platform"}}}`)
+	if _, err := client.RbacV1().Roles("cluster1").Patch(ctx, role.Name, types.MergePatchType, patch,
+		metav1.PatchOptions{FieldManager: "gitops"}); err != nil {
+		t.Fatalf("label: %v", err)
+	}
+
+	// Act: backend changes, the controller applies again
+	role, binding = agentRBAC("cluster1", testAddon(), addonagent.BackendConfigMap, []string{addonagent.CollectorPods}, 10)
+	err := applyAgentRBAC(ctx, client, role, binding)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("second apply: %v", err)
+	}
+	got, _ := client.RbacV1().Roles("cluster1").Get(ctx, role.Name, metav1.GetOptions{})
+	if got.Labels["team"] != "platform" {
+		t.Errorf("labels = %v, foreign label was removed", got.Labels)
+	}
+	if len(got.Rules) != 1 || got.Rules[0].Resources[0] != "configmaps" {
+		t.Errorf("Rules = %+v, want configmaps only", got.Rules)
+	}
+	rb, err := client.RbacV1().RoleBindings("cluster1").Get(ctx, binding.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get binding: %v", err)
+	}
+	if rb.Subjects[0].Name != "system:open-cluster-management:cluster:cluster1:addon:basic-addon" {
+		t.Errorf("subject = %q", rb.Subjects[0].Name)
+	}
+}
+
+// testAddon é o ManagedClusterAddOn basic-addon no cluster1, com UID fixo.
+func testAddon() *addonapiv1alpha1.ManagedClusterAddOn {
+	return &addonapiv1alpha1.ManagedClusterAddOn{
+		ObjectMeta: metav1.ObjectMeta{Name: "basic-addon", Namespace: "cluster1", UID: "3b7e9a1c-uid"},
+	}
+}
+
+func TestAgentRBACOwnedByAddon(t *testing.T) {
+	// Arrange: the addon is enabled on cluster1
+	ctx := context.Background()
+	addon := testAddon()
+	client := fake.NewClientset()
+
+	// Act: enable
+	role, binding := agentRBAC("cluster1", addon, addonagent.BackendCRD, []string{addonagent.CollectorPods}, 10)
+	if err := applyAgentRBAC(ctx, client, role, binding); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+
+	// Assert: both objects point to the addon in its own namespace, so the hub garbage
+	// collector removes them with the addon
+	got, err := client.RbacV1().Roles("cluster1").Get(ctx, role.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get role: %v", err)
+	}
+	rb, err := client.RbacV1().RoleBindings("cluster1").Get(ctx, binding.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get binding: %v", err)
+	}
+	want := metav1.OwnerReference{
+		APIVersion: "addon.open-cluster-management.io/v1alpha1",
+		Kind:       "ManagedClusterAddOn",
+		Name:       addon.Name,
+		UID:        addon.UID,
+	}
+	for _, obj := range []metav1.Object{got, rb} {
+		if obj.GetNamespace() != addon.Namespace {
+			t.Errorf("%s namespace = %q, want the addon namespace %q", obj.GetName(), obj.GetNamespace(), addon.Namespace)
+		}
+		if refs := obj.GetOwnerReferences(); len(refs) != 1 || !reflect.DeepEqual(refs[0], want) {
+			t.Errorf("%s ownerReferences = %+v, want %+v", obj.GetName(), refs, want)
+		}
+	}
+	if !reflect.DeepEqual(got.Rules, agentRules(addonagent.BackendCRD, []string{addonagent.CollectorPods}, 10)) {
+		t.Errorf("Rules = %+v", got.Rules)
+	}
+
+	// Act: configuration changes (backend, collectors and history)
+	collectors := []string{addonagent.CollectorPods, "nodes"}
+	role, binding = agentRBAC("cluster1", addon, addonagent.BackendConfigMap, collectors, 2)
+	if err := applyAgentRBAC(ctx, client, role, binding); err != nil {
+		t.Fatalf("reapply: %v", err)
+	}
+
+	// Assert: rules are replaced, not appended, and the owner is not duplicated
+	got, _ = client.RbacV1().Roles("cluster1").Get(ctx, role.Name, metav1.GetOptions{})
+	if !reflect.DeepEqual(got.Rules, agentRules(addonagent.BackendConfigMap, collectors, 2)) {
+		t.Errorf("Rules = %+v, want the new configuration only", got.Rules)
+	}
+	if refs := got.OwnerReferences; len(refs) != 1 || !reflect.DeepEqual(refs[0], want) {
+		t.Errorf("ownerReferences = %+v, want one pointing to the addon", refs)
+	}
+}